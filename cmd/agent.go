@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/quocvuong92/ai-cli/internal/api"
+	"github.com/quocvuong92/ai-cli/internal/config"
+	"github.com/quocvuong92/ai-cli/internal/display"
+	"github.com/quocvuong92/ai-cli/internal/executor"
+)
+
+// exitAborted is the exit code of an agent run that stopped before the
+// model finished: the iteration cap was hit or the run was interrupted
+const exitAborted = 2
+
+// errMaxIterations is returned by runToolLoop when the model keeps calling
+// tools past the iteration cap
+var errMaxIterations = errors.New("reached the maximum number of tool iterations")
+
+// approvalPolicy decides tool confirmations without prompting in headless runs
+type approvalPolicy string
+
+const (
+	approvePrompt approvalPolicy = ""      // Ask the user (interactive mode)
+	approveNone   approvalPolicy = "none"  // Deny everything that needs confirmation
+	approveSafe   approvalPolicy = "safe"  // Allow read-only commands
+	approveEdits  approvalPolicy = "edits" // Allow read-only commands and file writes/edits
+)
+
+// parseApprovalPolicy validates an --auto-approve value
+func parseApprovalPolicy(s string) (approvalPolicy, error) {
+	switch p := approvalPolicy(strings.ToLower(strings.TrimSpace(s))); p {
+	case approveNone, approveSafe, approveEdits:
+		return p, nil
+	default:
+		return approvePrompt, fmt.Errorf("invalid --auto-approve value %q. Use 'safe', 'edits', or 'none'", s)
+	}
+}
+
+// allowsCommand reports whether the policy approves a command that the
+// permission manager wants confirmed. Only read-only commands qualify.
+func (p approvalPolicy) allowsCommand(command string) bool {
+	return (p == approveSafe || p == approveEdits) && executor.ClassifyCommand(command) == executor.Safe
+}
+
+// allowsFile reports whether the policy approves a file operation.
+// Deletes are never auto-approved.
+func (p approvalPolicy) allowsFile(op string) bool {
+	return p == approveEdits && (op == "write" || op == "edit")
+}
+
+// confirmCommand asks the user to approve a command, or applies the
+// --auto-approve policy in headless runs
+func (app *App) confirmCommand(command, reasoning string) display.ApprovalChoice {
+	if app.approval == approvePrompt {
+		return display.AskCommandConfirmationExtended(command, reasoning)
+	}
+	approved := app.approval.allowsCommand(command)
+	display.ShowPolicyDecision(string(app.approval), "command", command, approved)
+	if approved {
+		return display.ApprovalOnce
+	}
+	return display.ApprovalDenied
+}
+
+// confirmFile asks the user to approve a file operation, or applies the
+// --auto-approve policy in headless runs
+func (app *App) confirmFile(op, path string) display.ApprovalChoice {
+	if app.approval == approvePrompt {
+		return display.AskFileConfirmation(op, path)
+	}
+	approved := app.approval.allowsFile(op)
+	display.ShowPolicyDecision(string(app.approval), op, path, approved)
+	if approved {
+		return display.ApprovalOnce
+	}
+	return display.ApprovalDenied
+}
+
+// newAgentCmd creates the agent command, which runs the interactive tool
+// loop without prompts for CI jobs
+func (app *App) newAgentCmd() *cobra.Command {
+	var policy string
+
+	cmd := &cobra.Command{
+		Use:   "agent [task...]",
+		Short: "Run a task with tools, without prompts",
+		Long: `Run a task headlessly with the same tools as interactive mode.
+
+Confirmation prompts are replaced by the --auto-approve policy:
+  none   deny every command or file change that needs confirmation
+  safe   also allow read-only commands (default)
+  edits  also allow file writes and edits
+
+Commands and rules already allowed in your settings run as usual; deletes and
+dangerous commands are never auto-approved. The run exits with status 1 on
+errors and 2 when it is aborted by --max-iterations or an interrupt.
+
+Examples:
+  ai-cli agent "fix the failing test"
+  ai-cli agent --auto-approve=edits --max-iterations 40 "update the changelog"
+  go test ./... 2>&1 | ai-cli agent --auto-approve=none "explain these failures"`,
+		Args: cobra.ArbitraryArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			p, err := parseApprovalPolicy(policy)
+			if err != nil {
+				display.ShowError(err.Error())
+				os.Exit(1)
+			}
+			app.approval = p
+			app.runAgent(cmd, args)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&app.verbose, "verbose", "v", false, "Enable debug mode")
+	cmd.Flags().BoolVarP(&app.cfg.Stream, "stream", "s", false, "Stream output in real-time")
+	cmd.Flags().StringVarP(&app.cfg.Model, "model", "m", "", "Model name (e.g., gpt-4.1, claude-3.7-sonnet)")
+	cmd.Flags().StringVar(&app.cfg.Provider, "provider", "", "AI provider: copilot, azure, openai, anthropic (default: auto-detect)")
+	cmd.Flags().StringArrayVarP(&app.files, "file", "f", nil, "Attach a file to the task (repeatable, - for stdin)")
+	cmd.Flags().StringVar(&policy, "auto-approve", string(approveSafe), "Approval policy for tool calls: safe, edits, or none")
+	cmd.Flags().BoolVar(&app.cfg.NoCache, "no-cache", false, "Bypass the response cache for this run")
+	cmd.Flags().IntVar(&app.maxIterations, "max-iterations", config.DefaultMaxToolIterations, "Maximum model round-trips before the run is aborted (default: limits.max_tool_iterations)")
+	app.addSamplingFlags(cmd)
+
+	return cmd
+}
+
+// runAgent runs the task through the tool loop and exits non-zero if it
+// fails or is aborted
+func (app *App) runAgent(cmd *cobra.Command, args []string) {
+	app.setupLogging()
+	app.applySamplingFlags(cmd)
+
+	if err := app.cfg.Validate(); err != nil {
+		display.ShowError(err.Error())
+		os.Exit(1)
+	}
+	if !cmd.Flags().Changed("max-iterations") {
+		app.maxIterations = app.cfg.MaxToolIterations
+	}
+	if app.maxIterations <= 0 {
+		display.ShowError("--max-iterations must be a positive number")
+		os.Exit(1)
+	}
+
+	inputs, err := collectInputs(app.files, os.Stdin, stdinIsPiped())
+	if err != nil {
+		display.ShowError(err.Error())
+		os.Exit(1)
+	}
+	task := strings.Join(args, " ")
+	if task == "" && len(inputs) == 0 {
+		_ = cmd.Help()
+		os.Exit(1)
+	}
+
+	log.Printf("Task: %s", task)
+	log.Printf("Model: %s", app.cfg.Model)
+	log.Printf("Auto-approve: %s", app.approval)
+	log.Printf("Max iterations: %d", app.maxIterations)
+
+	app.usageCommand = usageCommandAgent
+	client, err := app.newClient()
+	if err != nil {
+		display.ShowError(err.Error())
+		os.Exit(1)
+	}
+	app.client = client
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	exec := executor.NewExecutor()
+	session := &InteractiveSession{app: app, client: client, exec: exec}
+	messages := []api.Message{
+		{Role: "system", Content: config.DefaultSystemMessage + "\n\n" + AgentSystemPrompt},
+		{Role: "user", Content: buildUserMessage(task, inputs)},
+	}
+
+	_, err = app.runToolLoop(ctx, client, exec, &messages, session, app.maxIterations)
+	client.Close()
+
+	switch {
+	case err == nil:
+		return
+	case errors.Is(err, errMaxIterations), errors.Is(err, errLimitReached):
+		display.ShowError("agent aborted: " + err.Error())
+		os.Exit(exitAborted)
+	case ctx.Err() != nil:
+		display.ShowError("agent aborted: interrupted")
+		os.Exit(exitAborted)
+	default:
+		display.ShowError(err.Error())
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/quocvuong92/ai-cli/internal/api"
+	"github.com/quocvuong92/ai-cli/internal/executor"
+)
+
+// toolCallingProvider requests the given tool calls in order, then answers
+// with content
+type toolCallingProvider struct {
+	calls    []api.ToolCall
+	content  string
+	requests int
+}
+
+func (p *toolCallingProvider) Complete(ctx context.Context, r api.Request) (*api.ChatResponse, error) {
+	i := p.requests
+	p.requests++
+	if i < len(p.calls) {
+		return &api.ChatResponse{Choices: []api.Choice{{
+			Message:      api.Message{Role: "assistant", ToolCalls: []api.ToolCall{p.calls[i]}},
+			FinishReason: "tool_calls",
+		}}}, nil
+	}
+	return &api.ChatResponse{Choices: []api.Choice{{Message: api.Message{Role: "assistant", Content: p.content}}}}, nil
+}
+
+func (p *toolCallingProvider) Stream(ctx context.Context, r api.Request, handler api.StreamHandler) error {
+	resp, err := p.Complete(ctx, r)
+	if err != nil {
+		return err
+	}
+	if handler.OnDone != nil {
+		handler.OnDone(resp)
+	}
+	return nil
+}
+
+func TestParseApprovalPolicy(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    approvalPolicy
+		wantErr bool
+	}{
+		{"safe", approveSafe, false},
+		{"EDITS", approveEdits, false},
+		{" none ", approveNone, false},
+		{"all", approvePrompt, true},
+		{"", approvePrompt, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseApprovalPolicy(tt.input)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseApprovalPolicy(%q) = %q, %v, want %q, error %v", tt.input, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestApprovalPolicy_Allows(t *testing.T) {
+	tests := []struct {
+		policy   approvalPolicy
+		command  string
+		wantCmd  bool
+		op       string
+		wantFile bool
+	}{
+		{approveNone, "ls -la", false, "write", false},
+		{approveSafe, "ls -la", true, "write", false},
+		{approveSafe, "npm install", false, "edit", false},
+		{approveEdits, "git status", true, "edit", true},
+		{approveEdits, "make build", false, "write", true},
+		{approveEdits, "rm -rf /", false, "delete", false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.policy.allowsCommand(tt.command); got != tt.wantCmd {
+			t.Errorf("%s.allowsCommand(%q) = %v, want %v", tt.policy, tt.command, got, tt.wantCmd)
+		}
+		if got := tt.policy.allowsFile(tt.op); got != tt.wantFile {
+			t.Errorf("%s.allowsFile(%q) = %v, want %v", tt.policy, tt.op, got, tt.wantFile)
+		}
+	}
+}
+
+func TestHandleWriteFile_AutoApprove(t *testing.T) {
+	dir := createTestDir(t)
+
+	app := newTestApp()
+	app.approval = approveSafe
+	denied := filepath.Join(dir, "denied.txt")
+	result := app.handleWriteFile(makeToolCall("write_file", map[string]string{"path": denied, "content": "x"}))
+	if !strings.Contains(result, "denied") {
+		t.Errorf("handleWriteFile() with safe policy = %q, want denial", result)
+	}
+	if _, err := os.Stat(denied); !os.IsNotExist(err) {
+		t.Error("handleWriteFile() with safe policy should not create the file")
+	}
+
+	app.approval = approveEdits
+	allowed := filepath.Join(dir, "allowed.txt")
+	app.handleWriteFile(makeToolCall("write_file", map[string]string{"path": allowed, "content": "hello"}))
+	if data, err := os.ReadFile(allowed); err != nil || string(data) != "hello" {
+		t.Errorf("handleWriteFile() with edits policy wrote %q, %v", data, err)
+	}
+}
+
+func TestRunToolLoop(t *testing.T) {
+	t.Run("finishes", func(t *testing.T) {
+		provider := &toolCallingProvider{calls: []api.ToolCall{makeToolCall("noop", nil)}, content: "all done"}
+		app := newTestApp()
+		app.approval = approveNone
+		messages := []api.Message{{Role: "user", Content: "task"}}
+
+		content, err := app.runToolLoop(context.Background(), api.NewClientAdapter(provider), executor.NewExecutor(), &messages, &InteractiveSession{app: app}, 5)
+		if err != nil || content != "all done" {
+			t.Fatalf("runToolLoop() = %q, %v, want %q", content, err, "all done")
+		}
+		// user, assistant tool call, tool result
+		if len(messages) != 3 || messages[2].Role != "tool" || !strings.Contains(messages[2].Content, "Unknown tool") {
+			t.Errorf("runToolLoop() messages = %+v", messages)
+		}
+	})
+
+	t.Run("max iterations", func(t *testing.T) {
+		calls := make([]api.ToolCall, 10)
+		for i := range calls {
+			calls[i] = makeToolCall("noop", nil)
+		}
+		provider := &toolCallingProvider{calls: calls}
+		app := newTestApp()
+		messages := []api.Message{{Role: "user", Content: "task"}}
+
+		_, err := app.runToolLoop(context.Background(), api.NewClientAdapter(provider), executor.NewExecutor(), &messages, &InteractiveSession{app: app}, 3)
+		if !errors.Is(err, errMaxIterations) {
+			t.Fatalf("runToolLoop() error = %v, want errMaxIterations", err)
+		}
+		if provider.requests != 3 {
+			t.Errorf("runToolLoop() made %d requests, want 3", provider.requests)
+		}
+	})
+}
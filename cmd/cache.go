@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/quocvuong92/ai-cli/internal/api"
+	"github.com/quocvuong92/ai-cli/internal/config"
+	"github.com/quocvuong92/ai-cli/internal/display"
+)
+
+// newCacheCmd creates the cache command and its subcommands
+func (app *App) newCacheCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Inspect or clear the response cache",
+		Long: `Inspect or clear the on-disk response cache.
+
+The cache is opt-in: set "cache: {enabled: true}" in the config file to replay
+identical requests (same provider, model, messages, tools and sampling
+parameters) instead of sending them again. --no-cache bypasses it for one run.
+
+Examples:
+  ai-cli cache stats
+  ai-cli cache clear`,
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "stats",
+		Short: "Show the number, size and age of cached responses",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			app.runCacheStats()
+		},
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:   "clear",
+		Short: "Remove all cached responses",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			app.runCacheClear()
+		},
+	})
+	return cmd
+}
+
+// responseCache returns the response cache with the configured limits
+func (app *App) responseCache() *api.ResponseCache {
+	ttl := app.cfg.CacheTTL
+	if ttl <= 0 {
+		ttl = config.DefaultResponseCacheTTL
+	}
+	maxSizeMB := app.cfg.CacheMaxSizeMB
+	if maxSizeMB <= 0 {
+		maxSizeMB = config.DefaultResponseCacheMaxSizeMB
+	}
+	return api.NewResponseCache(ttl, int64(maxSizeMB)*1024*1024)
+}
+
+// cacheProvider identifies the provider, or fallback chain, in cache keys
+func (app *App) cacheProvider() string {
+	if len(app.cfg.FallbackChain) > 0 {
+		names := make([]string, len(app.cfg.FallbackChain))
+		for i, target := range app.cfg.FallbackChain {
+			names[i] = target.String()
+		}
+		return "fallback:" + strings.Join(names, ",")
+	}
+	return app.providerID()
+}
+
+// runCacheStats prints the response cache statistics
+func (app *App) runCacheStats() {
+	app.setupLogging()
+	if err := app.cfg.Validate(); err != nil {
+		log.Printf("Config validation warning: %v", err)
+	}
+
+	cache := app.responseCache()
+	stats, err := cache.Stats()
+	if err != nil {
+		display.ShowError(fmt.Sprintf("Failed to read the response cache: %v", err))
+		os.Exit(1)
+	}
+
+	enabled := "disabled (set cache.enabled in the config file)"
+	if app.cfg.Cache {
+		enabled = "enabled"
+	}
+	fmt.Printf("Response cache: %s\n", enabled)
+	fmt.Printf("  %-10s %s\n", "Location", stats.Dir)
+	fmt.Printf("  %-10s %d (%d expired)\n", "Entries", stats.Entries, stats.Expired)
+	fmt.Printf("  %-10s %s of %s\n", "Size", formatBytes(stats.Size), formatBytes(stats.MaxSize))
+	fmt.Printf("  %-10s %s\n", "TTL", stats.TTL)
+	if stats.Entries > 0 {
+		fmt.Printf("  %-10s %s\n", "Oldest", stats.Oldest.Format(time.DateTime))
+		fmt.Printf("  %-10s %s\n", "Newest", stats.Newest.Format(time.DateTime))
+	}
+}
+
+// runCacheClear removes all cached responses
+func (app *App) runCacheClear() {
+	app.setupLogging()
+	removed, err := app.responseCache().Clear()
+	if err != nil {
+		display.ShowError(fmt.Sprintf("Failed to clear the response cache: %v", err))
+		os.Exit(1)
+	}
+	fmt.Printf("Removed %d cached responses.\n", removed)
+}
+
+// formatBytes formats a size in bytes, e.g. "1.5 MB"
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1024*1024:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1024*1024))
+	case n >= 1024:
+		return fmt.Sprintf("%.1f KB", float64(n)/1024)
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
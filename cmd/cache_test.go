@@ -0,0 +1,20 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/quocvuong92/ai-cli/internal/config"
+)
+
+func TestApp_ResponseCacheDefaults(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	app := newTestApp()
+
+	stats, err := app.responseCache().Stats()
+	if err != nil {
+		t.Fatalf("Stats() error = %v", err)
+	}
+	if stats.TTL != config.DefaultResponseCacheTTL || stats.MaxSize != int64(config.DefaultResponseCacheMaxSizeMB)*1024*1024 {
+		t.Errorf("limits without config = %s, %d bytes; want the defaults", stats.TTL, stats.MaxSize)
+	}
+}
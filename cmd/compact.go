@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/quocvuong92/ai-cli/internal/api"
+	"github.com/quocvuong92/ai-cli/internal/display"
+)
+
+// handleCompactCommand processes /compact [instructions]: older turns are
+// replaced by a summary from the current model, keeping the system prompt
+// and the last turns, and the result is saved to history.
+func (app *App) handleCompactCommand(parts []string, messages *[]api.Message, client api.AIClient, session *InteractiveSession) {
+	instructions := ""
+	if len(parts) > 1 {
+		instructions = strings.TrimSpace(parts[1])
+	}
+
+	ctx := context.Background()
+	if session != nil && session.interruptCtx != nil {
+		ctx = session.interruptCtx.Start()
+		defer session.interruptCtx.Stop()
+	}
+
+	before, _ := app.contextUsage(*messages, nil)
+
+	sp := display.NewSpinner("Compacting conversation...")
+	sp.Start()
+	compacted, n, err := app.summarizeTurns(ctx, client, *messages, keepRecentTurns, instructions)
+	sp.Stop()
+
+	if err != nil {
+		if err == context.Canceled {
+			return
+		}
+		display.ShowError(fmt.Sprintf("Failed to compact conversation: %v", err))
+		return
+	}
+	if n == 0 {
+		fmt.Printf("Nothing to compact: the conversation has no more than %d turns.\n", keepRecentTurns)
+		return
+	}
+
+	*messages = compacted
+	after, _ := app.contextUsage(*messages, nil)
+	fmt.Printf("Compacted %d messages into a summary (%s → %s tokens).\n", n, display.FormatTokens(before), display.FormatTokens(after))
+	app.showContextUsage(*messages)
+
+	if session != nil {
+		session.saveHistory()
+	}
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/quocvuong92/ai-cli/internal/api"
+)
+
+func TestHandleCompactCommand(t *testing.T) {
+	app := newTestApp()
+	provider := &scriptedProvider{replies: []string{"earlier: fixed the parser"}}
+	messages := []api.Message{
+		{Role: "system", Content: "sys"},
+		{Role: "user", Content: "one"},
+		{Role: "assistant", Content: "1"},
+		{Role: "user", Content: "two"},
+		{Role: "assistant", Content: "2"},
+		{Role: "user", Content: "three"},
+		{Role: "assistant", Content: "3"},
+	}
+
+	app.handleCompactCommand([]string{"/compact", "keep error messages"}, &messages, api.NewClientAdapter(provider), nil)
+
+	if len(provider.requests) != 1 {
+		t.Fatalf("summary requests = %d, want 1", len(provider.requests))
+	}
+	if prompt := provider.requests[0].Messages[0].Content; !strings.Contains(prompt, "keep error messages") {
+		t.Errorf("instructions missing from prompt: %q", prompt)
+	}
+	want := []string{"sys", summaryPrefix + "earlier: fixed the parser", "two", "2", "three", "3"}
+	if len(messages) != len(want) {
+		t.Fatalf("messages = %+v", messages)
+	}
+	for i, content := range want {
+		if messages[i].Content != content {
+			t.Errorf("messages[%d] = %q, want %q", i, messages[i].Content, content)
+		}
+	}
+
+	// With only the kept turns left there is nothing more to compact
+	short := messages[2:]
+	app.handleCompactCommand([]string{"/compact"}, &short, api.NewClientAdapter(provider), nil)
+	if len(provider.requests) != 1 {
+		t.Errorf("compacted a conversation with only recent turns")
+	}
+}
@@ -48,3 +48,8 @@ Instructions:
 const WebContextMessageTemplate = `Web search results for additional context (cite using [1], [2], etc. if relevant):
 
 %s`
+
+// AgentSystemPrompt is appended to the system message in headless agent runs
+const AgentSystemPrompt = `You are running non-interactively as an agent. No user is available to answer questions.
+Work through the task with the available tools and finish with a short summary of what you did.
+Tool calls may be denied by the approval policy; if an action is denied, do not retry it - find another way or explain what is left to do.`
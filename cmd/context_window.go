@@ -0,0 +1,212 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/quocvuong92/ai-cli/internal/api"
+	"github.com/quocvuong92/ai-cli/internal/display"
+	"github.com/quocvuong92/ai-cli/internal/tokens"
+)
+
+const (
+	// toolOutputRemoved replaces tool results dropped to save context. The
+	// message itself stays so every tool call keeps its response.
+	toolOutputRemoved = "[tool output removed to save context]"
+
+	// summaryPrefix marks the system message holding summarized turns
+	summaryPrefix = "Summary of the earlier conversation:\n"
+
+	// keepRecentTurns is how many user turns are never summarized
+	keepRecentTurns = 2
+
+	// summaryExcerptChars limits how much of each message the summarizer sees
+	summaryExcerptChars = 2000
+)
+
+// summarizePrompt asks the model to condense a transcript
+const summarizePrompt = `Summarize the conversation below so it can replace the original messages.
+Keep facts, decisions, file names, commands and open questions; drop pleasantries.
+Reply with the summary only.`
+
+// Per-message overhead of the chat format, as counted by OpenAI, and the
+// cost of a high-detail 1024x1024 image
+const (
+	tokensPerMessage = 3
+	tokensPerReply   = 3
+	tokensPerImage   = 765
+)
+
+// countMessages estimates the prompt tokens of messages
+func countMessages(counter tokens.Counter, messages []api.Message) int {
+	total := tokensPerReply
+	for _, m := range messages {
+		total += tokensPerMessage + counter.Count(m.Role) + counter.Count(m.Content)
+		total += tokensPerImage * len(m.Images())
+		for _, tc := range m.ToolCalls {
+			total += counter.Count(tc.Function.Name) + counter.Count(tc.Function.Arguments)
+		}
+	}
+	return total
+}
+
+// countTools estimates the prompt tokens taken by tool definitions
+func countTools(counter tokens.Counter, tools []api.Tool) int {
+	if len(tools) == 0 {
+		return 0
+	}
+	data, err := json.Marshal(tools)
+	if err != nil {
+		return 0
+	}
+	return counter.Count(string(data))
+}
+
+// contextUsage returns the tokens messages and tools take and the current
+// model's context window
+func (app *App) contextUsage(messages []api.Message, tools []api.Tool) (used, window int) {
+	counter := tokens.ForModel(app.cfg.Model)
+	used = countMessages(counter, messages) + countTools(counter, tools)
+	return used, api.Capabilities.Lookup(app.cfg.Model).ContextWindow
+}
+
+// showContextUsage prints how full the context window is
+func (app *App) showContextUsage(messages []api.Message) {
+	var tools []api.Tool
+	if api.Capabilities.Lookup(app.cfg.Model).ToolCalls {
+		tools = api.GetDefaultTools()
+	}
+	used, window := app.contextUsage(messages, tools)
+	display.ShowContextUsage(used, window, tokens.IsEstimate(tokens.ForModel(app.cfg.Model)))
+}
+
+// fitContext trims messages in place so they fit the model's history
+// budget. Old tool outputs are dropped first, then older turns are replaced
+// by a summary. The current turn is never touched.
+func (app *App) fitContext(ctx context.Context, client api.AIClient, messages *[]api.Message, tools []api.Tool) {
+	counter := tokens.ForModel(app.cfg.Model)
+	budget := api.Capabilities.Lookup(app.cfg.Model).HistoryBudget() - countTools(counter, tools)
+	fits := func() bool { return countMessages(counter, *messages) <= budget }
+	if fits() {
+		return
+	}
+
+	if dropped := dropToolOutputs(counter, *messages, budget); dropped > 0 {
+		log.Printf("Context: dropped %d old tool outputs", dropped)
+		if fits() {
+			return
+		}
+	}
+
+	for keep := keepRecentTurns; keep >= 1 && !fits(); keep-- {
+		compacted, n, err := app.summarizeTurns(ctx, client, *messages, keep, "")
+		if err != nil {
+			display.ShowWarning(fmt.Sprintf("Could not summarize older messages: %v", err))
+			return
+		}
+		if n == 0 {
+			continue
+		}
+		*messages = compacted
+		display.ShowContextSummarized(n)
+	}
+}
+
+// dropToolOutputs blanks the oldest tool results before the current turn
+// until messages fit budget, returning how many were dropped
+func dropToolOutputs(counter tokens.Counter, messages []api.Message, budget int) int {
+	current := lastUserIndex(messages)
+	used := countMessages(counter, messages)
+	dropped := 0
+	for i := 0; i < current && used > budget; i++ {
+		m := &messages[i]
+		if m.Role != "tool" || m.Content == toolOutputRemoved {
+			continue
+		}
+		used -= counter.Count(m.Content) - counter.Count(toolOutputRemoved)
+		m.Content = toolOutputRemoved
+		dropped++
+	}
+	return dropped
+}
+
+// summarizeTurns replaces everything between the leading system prompt and
+// the last keep user turns with a summary, returning the new messages and
+// how many were summarized. instructions, if any, steer the summary.
+func (app *App) summarizeTurns(ctx context.Context, client api.AIClient, messages []api.Message, keep int, instructions string) ([]api.Message, int, error) {
+	head := 0
+	for head < len(messages) && messages[head].Role == "system" && !strings.HasPrefix(messages[head].Content, summaryPrefix) {
+		head++
+	}
+	cut := len(messages)
+	for seen := 0; cut > head && seen < keep; {
+		cut--
+		if messages[cut].Role == "user" {
+			seen++
+		}
+	}
+	if cut <= head {
+		return messages, 0, nil
+	}
+
+	summary, err := app.summarize(ctx, client, messages[head:cut], instructions)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	compacted := make([]api.Message, 0, head+1+len(messages)-cut)
+	compacted = append(compacted, messages[:head]...)
+	compacted = append(compacted, api.Message{Role: "system", Content: summaryPrefix + summary})
+	compacted = append(compacted, messages[cut:]...)
+	return compacted, cut - head, nil
+}
+
+// summarize asks the model for a summary of messages
+func (app *App) summarize(ctx context.Context, client api.AIClient, messages []api.Message, instructions string) (string, error) {
+	var transcript strings.Builder
+	for _, m := range messages {
+		content := strings.TrimPrefix(m.Content, summaryPrefix)
+		if runes := []rune(content); len(runes) > summaryExcerptChars {
+			content = string(runes[:summaryExcerptChars]) + " [...]"
+		}
+		if n := len(m.Images()); n > 0 {
+			content += fmt.Sprintf("\n(%d image(s) attached)", n)
+		}
+		for _, tc := range m.ToolCalls {
+			content += fmt.Sprintf("\n(called %s %s)", tc.Function.Name, tc.Function.Arguments)
+		}
+		fmt.Fprintf(&transcript, "%s: %s\n\n", m.Role, content)
+	}
+
+	prompt := summarizePrompt
+	if instructions != "" {
+		prompt += "\n\nAdditional instructions: " + instructions
+	}
+	req := app.newRequest([]api.Message{
+		{Role: "system", Content: prompt},
+		{Role: "user", Content: transcript.String()},
+	}, nil)
+	resp, err := client.Complete(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	summary := strings.TrimSpace(resp.GetContent())
+	if summary == "" {
+		return "", fmt.Errorf("empty summary")
+	}
+	return summary, nil
+}
+
+// lastUserIndex returns the index of the last user message, or len(messages)
+// if there is none
+func lastUserIndex(messages []api.Message) int {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == "user" {
+			return i
+		}
+	}
+	return len(messages)
+}
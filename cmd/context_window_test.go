@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/quocvuong92/ai-cli/internal/api"
+	"github.com/quocvuong92/ai-cli/internal/config"
+)
+
+// withContextWindow gives test-model a small context window for the test
+func withContextWindow(t *testing.T, window int) {
+	t.Helper()
+	api.Capabilities.SetOverrides(map[string]config.ModelCapabilityOverride{
+		"test-model": {ContextWindow: window, MaxOutputTokens: window / 4},
+	})
+	t.Cleanup(func() { api.Capabilities.SetOverrides(nil) })
+}
+
+func TestFitContext_DropsOldToolOutputsFirst(t *testing.T) {
+	withContextWindow(t, 1000)
+	app := newTestApp()
+	provider := &scriptedProvider{}
+
+	call := makeToolCall("read_file", map[string]string{"path": "big.txt"})
+	messages := []api.Message{
+		{Role: "system", Content: "sys"},
+		{Role: "user", Content: "read big.txt"},
+		{Role: "assistant", ToolCalls: []api.ToolCall{call}},
+		{Role: "tool", ToolCallID: call.ID, Content: strings.Repeat("data ", 1000)},
+		{Role: "assistant", Content: "done"},
+		{Role: "user", Content: "thanks"},
+	}
+
+	app.fitContext(context.Background(), api.NewClientAdapter(provider), &messages, nil)
+
+	if len(messages) != 6 || messages[3].Content != toolOutputRemoved {
+		t.Errorf("old tool output not dropped: %+v", messages)
+	}
+	if len(provider.requests) != 0 {
+		t.Errorf("summarized although dropping tool outputs was enough")
+	}
+}
+
+func TestFitContext_SummarizesOlderTurns(t *testing.T) {
+	withContextWindow(t, 1000)
+	app := newTestApp()
+	provider := &scriptedProvider{replies: []string{"they discussed long things"}}
+
+	long := strings.Repeat("words ", 400)
+	messages := []api.Message{
+		{Role: "system", Content: "sys"},
+		{Role: "user", Content: "first " + long},
+		{Role: "assistant", Content: long},
+		{Role: "user", Content: "second"},
+		{Role: "assistant", Content: "ok"},
+		{Role: "user", Content: "third"},
+	}
+
+	app.fitContext(context.Background(), api.NewClientAdapter(provider), &messages, nil)
+
+	if len(provider.requests) != 1 {
+		t.Fatalf("summary requests = %d, want 1", len(provider.requests))
+	}
+	if transcript := provider.requests[0].Messages[1].Content; !strings.Contains(transcript, "user: first") {
+		t.Errorf("summarizer did not get the old turns: %.80q", transcript)
+	}
+	want := []string{"sys", summaryPrefix + "they discussed long things", "second", "ok", "third"}
+	if len(messages) != len(want) {
+		t.Fatalf("messages = %+v", messages)
+	}
+	for i, content := range want {
+		if messages[i].Content != content {
+			t.Errorf("messages[%d] = %q, want %q", i, messages[i].Content, content)
+		}
+	}
+}
+
+func TestFitContext_UnderBudgetUnchanged(t *testing.T) {
+	withContextWindow(t, 100000)
+	app := newTestApp()
+	provider := &scriptedProvider{}
+	messages := []api.Message{{Role: "system", Content: "sys"}, {Role: "user", Content: "hi"}}
+
+	app.fitContext(context.Background(), api.NewClientAdapter(provider), &messages, api.GetDefaultTools())
+
+	if len(messages) != 2 || len(provider.requests) != 0 {
+		t.Errorf("messages changed under budget: %+v", messages)
+	}
+}
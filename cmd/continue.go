@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/quocvuong92/ai-cli/internal/api"
+	"github.com/quocvuong92/ai-cli/internal/display"
+)
+
+// continuePrompt asks the model to pick up a reply that was cut off
+const continuePrompt = "Your previous reply was cut off. Continue exactly where it stopped, without repeating anything."
+
+// maxStreamContinuations is how many times a one-shot stream that drops is
+// continued automatically
+const maxStreamContinuations = 2
+
+// continuationMessages returns messages followed by the partial reply and
+// the instruction to continue it
+func continuationMessages(messages []api.Message, partial string) []api.Message {
+	out := make([]api.Message, 0, len(messages)+2)
+	out = append(out, messages...)
+	return append(out,
+		api.Message{Role: "assistant", Content: partial},
+		api.Message{Role: "user", Content: continuePrompt},
+	)
+}
+
+// streamWithContinue streams req and, when the stream drops after content
+// has arrived, asks the model to continue from where it stopped. The
+// handler sees one uninterrupted stream; OnDone receives the joined reply.
+func (app *App) streamWithContinue(ctx context.Context, client api.AIClient, req api.Request, handler api.StreamHandler) error {
+	messages := req.Messages
+	var partial string
+
+	for attempt := 0; ; attempt++ {
+		var final *api.ChatResponse
+		h := handler
+		h.OnDone = func(resp *api.ChatResponse) { final = resp }
+
+		err := client.Stream(ctx, req, h)
+		var interrupted *api.StreamInterruptedError
+		if errors.As(err, &interrupted) && attempt < maxStreamContinuations && ctx.Err() == nil {
+			partial += interrupted.Partial.GetContent()
+			display.ShowWarning(fmt.Sprintf("Response interrupted (%v); continuing", interrupted.Err))
+			req.Messages = continuationMessages(messages, partial)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if handler.OnDone != nil {
+			if final == nil {
+				final = contentResponse("")
+			}
+			if partial != "" && len(final.Choices) > 0 {
+				final.Choices[0].Message.Content = partial + final.Choices[0].Message.Content
+			}
+			handler.OnDone(final)
+		}
+		return nil
+	}
+}
+
+// respond sends the conversation, which ends with a user message, and
+// records the reply. If the request fails the messages from rollback on are
+// dropped; a reply cut off mid-stream is kept for /continue. It reports
+// whether the message reached the model.
+func (s *InteractiveSession) respond(rollback int) bool {
+	response, err := s.app.sendInteractiveMessageWithTools(s.client, s.exec, &s.messages, s.interruptCtx, s)
+
+	var interrupted *api.StreamInterruptedError
+	switch {
+	case errors.As(err, &interrupted):
+		s.messages = append(s.messages, api.Message{Role: "assistant", Content: interrupted.Partial.GetContent()})
+		s.interrupted = true
+		s.saveHistory()
+		display.ShowWarning(fmt.Sprintf("Response interrupted (%v). Use /continue to resume it or /retry to ask again.", interrupted.Err))
+		return true
+	case err == context.Canceled:
+		s.messages = s.messages[:rollback]
+		return false
+	case err != nil:
+		display.ShowError(err.Error())
+		s.messages = s.messages[:rollback]
+		return false
+	}
+
+	s.interrupted = false
+	if response != "" {
+		s.messages = append(s.messages, api.Message{Role: "assistant", Content: response})
+	}
+	s.saveHistory()
+	s.app.showContextUsage(s.messages)
+	fmt.Println()
+	return true
+}
+
+// handleContinueCommand processes /continue: the model is asked to finish
+// an interrupted reply, and the continuation is joined onto it
+func (s *InteractiveSession) handleContinueCommand() {
+	last := len(s.messages) - 1
+	if !s.interrupted || last < 0 || s.messages[last].Role != "assistant" {
+		fmt.Println("Nothing to continue: the last response was not interrupted.")
+		return
+	}
+
+	s.messages = append(s.messages, api.Message{Role: "user", Content: continuePrompt})
+	fmt.Println()
+	if !s.respond(last + 1) {
+		return
+	}
+
+	// Fold a plain continuation into the interrupted reply so history reads
+	// as one answer
+	if len(s.messages) == last+3 && s.messages[last+2].Role == "assistant" && len(s.messages[last+2].ToolCalls) == 0 {
+		s.messages[last].Content += s.messages[last+2].Content
+		s.messages = s.messages[:last+1]
+		s.saveHistory()
+	}
+}
+
+// handleRetryCommand processes /retry: everything after the last user
+// message is dropped and the message is sent again
+func (s *InteractiveSession) handleRetryCommand() {
+	// The follow-ups /continue sends aren't worth retrying on their own
+	last := len(s.messages)
+	for i := len(s.messages) - 1; i >= 0; i-- {
+		if s.messages[i].Role == "user" && s.messages[i].Content != continuePrompt {
+			last = i
+			break
+		}
+	}
+	if last == len(s.messages) {
+		fmt.Println("Nothing to retry.")
+		return
+	}
+
+	s.messages = s.messages[:last+1]
+	s.interrupted = false
+	fmt.Println()
+	s.respond(last)
+}
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/quocvuong92/ai-cli/internal/api"
+	"github.com/quocvuong92/ai-cli/internal/executor"
+)
+
+// interruptedAfter returns the error of a stream that dropped after partial
+func interruptedAfter(partial string) error {
+	return &api.StreamInterruptedError{Partial: contentResponse(partial), Err: errors.New("connection reset")}
+}
+
+func TestStreamWithContinue(t *testing.T) {
+	provider := &scriptedProvider{
+		replies: []string{"", "lo, world"},
+		errs:    []error{interruptedAfter("Hel")},
+	}
+	app := newTestApp()
+
+	var final *api.ChatResponse
+	req := api.Request{Messages: []api.Message{{Role: "user", Content: "greet"}}}
+	err := app.streamWithContinue(context.Background(), api.NewClientAdapter(provider), req, api.StreamHandler{
+		OnChunk: func(string) {},
+		OnDone:  func(resp *api.ChatResponse) { final = resp },
+	})
+	if err != nil {
+		t.Fatalf("streamWithContinue() error = %v", err)
+	}
+	if final == nil || final.GetContent() != "Hello, world" {
+		t.Fatalf("final response = %+v, want joined content", final)
+	}
+
+	if len(provider.requests) != 2 {
+		t.Fatalf("requests = %d, want 2", len(provider.requests))
+	}
+	sent := provider.requests[1].Messages
+	if len(sent) != 3 || sent[1].Role != "assistant" || sent[1].Content != "Hel" || sent[2].Content != continuePrompt {
+		t.Errorf("continuation messages = %+v", sent)
+	}
+}
+
+func TestStreamWithContinue_GivesUp(t *testing.T) {
+	provider := &scriptedProvider{errs: []error{interruptedAfter("a"), interruptedAfter("b"), interruptedAfter("c")}}
+	app := newTestApp()
+
+	err := app.streamWithContinue(context.Background(), api.NewClientAdapter(provider), api.Request{}, api.StreamHandler{OnChunk: func(string) {}})
+	var interrupted *api.StreamInterruptedError
+	if !errors.As(err, &interrupted) {
+		t.Errorf("error = %v, want StreamInterruptedError", err)
+	}
+	if len(provider.requests) != maxStreamContinuations+1 {
+		t.Errorf("requests = %d, want %d", len(provider.requests), maxStreamContinuations+1)
+	}
+}
+
+func TestInteractiveSession_ContinueAndRetry(t *testing.T) {
+	provider := &scriptedProvider{
+		replies: []string{"", "lo", "Hi"},
+		errs:    []error{interruptedAfter("Hel")},
+	}
+	app := newTestApp()
+	app.cfg.Stream = true
+	s := &InteractiveSession{
+		app:          app,
+		client:       api.NewClientAdapter(provider),
+		exec:         executor.NewExecutor(),
+		messages:     []api.Message{{Role: "system", Content: "sys"}, {Role: "user", Content: "greet"}},
+		interruptCtx: NewInterruptibleContext(),
+	}
+
+	if !s.respond(1) {
+		t.Fatal("respond() = false, want true for an interrupted reply")
+	}
+	if !s.interrupted || len(s.messages) != 3 || s.messages[2].Content != "Hel" {
+		t.Fatalf("after interruption: interrupted = %v, messages = %+v", s.interrupted, s.messages)
+	}
+
+	s.handleContinueCommand()
+	if s.interrupted || len(s.messages) != 3 || s.messages[2].Content != "Hello" {
+		t.Fatalf("after /continue: interrupted = %v, messages = %+v", s.interrupted, s.messages)
+	}
+
+	s.handleRetryCommand()
+	if len(s.messages) != 3 || s.messages[2].Content != "Hi" {
+		t.Errorf("after /retry: messages = %+v", s.messages)
+	}
+	if sent := provider.requests[2].Messages; len(sent) != 2 || sent[1].Content != "greet" {
+		t.Errorf("/retry sent %+v, want the conversation up to the last user message", sent)
+	}
+}
+
+func TestInteractiveSession_RetryOnlyContinuation(t *testing.T) {
+	// A summarized conversation can be left with a /continue follow-up as
+	// its only user message
+	provider := &scriptedProvider{}
+	s := &InteractiveSession{
+		app:    newTestApp(),
+		client: api.NewClientAdapter(provider),
+		messages: []api.Message{
+			{Role: "system", Content: "sys"},
+			{Role: "user", Content: continuePrompt},
+			{Role: "assistant", Content: "the rest"},
+		},
+		interruptCtx: NewInterruptibleContext(),
+	}
+
+	s.handleRetryCommand()
+	if len(s.messages) != 3 || len(provider.requests) != 0 {
+		t.Errorf("/retry with nothing to retry changed messages to %+v and sent %d requests", s.messages, len(provider.requests))
+	}
+}
@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/quocvuong92/ai-cli/internal/display"
+	"github.com/quocvuong92/ai-cli/internal/export"
+	"github.com/quocvuong92/ai-cli/internal/history"
+)
+
+// exportArgs is what /export and "history export" were asked for
+type exportArgs struct {
+	format string
+	path   string
+	redact bool
+}
+
+// parseExportArgs reads the arguments of /export: an optional format, an
+// optional path and --redact, in any order. Without a format it comes from
+// the path's extension, else Markdown.
+func parseExportArgs(args []string) (exportArgs, error) {
+	var a exportArgs
+	for _, arg := range args {
+		switch {
+		case arg == "--redact":
+			a.redact = true
+		case a.format == "" && isExportFormat(arg):
+			a.format, _ = export.ParseFormat(arg)
+		case a.path == "":
+			a.path = arg
+		default:
+			return a, fmt.Errorf("unexpected argument %q. Usage: /export [md|html|json] [path] [--redact]", arg)
+		}
+	}
+	if a.format == "" {
+		a.format = export.FormatMarkdown
+		if format, ok := export.FormatForPath(a.path); ok {
+			a.format = format
+		}
+	}
+	return a, nil
+}
+
+// isExportFormat reports whether arg names an export format
+func isExportFormat(arg string) bool {
+	_, err := export.ParseFormat(arg)
+	return err == nil
+}
+
+// exportFileName is the default file a conversation is exported to
+func exportFileName(conv history.ConversationEntry, format string) string {
+	return "conversation-" + shortID(conv.ID) + "." + format
+}
+
+// writeExport renders conv to path, or to stdout if path is empty
+func writeExport(conv history.ConversationEntry, a exportArgs) error {
+	opts := export.Options{Redact: a.redact}
+	if a.path == "" {
+		return export.Write(os.Stdout, conv, a.format, opts)
+	}
+	f, err := os.Create(a.path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", a.path, err)
+	}
+	if err := export.Write(f, conv, a.format, opts); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// handleExportCommand processes /export [md|html|json] [path] [--redact]
+func (s *InteractiveSession) handleExportCommand(parts []string) {
+	if len(s.messages) <= 1 {
+		fmt.Println("Nothing to export yet.")
+		return
+	}
+	var args []string
+	if len(parts) > 1 {
+		args = strings.Fields(parts[1])
+	}
+	a, err := parseExportArgs(args)
+	if err != nil {
+		display.ShowError(err.Error())
+		return
+	}
+
+	conv := s.currentConversation()
+	if a.path == "" {
+		a.path = exportFileName(conv, a.format)
+	}
+	if err := writeExport(conv, a); err != nil {
+		display.ShowError(err.Error())
+		return
+	}
+	fmt.Printf("Exported conversation to %s\n", a.path)
+}
+
+// currentConversation returns the conversation in progress as a history
+// entry, keeping the creation time of its saved entry if there is one
+func (s *InteractiveSession) currentConversation() history.ConversationEntry {
+	now := time.Now()
+	conv := history.ConversationEntry{
+		ID:        s.conversationID,
+		Model:     s.app.cfg.Model,
+		Provider:  s.app.getProviderName(),
+		Messages:  s.messages,
+		CreatedAt: now,
+		UpdatedAt: now,
+		Sources:   s.sources,
+	}
+	if s.history != nil {
+		if saved := s.history.GetConversation(s.conversationID); saved != nil {
+			conv.CreatedAt = saved.CreatedAt
+		}
+	}
+	return conv
+}
+
+// newHistoryExportCmd creates the "history export" command
+func (app *App) newHistoryExportCmd() *cobra.Command {
+	var a exportArgs
+	var format string
+
+	cmd := &cobra.Command{
+		Use:   "export <id|index>",
+		Short: "Export a saved conversation as Markdown, HTML or JSON",
+		Long: `Export a saved conversation as Markdown, HTML or JSON, to stdout or a file.
+
+Tool calls and their results become collapsible sections, plans become
+checklists and web search citations become links. --redact removes secrets
+such as API keys and tokens from tool calls and tool output.
+
+Examples:
+  ai-cli history export 1 > session.md
+  ai-cli history export 3f9a1c2e -o incident.html --redact
+  ai-cli history export 2 --format json -o session.json`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			hist := app.loadHistoryOrExit()
+			conv, err := hist.Resolve(args[0])
+			if err != nil {
+				display.ShowError(err.Error())
+				os.Exit(1)
+			}
+
+			a.format = export.FormatMarkdown
+			if f, ok := export.FormatForPath(a.path); ok {
+				a.format = f
+			}
+			if format != "" {
+				if a.format, err = export.ParseFormat(format); err != nil {
+					display.ShowError(err.Error())
+					os.Exit(1)
+				}
+			}
+
+			if err := writeExport(*conv, a); err != nil {
+				display.ShowError(err.Error())
+				os.Exit(1)
+			}
+			if a.path != "" {
+				fmt.Fprintf(os.Stderr, "Exported conversation to %s\n", a.path)
+			}
+		},
+	}
+
+	cmd.Flags().StringVarP(&format, "format", "f", "", "Export format: "+strings.Join(export.Formats, ", ")+" (default: from the output extension, else md)")
+	cmd.Flags().StringVarP(&a.path, "output", "o", "", "Write to a file instead of stdout")
+	cmd.Flags().BoolVar(&a.redact, "redact", false, "Remove secrets from tool calls and tool output")
+
+	return cmd
+}
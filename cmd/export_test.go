@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/quocvuong92/ai-cli/internal/api"
+	"github.com/quocvuong92/ai-cli/internal/history"
+)
+
+func TestParseExportArgs(t *testing.T) {
+	tests := []struct {
+		args    []string
+		want    exportArgs
+		wantErr bool
+	}{
+		{nil, exportArgs{format: "md"}, false},
+		{[]string{"html"}, exportArgs{format: "html"}, false},
+		{[]string{"notes/session.json"}, exportArgs{format: "json", path: "notes/session.json"}, false},
+		{[]string{"md", "out.txt", "--redact"}, exportArgs{format: "md", path: "out.txt", redact: true}, false},
+		{[]string{"out.html", "json"}, exportArgs{format: "json", path: "out.html"}, false},
+		{[]string{"a.md", "b.md"}, exportArgs{}, true},
+	}
+	for _, tt := range tests {
+		got, err := parseExportArgs(tt.args)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseExportArgs(%q) = %+v, want an error", tt.args, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("parseExportArgs(%q) = %+v, %v; want %+v", tt.args, got, err, tt.want)
+		}
+	}
+}
+
+func TestInteractiveSession_RecordSources(t *testing.T) {
+	s := &InteractiveSession{
+		app:      newTestApp(),
+		messages: []api.Message{{Role: "user", Content: "q"}, {Role: "assistant", Content: "Answer [1]"}},
+		history:  history.NewHistoryAt(t.TempDir()),
+	}
+	s.recordSources(&api.TavilyResponse{Results: []api.TavilyResult{{Title: "Go", URL: "https://go.dev"}}})
+
+	conv := s.currentConversation()
+	if sources := conv.SourcesOf(s.messages[1]); len(sources) != 1 || sources[0].URL != "https://go.dev" {
+		t.Errorf("SourcesOf() = %+v", sources)
+	}
+
+	s.saveHistory()
+	if saved := s.history.GetConversation(s.conversationID); saved == nil || len(saved.Sources) != 1 {
+		t.Errorf("saved conversation sources = %+v", saved)
+	}
+}
@@ -0,0 +1,388 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/elk-language/go-prompt"
+	"github.com/spf13/cobra"
+
+	"github.com/quocvuong92/ai-cli/internal/display"
+	"github.com/quocvuong92/ai-cli/internal/history"
+)
+
+const (
+	// pickerSize is how many conversations the /resume picker lists at once
+	pickerSize = 10
+
+	// shortIDLength is how much of a conversation ID is shown in lists;
+	// any unique prefix resolves
+	shortIDLength = 8
+
+	// fuzzyTextChars limits how much of each message the picker matches against
+	fuzzyTextChars = 200
+)
+
+// newHistoryCmd creates the history command and its subcommands
+func (app *App) newHistoryCmd() *cobra.Command {
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "List, show, search, export, import or delete saved conversations",
+		Long: `List, show, search, export, import or delete the conversations saved by interactive mode.
+
+Conversations are referred to by their ID, a unique ID prefix, or their
+number in "history list" (1 is the most recent). IDs are matched first, so a
+number that is also an ID prefix means the ID; "#2" always means number 2.
+
+Examples:
+  ai-cli history list
+  ai-cli history show 2
+  ai-cli history search "connection pool"
+  ai-cli history export 1 -o session.md
+  ai-cli history import session.json
+  ai-cli history delete 3f9a1c2e`,
+	}
+
+	list := &cobra.Command{
+		Use:   "list",
+		Short: "List saved conversations, most recent first",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			hist := app.loadHistoryOrExit()
+			conversations := hist.Newest(limit)
+			if len(conversations) == 0 {
+				fmt.Println("No conversation history.")
+				return
+			}
+			for i, conv := range conversations {
+				fmt.Println(formatConversation(i+1, conv))
+			}
+		},
+	}
+	list.Flags().IntVarP(&limit, "limit", "n", 20, "Number of conversations to list (0 for all)")
+
+	show := &cobra.Command{
+		Use:   "show <id|index>",
+		Short: "Print a saved conversation",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			hist := app.loadHistoryOrExit()
+			conv, err := hist.Resolve(args[0])
+			if err != nil {
+				display.ShowError(err.Error())
+				os.Exit(1)
+			}
+			fmt.Printf("Conversation %s\n", conv.ID)
+			fmt.Printf("Model:    %s (%s)\n", conv.Model, conv.Provider)
+			fmt.Printf("Created:  %s\n", conv.CreatedAt.Format("2006-01-02 15:04"))
+			fmt.Printf("Updated:  %s\n\n", conv.UpdatedAt.Format("2006-01-02 15:04"))
+			app.showConversation(*conv)
+		},
+	}
+
+	search := &cobra.Command{
+		Use:   "search <text>",
+		Short: "Find conversations whose messages contain text",
+		Args:  cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			hist := app.loadHistoryOrExit()
+			results := hist.Search(strings.Join(args, " "))
+			if len(results) == 0 {
+				fmt.Println("No matching conversations.")
+				return
+			}
+			for _, r := range results {
+				fmt.Println(formatConversation(r.Index, r.Entry))
+				for _, excerpt := range r.Excerpts {
+					fmt.Printf("      %s\n", excerpt)
+				}
+			}
+		},
+	}
+
+	imp := &cobra.Command{
+		Use:   "import <file>",
+		Short: "Import a conversation from JSON",
+		Long: `Import a conversation from a JSON file: an "ai-cli history export --format json"
+export, an object with an OpenAI-style "messages" array (such as a Chat
+Completions request body), or a bare messages array. The imported
+conversation becomes the most recent one; "-" reads from stdin.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			data, err := readImportFile(args[0])
+			if err != nil {
+				display.ShowError(err.Error())
+				os.Exit(1)
+			}
+			conv, err := history.ParseConversation(data)
+			if err != nil {
+				display.ShowError(fmt.Sprintf("%s: %v", args[0], err))
+				os.Exit(1)
+			}
+			hist := app.loadHistoryOrExit()
+			id := hist.ImportConversation(conv)
+			if err := hist.Save(); err != nil {
+				display.ShowError(err.Error())
+				os.Exit(1)
+			}
+			imported := hist.GetConversation(id)
+			fmt.Printf("Imported conversation %s (%s, %d messages)\n", shortID(id), imported.Title(), imported.MessageCount())
+		},
+	}
+
+	del := &cobra.Command{
+		Use:   "delete <id|index>",
+		Short: "Delete a saved conversation",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			hist := app.loadHistoryOrExit()
+			conv, err := hist.Resolve(args[0])
+			if err != nil {
+				display.ShowError(err.Error())
+				os.Exit(1)
+			}
+			id, title := conv.ID, conv.Title()
+			hist.DeleteConversation(id)
+			if err := hist.Save(); err != nil {
+				display.ShowError(err.Error())
+				os.Exit(1)
+			}
+			fmt.Printf("Deleted conversation %s (%s)\n", shortID(id), title)
+		},
+	}
+
+	cmd.AddCommand(list, show, search, app.newHistoryExportCmd(), imp, del)
+	return cmd
+}
+
+// openHistory creates the conversation history with the configured
+// retention and loads it
+func (app *App) openHistory() (*history.History, error) {
+	retention := history.Retention{
+		MaxCount: app.cfg.HistoryMaxConversations,
+		MaxAge:   app.cfg.HistoryMaxAge,
+	}
+	if retention.MaxCount <= 0 {
+		// Validate() didn't get as far as the history settings
+		retention.MaxCount = history.DefaultMaxConversations
+	}
+	hist := history.NewHistory()
+	hist.SetRetention(retention)
+	return hist, hist.Load()
+}
+
+// loadHistoryOrExit loads the conversation history, exiting on failure
+func (app *App) loadHistoryOrExit() *history.History {
+	app.setupLogging()
+	if err := app.cfg.Validate(); err != nil {
+		log.Printf("Config validation warning: %v", err)
+	}
+	hist, err := app.openHistory()
+	if err != nil {
+		display.ShowError(err.Error())
+		os.Exit(1)
+	}
+	return hist
+}
+
+// readImportFile reads the file to import; "-" reads stdin
+func readImportFile(path string) ([]byte, error) {
+	if path == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(path)
+}
+
+// formatConversation formats a conversation as one list line
+func formatConversation(index int, conv history.ConversationEntry) string {
+	return fmt.Sprintf("  %2d. [%s] %s  %s (%d messages, %s)",
+		index,
+		conv.UpdatedAt.Format("2006-01-02 15:04"),
+		shortID(conv.ID),
+		conv.Title(),
+		conv.MessageCount(),
+		conv.Model,
+	)
+}
+
+// shortID returns the prefix of id shown in lists
+func shortID(id string) string {
+	if len(id) > shortIDLength {
+		return id[:shortIDLength]
+	}
+	return id
+}
+
+// showConversation prints the user, assistant and tool messages of conv
+func (app *App) showConversation(conv history.ConversationEntry) {
+	for _, msg := range conv.Messages {
+		switch {
+		case msg.Role == "user":
+			fmt.Printf("👤 You:\n%s\n\n", msg.Content)
+		case msg.Role == "assistant" && msg.Content != "":
+			fmt.Printf("🤖 Assistant:\n")
+			if app.cfg.Render {
+				display.ShowContentRendered(msg.Content)
+			} else {
+				display.ShowContent(msg.Content)
+			}
+			fmt.Println()
+		case msg.Role == "tool" && msg.Content != "":
+			fmt.Printf("🔧 Tool Result:\n%s\n\n", msg.Content)
+		}
+	}
+}
+
+// pickConversation lets the user choose from conversations (most recent
+// first): a number picks from the list shown, Enter picks the first, q
+// cancels, and any other text narrows the list with a fuzzy match on the
+// title and first messages. Returns nil if cancelled.
+func pickConversation(conversations []history.ConversationEntry, in *bufio.Reader, out io.Writer) *history.ConversationEntry {
+	shown := conversations[:min(pickerSize, len(conversations))]
+	for {
+		for i, conv := range shown {
+			fmt.Fprintln(out, formatConversation(i+1, conv))
+		}
+		fmt.Fprint(out, "\nResume which? [number, Enter for 1, text to filter, q to cancel]: ")
+
+		line, err := in.ReadString('\n')
+		if err != nil && line == "" {
+			return nil
+		}
+		answer := strings.TrimSpace(line)
+		switch {
+		case answer == "":
+			return &shown[0]
+		case strings.EqualFold(answer, "q"):
+			return nil
+		}
+		if n, err := strconv.Atoi(answer); err == nil {
+			if n >= 1 && n <= len(shown) {
+				return &shown[n-1]
+			}
+			fmt.Fprintf(out, "Pick a number from 1 to %d.\n\n", len(shown))
+			continue
+		}
+
+		matches := fuzzyFilter(conversations, answer)
+		if len(matches) == 0 {
+			fmt.Fprintf(out, "No conversations match %q.\n\n", answer)
+			continue
+		}
+		fmt.Fprintln(out)
+		shown = matches[:min(pickerSize, len(matches))]
+	}
+}
+
+// fuzzyFilter returns the conversations matching query, best matches first
+func fuzzyFilter(conversations []history.ConversationEntry, query string) []history.ConversationEntry {
+	type scored struct {
+		conv  history.ConversationEntry
+		score int
+	}
+	var matches []scored
+	for _, conv := range conversations {
+		if score, ok := fuzzyScore(query, conversationText(conv)); ok {
+			matches = append(matches, scored{conv, score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].score > matches[j].score })
+
+	filtered := make([]history.ConversationEntry, len(matches))
+	for i, m := range matches {
+		filtered[i] = m.conv
+	}
+	return filtered
+}
+
+// conversationText is what the picker matches against: the title and the
+// start of the first user and assistant messages
+func conversationText(conv history.ConversationEntry) string {
+	parts := []string{conv.ID, conv.Title()}
+	seen := make(map[string]bool)
+	for _, m := range conv.Messages {
+		if (m.Role != "user" && m.Role != "assistant") || seen[m.Role] || m.Content == "" {
+			continue
+		}
+		seen[m.Role] = true
+		content := m.Content
+		if utf8.RuneCountInString(content) > fuzzyTextChars {
+			content = string([]rune(content)[:fuzzyTextChars])
+		}
+		parts = append(parts, content)
+	}
+	return strings.Join(parts, " ")
+}
+
+// fuzzyScore matches each word of query against text, as a substring or
+// failing that as a subsequence of its characters. Substring and
+// consecutive-character matches score higher.
+func fuzzyScore(query, text string) (int, bool) {
+	text = strings.ToLower(text)
+	total := 0
+	for _, term := range strings.Fields(strings.ToLower(query)) {
+		if i := strings.Index(text, term); i >= 0 {
+			total += 100 + 10*len(term) - min(i, 50)
+			continue
+		}
+		score, ok := subsequenceScore(term, text)
+		if !ok {
+			return 0, false
+		}
+		total += score
+	}
+	return total, true
+}
+
+// subsequenceScore reports whether the characters of term appear in order
+// in text, scoring runs of consecutive characters higher
+func subsequenceScore(term, text string) (int, bool) {
+	runes := []rune(text)
+	score, pos, last := 0, 0, -2
+	for _, r := range term {
+		for pos < len(runes) && runes[pos] != r {
+			pos++
+		}
+		if pos == len(runes) {
+			return 0, false
+		}
+		if pos == last+1 {
+			score += 5
+		} else {
+			score++
+		}
+		last = pos
+		pos++
+	}
+	return score, true
+}
+
+// resumeSuggestions offers the recent conversations for /resume, filtered
+// by what has been typed
+func (s *InteractiveSession) resumeSuggestions(typed string) []prompt.Suggest {
+	if s.history == nil {
+		return nil
+	}
+	var suggestions []prompt.Suggest
+	for _, conv := range s.history.Newest(pickerSize) {
+		if typed != "" {
+			if _, ok := fuzzyScore(typed, conversationText(conv)); !ok {
+				continue
+			}
+		}
+		suggestions = append(suggestions, prompt.Suggest{
+			Text:        shortID(conv.ID),
+			Description: conv.UpdatedAt.Format("01-02 15:04") + " " + conv.Title(),
+		})
+	}
+	return suggestions
+}
@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/quocvuong92/ai-cli/internal/api"
+	"github.com/quocvuong92/ai-cli/internal/executor"
+	"github.com/quocvuong92/ai-cli/internal/history"
+)
+
+func pickerConversations() []history.ConversationEntry {
+	conv := func(id, question string) history.ConversationEntry {
+		return history.ConversationEntry{ID: id, Messages: []api.Message{{Role: "user", Content: question}}}
+	}
+	return []history.ConversationEntry{
+		conv("c3", "Fix the flaky integration test"),
+		conv("c2", "Explain Kubernetes pod disruption budgets"),
+		conv("c1", "Write a haiku about autumn"),
+	}
+}
+
+func TestPickConversation(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string // "" for cancelled
+	}{
+		{"enter picks the most recent", "\n", "c3"},
+		{"number", "2\n", "c2"},
+		{"out of range then number", "7\n3\n", "c1"},
+		{"filter then pick", "kube\n1\n", "c2"},
+		{"fuzzy filter then enter", "hku\n\n", "c1"},
+		{"no match then cancel", "zebra\nq\n", ""},
+		{"end of input", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := pickConversation(pickerConversations(), bufio.NewReader(strings.NewReader(tt.input)), io.Discard)
+			switch {
+			case tt.want == "" && got != nil:
+				t.Errorf("pickConversation() = %s, want cancelled", got.ID)
+			case tt.want != "" && (got == nil || got.ID != tt.want):
+				t.Errorf("pickConversation() = %+v, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFuzzyScore(t *testing.T) {
+	text := "Explain Kubernetes pod disruption budgets"
+	if _, ok := fuzzyScore("kbrnts", text); !ok {
+		t.Error("fuzzyScore() should match a subsequence")
+	}
+	if _, ok := fuzzyScore("pod zebra", text); ok {
+		t.Error("fuzzyScore() should require every word to match")
+	}
+	substring, _ := fuzzyScore("budget", text)
+	scattered, _ := fuzzyScore("bdgt", text)
+	if substring <= scattered {
+		t.Errorf("substring score %d should beat scattered score %d", substring, scattered)
+	}
+}
+
+func TestInteractiveSession_SavesHistoryEachTurn(t *testing.T) {
+	dir := t.TempDir()
+	app := newTestApp()
+	s := &InteractiveSession{
+		app:          app,
+		client:       api.NewClientAdapter(&scriptedProvider{replies: []string{"Hi", "Fine"}}),
+		exec:         executor.NewExecutor(),
+		messages:     []api.Message{{Role: "system", Content: "sys"}},
+		history:      history.NewHistoryAt(dir),
+		interruptCtx: NewInterruptibleContext(),
+	}
+	s.setConversation("conv-1")
+
+	load := func() []history.ConversationEntry {
+		t.Helper()
+		hist := history.NewHistoryAt(dir)
+		if err := hist.Load(); err != nil {
+			t.Fatal(err)
+		}
+		return hist.Conversations
+	}
+
+	s.messages = append(s.messages, api.Message{Role: "user", Content: "hello"})
+	s.respond(1)
+	first := load()
+	if len(first) != 1 || len(first[0].Messages) != 3 {
+		t.Fatalf("after the first turn: %+v", first)
+	}
+
+	s.messages = append(s.messages, api.Message{Role: "user", Content: "how are you?"})
+	s.respond(3)
+	s.saveHistory() // As on exit
+	second := load()
+	if len(second) != 1 || len(second[0].Messages) != 5 {
+		t.Fatalf("after the second turn: %+v", second)
+	}
+	if !second[0].CreatedAt.Equal(first[0].CreatedAt) {
+		t.Errorf("CreatedAt changed from %v to %v", first[0].CreatedAt, second[0].CreatedAt)
+	}
+}
+
+func TestInteractiveSession_ProviderSwitchKeepsSavedConversation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{"id":"1","choices":[{"index":0,"message":{"role":"assistant","content":"From the new provider"},"finish_reason":"stop"}]}`)
+	}))
+	defer server.Close()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(home, ".cache"))
+	t.Setenv("OPENAI_BASE_URL", server.URL+"/v1")
+	t.Setenv("OPENAI_API_KEY", "test-key")
+	t.Setenv("OPENAI_MODELS", "test-model")
+
+	dir := t.TempDir()
+	app := newTestApp()
+	app.cfg.NoUsageLedger = true
+	s := &InteractiveSession{
+		app:          app,
+		client:       api.NewClientAdapter(&scriptedProvider{replies: []string{"From the old provider"}}),
+		exec:         executor.NewExecutor(),
+		messages:     []api.Message{{Role: "system", Content: "sys"}},
+		history:      history.NewHistoryAt(dir),
+		interruptCtx: NewInterruptibleContext(),
+	}
+	s.setConversation("conv-1")
+
+	s.messages = append(s.messages, api.Message{Role: "user", Content: "before"})
+	s.respond(1)
+	app.handleCommand("/provider openai", &s.messages, &s.client, s.exec, s)
+	s.messages = append(s.messages, api.Message{Role: "user", Content: "after"})
+	s.respond(1)
+
+	hist := history.NewHistoryAt(dir)
+	if err := hist.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if len(hist.Conversations) != 2 {
+		t.Fatalf("history has %d conversations, want 2: %+v", len(hist.Conversations), hist.Conversations)
+	}
+	if old := hist.GetConversation("conv-1"); old == nil || old.Messages[1].Content != "before" {
+		t.Errorf("conversation before the switch = %+v", old)
+	}
+	if latest := hist.Newest(1)[0]; latest.ID == "conv-1" || latest.Messages[2].Content != "From the new provider" {
+		t.Errorf("conversation after the switch = %+v", latest)
+	}
+}
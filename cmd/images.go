@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/quocvuong92/ai-cli/internal/api"
+	"github.com/quocvuong92/ai-cli/internal/display"
+)
+
+// attachedImage is an image waiting to be sent with the next message
+type attachedImage struct {
+	path string
+	part api.ContentPart
+}
+
+// warnIfNoVision warns when the current model isn't known to accept images
+func (app *App) warnIfNoVision() {
+	if !api.Capabilities.Lookup(app.cfg.Model).Vision {
+		display.ShowWarning(fmt.Sprintf("%s may not support images; the request may fail", app.cfg.Model))
+	}
+}
+
+// handleImageCommand processes /image: "/image <path>" attaches an image to
+// the next message, "/image clear" drops pending images and "/image" alone
+// lists them.
+func (app *App) handleImageCommand(parts []string, session *InteractiveSession) {
+	if session == nil {
+		fmt.Println("Images are not available.")
+		return
+	}
+
+	arg := ""
+	if len(parts) > 1 {
+		arg = strings.TrimSpace(parts[1])
+	}
+
+	switch arg {
+	case "":
+		if len(session.pendingImages) == 0 {
+			fmt.Println("No images attached. Usage: /image <path>")
+			return
+		}
+		fmt.Println("Attached to the next message:")
+		for _, img := range session.pendingImages {
+			fmt.Printf("  %s\n", img.path)
+		}
+	case "clear":
+		session.pendingImages = nil
+		fmt.Println("Attached images cleared.")
+	default:
+		part, err := api.LoadImage(arg)
+		if err != nil {
+			display.ShowError(err.Error())
+			return
+		}
+		session.pendingImages = append(session.pendingImages, attachedImage{path: arg, part: part})
+		fmt.Printf("Attached %s; it will be sent with your next message.\n", filepath.Base(arg))
+		app.warnIfNoVision()
+	}
+}
+
+// imageParts returns the content parts of images
+func imageParts(images []attachedImage) []api.ContentPart {
+	parts := make([]api.ContentPart, len(images))
+	for i, img := range images {
+		parts[i] = img.part
+	}
+	return parts
+}
@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/quocvuong92/ai-cli/internal/executor"
+)
+
+// stdinPath is the -f value that means "read standard input"
+const stdinPath = "-"
+
+// inputBlock is a piece of attached context (a file or piped stdin)
+type inputBlock struct {
+	name      string
+	content   string
+	truncated bool
+}
+
+// stdinIsPiped reports whether stdin is a pipe or redirected file rather than
+// a terminal, so one-shot mode can read it without blocking on a TTY
+func stdinIsPiped() bool {
+	info, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+	mode := info.Mode()
+	return mode&os.ModeNamedPipe != 0 || mode.IsRegular()
+}
+
+// readInput reads at most executor.MaxFileSize bytes from r.
+// Binary content is rejected since it can't be sent as a prompt.
+func readInput(r io.Reader, name string) (inputBlock, error) {
+	data, err := io.ReadAll(io.LimitReader(r, executor.MaxFileSize+1))
+	if err != nil {
+		return inputBlock{}, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+
+	block := inputBlock{name: name}
+	if len(data) > executor.MaxFileSize {
+		data = data[:executor.MaxFileSize]
+		block.truncated = true
+	}
+	if bytes.IndexByte(data, 0) >= 0 {
+		return inputBlock{}, fmt.Errorf("%s appears to be a binary file", name)
+	}
+	block.content = string(data)
+	return block, nil
+}
+
+// collectInputs reads each -f path ("-" for stdin). Piped stdin is read on
+// its own only when there are no -f paths; with them it takes "-", so a
+// script's inherited stdin isn't attached (or waited on) by accident.
+func collectInputs(paths []string, stdin io.Reader, stdinPiped bool) ([]inputBlock, error) {
+	var blocks []inputBlock
+	readStdin := false
+
+	for _, path := range paths {
+		if path == stdinPath {
+			if readStdin {
+				continue
+			}
+			readStdin = true
+			block, err := readInput(stdin, "stdin")
+			if err != nil {
+				return nil, err
+			}
+			blocks = append(blocks, block)
+			continue
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil, fmt.Errorf("file not found: %s", path)
+			}
+			return nil, err
+		}
+		if info.IsDir() {
+			return nil, fmt.Errorf("%s is a directory", path)
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		block, err := readInput(f, path)
+		_ = f.Close()
+		if err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, block)
+	}
+
+	if stdinPiped && len(paths) == 0 {
+		block, err := readInput(stdin, "stdin")
+		if err != nil {
+			return nil, err
+		}
+		if strings.TrimSpace(block.content) != "" {
+			blocks = append(blocks, block)
+		}
+	}
+
+	return blocks, nil
+}
+
+// buildUserMessage appends each input block to the query as a fenced context block
+func buildUserMessage(query string, blocks []inputBlock) string {
+	var b strings.Builder
+	b.WriteString(query)
+
+	for _, block := range blocks {
+		if b.Len() > 0 {
+			b.WriteString("\n\n")
+		}
+		fence := codeFence(block.content)
+		if block.name == "stdin" {
+			b.WriteString("Input from stdin:\n")
+		} else {
+			fmt.Fprintf(&b, "File: %s\n", block.name)
+		}
+		b.WriteString(fence)
+		b.WriteString("\n")
+		b.WriteString(strings.TrimRight(block.content, "\n"))
+		b.WriteString("\n")
+		b.WriteString(fence)
+		if block.truncated {
+			fmt.Fprintf(&b, "\n[Truncated: %s exceeds %dKB, showing the beginning]", block.name, executor.MaxFileSize/1024)
+		}
+	}
+
+	return b.String()
+}
+
+// codeFence returns a backtick fence longer than any backtick run in content,
+// so attached Markdown or diffs can't close the block early
+func codeFence(content string) string {
+	longest, run := 0, 0
+	for _, r := range content {
+		if r == '`' {
+			run++
+			if run > longest {
+				longest = run
+			}
+		} else {
+			run = 0
+		}
+	}
+	if longest < 3 {
+		return "```"
+	}
+	return strings.Repeat("`", longest+1)
+}
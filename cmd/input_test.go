@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/quocvuong92/ai-cli/internal/executor"
+)
+
+func TestCollectInputs(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(path, []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		paths      []string
+		stdin      string
+		stdinPiped bool
+		wantNames  []string
+	}{
+		{"file only", []string{path}, "", false, []string{path}},
+		{"piped stdin without files", nil, "diff", true, []string{"stdin"}},
+		{"piped stdin ignored with files", []string{path}, "diff", true, []string{path}},
+		{"piped stdin with files and dash", []string{path, "-"}, "diff", true, []string{path, "stdin"}},
+		{"dash reads stdin once", []string{"-", path, "-"}, "diff", true, []string{"stdin", path}},
+		{"empty piped stdin ignored", nil, "  \n", true, nil},
+		{"terminal stdin not read", nil, "ignored", false, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blocks, err := collectInputs(tt.paths, strings.NewReader(tt.stdin), tt.stdinPiped)
+			if err != nil {
+				t.Fatalf("collectInputs() error = %v", err)
+			}
+			if len(blocks) != len(tt.wantNames) {
+				t.Fatalf("got %d blocks, want %d", len(blocks), len(tt.wantNames))
+			}
+			for i, want := range tt.wantNames {
+				if blocks[i].name != want {
+					t.Errorf("block[%d].name = %q, want %q", i, blocks[i].name, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCollectInputs_Errors(t *testing.T) {
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "app.bin")
+	if err := os.WriteFile(binary, []byte{0x7f, 'E', 'L', 'F', 0}, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{filepath.Join(dir, "missing.txt"), dir, binary} {
+		if _, err := collectInputs([]string{path}, strings.NewReader(""), false); err == nil {
+			t.Errorf("collectInputs(%q) should fail", path)
+		}
+	}
+}
+
+func TestReadInput_Truncates(t *testing.T) {
+	block, err := readInput(strings.NewReader(strings.Repeat("x", executor.MaxFileSize+10)), "stdin")
+	if err != nil {
+		t.Fatalf("readInput() error = %v", err)
+	}
+	if !block.truncated || len(block.content) != executor.MaxFileSize {
+		t.Errorf("truncated = %v, len = %d; want truncation to MaxFileSize", block.truncated, len(block.content))
+	}
+	if msg := buildUserMessage("q", []inputBlock{block}); !strings.Contains(msg, "[Truncated: stdin exceeds 512KB") {
+		t.Error("buildUserMessage() should note the truncation")
+	}
+}
+
+func TestBuildUserMessage(t *testing.T) {
+	got := buildUserMessage("review this", []inputBlock{
+		{name: "stdin", content: "+added line\n"},
+		{name: "README.md", content: "```go\nx := 1\n```\n"},
+	})
+	want := "review this\n\n" +
+		"Input from stdin:\n```\n+added line\n```\n\n" +
+		"File: README.md\n````\n```go\nx := 1\n```\n````"
+	if got != want {
+		t.Errorf("buildUserMessage() =\n%s\nwant\n%s", got, want)
+	}
+
+	// Piped input alone is a valid prompt
+	if got := buildUserMessage("", []inputBlock{{name: "stdin", content: "hi"}}); got != "Input from stdin:\n```\nhi\n```" {
+		t.Errorf("buildUserMessage() with no query = %q", got)
+	}
+}
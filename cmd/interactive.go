@@ -4,6 +4,8 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"log"
+	"maps"
 	"os"
 	"os/signal"
 	"strings"
@@ -32,8 +34,11 @@ type InteractiveSession struct {
 	inputBuffer    []string // Buffer for multiline input
 	history        *history.History
 	conversationID string
-	interruptCtx   *InterruptibleContext // For graceful Ctrl+C cancellation
-	currentPlan    *display.Plan         // Current task plan/checklist
+	interruptCtx   *InterruptibleContext       // For graceful Ctrl+C cancellation
+	currentPlan    *display.Plan               // Current task plan/checklist
+	pendingImages  []attachedImage             // Images to send with the next message (/image)
+	interrupted    bool                        // The last reply was cut off mid-stream (/continue)
+	sources        map[string][]history.Source // Web search sources cited by replies, by history.ReplyKey
 }
 
 // InterruptibleContext manages a cancellable context for operations.
@@ -113,11 +118,7 @@ func (s *InteractiveSession) completer(d prompt.Document) ([]prompt.Suggest, ist
 	if strings.HasPrefix(textLower, "/model ") {
 		var suggestions []prompt.Suggest
 		for _, model := range s.app.cfg.AvailableModels {
-			desc := ""
-			if model == s.app.cfg.Model {
-				desc = "(current)"
-			}
-			suggestions = append(suggestions, prompt.Suggest{Text: model, Description: desc})
+			suggestions = append(suggestions, prompt.Suggest{Text: model, Description: s.app.modelDescription(model)})
 		}
 		return prompt.FilterHasPrefix(suggestions, w, true), startIndex, endIndex
 	}
@@ -127,6 +128,26 @@ func (s *InteractiveSession) completer(d prompt.Document) ([]prompt.Suggest, ist
 		suggestions := []prompt.Suggest{
 			{Text: "copilot", Description: "GitHub Copilot (free with Pro)"},
 			{Text: "azure", Description: "Azure OpenAI"},
+			{Text: "openai", Description: "OpenAI-compatible server"},
+			{Text: "anthropic", Description: "Anthropic Messages API"},
+		}
+		return prompt.FilterHasPrefix(suggestions, w, true), startIndex, endIndex
+	}
+
+	// /resume <conversation> - suggest recent conversations
+	if strings.HasPrefix(textLower, "/resume ") {
+		return s.resumeSuggestions(w), startIndex, endIndex
+	}
+
+	// /set <param> - suggest sampling parameters
+	if strings.HasPrefix(textLower, "/set ") && !strings.Contains(strings.TrimPrefix(textLower, "/set "), " ") {
+		suggestions := []prompt.Suggest{
+			{Text: "temperature", Description: "Sampling temperature (0-2)"},
+			{Text: "top_p", Description: "Nucleus sampling probability mass (0-1)"},
+			{Text: "max_tokens", Description: "Maximum tokens to generate"},
+			{Text: "seed", Description: "Seed for deterministic sampling"},
+			{Text: "stop", Description: "Set the stop sequence (+<sequence> adds one)"},
+			{Text: "reasoning_effort", Description: "Reasoning effort: low, medium, high"},
 		}
 		return prompt.FilterHasPrefix(suggestions, w, true), startIndex, endIndex
 	}
@@ -148,6 +169,10 @@ func (s *InteractiveSession) completer(d prompt.Document) ([]prompt.Suggest, ist
 		// Most used commands first
 		{Text: "/model", Description: "Show/switch model (current: " + s.app.cfg.Model + ")"},
 		{Text: "/clear", Description: "Clear conversation history"},
+		{Text: "/compact", Description: "Summarize older turns to free context (optional instructions)"},
+		{Text: "/image", Description: "Attach an image to the next message (/image clear to drop)"},
+		{Text: "/retry", Description: "Send the last message again"},
+		{Text: "/continue", Description: "Continue an interrupted response"},
 		{Text: "/web", Description: "Web search commands"},
 		{Text: "/help", Description: "Show all available commands"},
 		{Text: "/exit", Description: "Exit interactive mode"},
@@ -160,10 +185,12 @@ func (s *InteractiveSession) completer(d prompt.Document) ([]prompt.Suggest, ist
 
 		// History commands
 		{Text: "/history", Description: "Show recent conversations"},
-		{Text: "/resume", Description: "Resume last conversation"},
+		{Text: "/resume", Description: "Resume a conversation (number, ID, or pick from a list)"},
+		{Text: "/export", Description: "Export the conversation (md, html, json; --redact)"},
 
 		// Provider
 		{Text: "/provider", Description: "Show/switch provider (current: " + s.app.getProviderName() + ")"},
+		{Text: "/set", Description: "Show/set sampling parameters (e.g., /set temperature 0.2)"},
 
 		// Permission commands
 		{Text: "/show-permissions", Description: "Show command execution permissions"},
@@ -187,7 +214,8 @@ func (s *InteractiveSession) completer(d prompt.Document) ([]prompt.Suggest, ist
 // with backslash continuation and various slash commands.
 func (app *App) runInteractive() {
 	// Create AI client
-	client, err := api.NewClient(app.cfg)
+	app.usageCommand = usageCommandInteractive
+	client, err := app.newClient()
 	if err != nil {
 		display.ShowError(err.Error())
 		return
@@ -195,6 +223,8 @@ func (app *App) runInteractive() {
 	// Ensure client resources are cleaned up on exit
 	defer client.Close()
 
+	app.loadModels(client, app.refreshModels)
+
 	fmt.Println("AI CLI - Interactive Mode")
 	fmt.Printf("Model: %s\n", app.cfg.Model)
 	fmt.Printf("Provider: %s\n", app.getProviderName())
@@ -207,8 +237,8 @@ func (app *App) runInteractive() {
 	fmt.Println()
 
 	// Initialize history
-	hist := history.NewHistory()
-	if err := hist.Load(); err != nil {
+	hist, err := app.openHistory()
+	if err != nil {
 		// History load failed, continue without it
 		fmt.Fprintf(os.Stderr, "Note: Could not load history: %v\n", err)
 	}
@@ -220,11 +250,11 @@ func (app *App) runInteractive() {
 		messages: []api.Message{
 			{Role: "system", Content: config.DefaultSystemMessage},
 		},
-		exitFlag:       false,
-		history:        hist,
-		conversationID: uuid.New().String(),
-		interruptCtx:   NewInterruptibleContext(),
+		exitFlag:     false,
+		history:      hist,
+		interruptCtx: NewInterruptibleContext(),
 	}
+	session.setConversation(uuid.New().String())
 
 	p := prompt.New(
 		session.executor,
@@ -274,23 +304,27 @@ func (app *App) runInteractive() {
 	p.Run()
 }
 
-// saveHistory persists the current conversation to the history file.
-// Only saves if there are messages beyond the initial system prompt.
+// setConversation switches to conversation id, which usage is recorded under
+func (s *InteractiveSession) setConversation(id string) {
+	s.conversationID = id
+	s.app.conversationID = id
+	s.sources = nil
+}
+
+// saveHistory writes the current conversation to history, updating its
+// entry if it was saved before. It runs after every assistant turn, so an
+// unexpected exit loses at most the turn in progress. Nothing is saved until
+// there are messages beyond the initial system prompt.
 func (s *InteractiveSession) saveHistory() {
-	if s.history == nil {
+	if s.history == nil || len(s.messages) <= 1 {
 		return
 	}
-	// Only save if there are messages beyond the system prompt
-	if len(s.messages) > 1 {
-		s.history.AddConversation(
-			s.conversationID,
-			s.app.cfg.Model,
-			s.app.getProviderName(),
-			s.messages,
-		)
-		if err := s.history.Save(); err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: Could not save history: %v\n", err)
-		}
+	s.history.UpsertConversation(s.conversationID, s.app.cfg.Model, s.app.getProviderName(), s.messages)
+	if conv := s.history.GetConversation(s.conversationID); conv != nil {
+		conv.Sources = maps.Clone(s.sources)
+	}
+	if err := s.history.Save(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Could not save history: %v\n", err)
 	}
 }
 
@@ -339,48 +373,65 @@ func (s *InteractiveSession) executor(input string) {
 	}
 
 	// Regular chat with tool support
-	s.messages = append(s.messages, api.Message{Role: "user", Content: input})
+	s.messages = append(s.messages, api.NewUserMessage(input, imageParts(s.pendingImages)))
 	fmt.Println()
-	response, err := s.app.sendInteractiveMessageWithTools(s.client, s.exec, &s.messages, s.interruptCtx, s)
-	if err != nil {
-		// Check if it was a cancellation
-		if err == context.Canceled {
-			// Remove the user message since we didn't complete
-			s.messages = s.messages[:len(s.messages)-1]
-			return
-		}
-		display.ShowError(err.Error())
-		s.messages = s.messages[:len(s.messages)-1]
-		return
-	}
-	if response != "" {
-		s.messages = append(s.messages, api.Message{Role: "assistant", Content: response})
+	if s.respond(len(s.messages) - 1) {
+		s.pendingImages = nil
 	}
-	fmt.Println()
 }
 
 // getProviderName returns a human-readable provider name.
 // It checks explicit provider setting first, then auto-detects based on
-// available credentials (GitHub Copilot login or Azure environment variables).
+// available credentials (GitHub Copilot login, Azure or OpenAI-compatible settings).
 func (app *App) getProviderName() string {
-	// Check explicit provider setting first
-	switch app.cfg.Provider {
-	case "copilot", "github":
+	// A fallback chain takes precedence over the single provider setting
+	if len(app.cfg.FallbackChain) > 0 {
+		names := make([]string, len(app.cfg.FallbackChain))
+		for i, target := range app.cfg.FallbackChain {
+			names[i] = target.String()
+		}
+		return "Fallback (" + strings.Join(names, " → ") + ")"
+	}
+
+	switch app.providerID() {
+	case "copilot":
 		return "GitHub Copilot"
 	case "azure":
 		return "Azure OpenAI"
+	case "openai":
+		return "OpenAI Compatible"
+	case "anthropic":
+		return "Anthropic"
+	default:
+		return "Unknown"
+	}
+}
+
+// providerID returns the provider NewClient selects for a single-provider
+// setup: "copilot", "azure", "openai", "anthropic", or "" if none is available.
+func (app *App) providerID() string {
+	// Check explicit provider setting first
+	switch app.cfg.Provider {
+	case "copilot", "github":
+		return "copilot"
+	case "azure", "openai", "anthropic":
+		return app.cfg.Provider
 	}
 
 	// Auto-detect based on what NewClient will choose
 	if auth.IsLoggedIn() {
-		return "GitHub Copilot"
+		return "copilot"
 	}
 
 	if app.cfg.AzureEndpoint != "" && app.cfg.AzureAPIKey != "" {
-		return "Azure OpenAI"
+		return "azure"
+	}
+
+	if app.cfg.OpenAIBaseURL != "" {
+		return "openai"
 	}
 
-	return "Unknown"
+	return ""
 }
 
 // sendInteractiveMessage sends a message to the AI and displays the response.
@@ -388,13 +439,19 @@ func (app *App) getProviderName() string {
 func (app *App) sendInteractiveMessage(client api.AIClient, messages []api.Message) (string, error) {
 	if app.cfg.Stream {
 		var fullContent strings.Builder
+		var reasoning reasoningDisplay
 		firstChunk := true
 
 		sp := display.NewSpinner("Thinking...")
 		sp.Start()
 
-		err := client.QueryStreamWithHistory(messages,
-			func(content string) {
+		err := client.Stream(context.Background(), app.newRequest(messages, nil), api.StreamHandler{
+			OnReasoning: func(content string) {
+				sp.Stop()
+				reasoning.chunk(content)
+			},
+			OnChunk: func(content string) {
+				reasoning.end()
 				if firstChunk {
 					firstChunk = false
 					if app.cfg.Render {
@@ -409,10 +466,10 @@ func (app *App) sendInteractiveMessage(client api.AIClient, messages []api.Messa
 					fmt.Print(content)
 				}
 			},
-			nil,
-		)
+		})
 
 		sp.Stop()
+		reasoning.end()
 
 		if err != nil {
 			return "", err
@@ -430,13 +487,14 @@ func (app *App) sendInteractiveMessage(client api.AIClient, messages []api.Messa
 	sp := display.NewSpinner("Thinking...")
 	sp.Start()
 
-	resp, err := client.QueryWithHistory(messages)
+	resp, err := client.Complete(context.Background(), app.newRequest(messages, nil))
 	sp.Stop()
 
 	if err != nil {
 		return "", err
 	}
 
+	display.ShowReasoning(resp.GetReasoning())
 	content := resp.GetContent()
 	if app.cfg.Render {
 		display.ShowContentRendered(content)
@@ -456,23 +514,53 @@ func (app *App) sendInteractiveMessageWithTools(client api.AIClient, exec *execu
 	ctx := interruptCtx.Start()
 	defer interruptCtx.Stop()
 
-	tools := api.GetDefaultTools()
+	return app.runToolLoop(ctx, client, exec, messages, session, app.cfg.MaxToolIterations)
+}
+
+// runToolLoop calls the API with the default tools, runs any requested tool
+// calls and feeds their results back until the model answers without tools.
+// A positive maxIterations limits the number of API calls; exceeding it
+// returns errMaxIterations. Every call is checked against the usage limits
+// first, returning errLimitReached once one is used up.
+func (app *App) runToolLoop(ctx context.Context, client api.AIClient, exec *executor.Executor, messages *[]api.Message, session *InteractiveSession, maxIterations int) (string, error) {
+	// Models without tool support reject requests that carry tools
+	var tools []api.Tool
+	if api.Capabilities.Lookup(app.cfg.Model).ToolCalls {
+		tools = api.GetDefaultTools()
+	} else {
+		log.Printf("Model %s does not support tools; sending without them", app.cfg.Model)
+	}
 
 	// Keep calling the API until there are no more tool calls
-	for {
+	for iteration := 1; ; iteration++ {
+		if maxIterations > 0 && iteration > maxIterations {
+			return "", fmt.Errorf("%w (%d)", errMaxIterations, maxIterations)
+		}
+		if err := app.checkLimits(); err != nil {
+			return "", err
+		}
+
+		app.fitContext(ctx, client, messages, tools)
+
 		var resp *api.ChatResponse
 		var err error
 
 		if app.cfg.Stream {
 			// Streaming mode
 			var fullContent strings.Builder
+			var reasoning reasoningDisplay
 			firstChunk := true
 
 			sp := display.NewSpinner("Thinking...")
 			sp.Start()
 
-			err = client.QueryStreamWithHistoryAndToolsContext(ctx, *messages, tools,
-				func(content string) {
+			err = client.Stream(ctx, app.newRequest(*messages, tools), api.StreamHandler{
+				OnReasoning: func(content string) {
+					sp.Stop()
+					reasoning.chunk(content)
+				},
+				OnChunk: func(content string) {
+					reasoning.end()
 					if firstChunk {
 						firstChunk = false
 						if app.cfg.Render {
@@ -487,14 +575,21 @@ func (app *App) sendInteractiveMessageWithTools(client api.AIClient, exec *execu
 						fmt.Print(content)
 					}
 				},
-				func(finalResp *api.ChatResponse) {
+				OnDone: func(finalResp *api.ChatResponse) {
 					resp = finalResp
 				},
-			)
+			})
 
 			sp.Stop()
+			reasoning.end()
 
 			if err != nil {
+				// Show what arrived before the stream failed
+				if app.cfg.Render && fullContent.Len() > 0 {
+					display.ShowContentRendered(fullContent.String())
+				} else if !app.cfg.Render && !firstChunk {
+					fmt.Println()
+				}
 				return "", err
 			}
 
@@ -512,12 +607,13 @@ func (app *App) sendInteractiveMessageWithTools(client api.AIClient, exec *execu
 			sp := display.NewSpinner("Thinking...")
 			sp.Start()
 
-			resp, err = client.QueryWithHistoryAndToolsContext(ctx, *messages, tools)
+			resp, err = client.Complete(ctx, app.newRequest(*messages, tools))
 			sp.Stop()
 
 			if err != nil {
 				return "", err
 			}
+			display.ShowReasoning(resp.GetReasoning())
 		}
 
 		// Check if there are tool calls
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/quocvuong92/ai-cli/internal/api"
+)
+
+// errLimitReached is returned before a request that a configured usage
+// limit does not allow
+var errLimitReached = errors.New("usage limit reached")
+
+// budget tracks the usage that limits.max_tokens_per_session and
+// limits.max_requests_per_day are checked against
+type budget struct {
+	mu            sync.Mutex
+	sessionTokens int
+	day           string // Local date dayRequests counts, "" until loaded
+	dayRequests   int
+}
+
+// checkLimits returns errLimitReached if the session token or daily request
+// limit has been used up
+func (app *App) checkLimits() error {
+	b := &app.budget
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if max := app.cfg.MaxTokensPerSession; max > 0 && b.sessionTokens >= max {
+		return fmt.Errorf("%w: %d tokens used this session (limits.max_tokens_per_session is %d)", errLimitReached, b.sessionTokens, max)
+	}
+	if max := app.cfg.MaxRequestsPerDay; max > 0 {
+		app.syncDay(time.Now())
+		if b.dayRequests >= max {
+			return fmt.Errorf("%w: %d requests today (limits.max_requests_per_day is %d)", errLimitReached, b.dayRequests, max)
+		}
+	}
+	return nil
+}
+
+// trackUsage counts a completed response against the limits
+func (app *App) trackUsage(resp *api.ChatResponse) {
+	b := &app.budget
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.sessionTokens += resp.Usage.TotalTokens
+	if app.cfg.MaxRequestsPerDay > 0 {
+		app.syncDay(time.Now())
+		b.dayRequests++
+	}
+}
+
+// syncDay starts the daily request count for now's date, seeded from the
+// usage ledger so other runs today count too. The caller holds the budget
+// lock, and must call it before this run's requests reach the ledger.
+func (app *App) syncDay(now time.Time) {
+	b := &app.budget
+	today := now.Format(time.DateOnly)
+	if b.day == today {
+		return
+	}
+	b.day, b.dayRequests = today, 0
+	if app.cfg.NoUsageLedger || app.usageLedger == nil {
+		return
+	}
+
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	entries, err := app.usageLedger.Load(midnight)
+	if err != nil {
+		log.Printf("Failed to count today's requests: %v", err)
+		return
+	}
+	b.dayRequests = len(entries)
+}
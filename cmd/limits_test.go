@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/quocvuong92/ai-cli/internal/api"
+	"github.com/quocvuong92/ai-cli/internal/executor"
+	"github.com/quocvuong92/ai-cli/internal/usage"
+)
+
+func TestRunToolLoop_Limits(t *testing.T) {
+	loopingProvider := func() *toolCallingProvider {
+		calls := make([]api.ToolCall, 10)
+		for i := range calls {
+			calls[i] = makeToolCall("noop", nil)
+		}
+		return &toolCallingProvider{calls: calls}
+	}
+
+	t.Run("session tokens", func(t *testing.T) {
+		provider := loopingProvider()
+		app := newTestApp()
+		app.cfg.MaxTokensPerSession = 100
+		app.budget.sessionTokens = 100
+		messages := []api.Message{{Role: "user", Content: "task"}}
+
+		_, err := app.runToolLoop(context.Background(), api.NewClientAdapter(provider), executor.NewExecutor(), &messages, &InteractiveSession{app: app}, 5)
+		if !errors.Is(err, errLimitReached) {
+			t.Fatalf("runToolLoop() error = %v, want errLimitReached", err)
+		}
+		if provider.requests != 0 {
+			t.Errorf("runToolLoop() made %d requests, want 0", provider.requests)
+		}
+	})
+
+	t.Run("requests per day", func(t *testing.T) {
+		ledger := usage.NewLedgerAt(filepath.Join(t.TempDir(), usage.LedgerFileName))
+		now := time.Now()
+		_ = ledger.Append(usage.Entry{Time: now.Add(-48 * time.Hour), Model: "test-model"})
+		_ = ledger.Append(usage.Entry{Time: now, Model: "test-model"})
+
+		provider := loopingProvider()
+		app := newTestApp()
+		app.cfg.MaxRequestsPerDay = 2
+		app.usageLedger = ledger
+		client := api.NewUsageClient(api.NewClientAdapter(provider), func(r api.Request, resp *api.ChatResponse) {
+			app.trackUsage(resp)
+		})
+		messages := []api.Message{{Role: "user", Content: "task"}}
+
+		_, err := app.runToolLoop(context.Background(), client, executor.NewExecutor(), &messages, &InteractiveSession{app: app}, 5)
+		if !errors.Is(err, errLimitReached) {
+			t.Fatalf("runToolLoop() error = %v, want errLimitReached", err)
+		}
+		// One request was recorded earlier today, so one more is allowed
+		if provider.requests != 1 {
+			t.Errorf("runToolLoop() made %d requests, want 1", provider.requests)
+		}
+	})
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/quocvuong92/ai-cli/internal/api"
+	"github.com/quocvuong92/ai-cli/internal/config"
+	"github.com/quocvuong92/ai-cli/internal/display"
+)
+
+// loadModels fetches the provider's live model list (from the disk cache
+// while fresh) and makes it the list used for validation and completion.
+// Failures are logged and leave the configured list in place.
+func (app *App) loadModels(client api.AIClient, refresh bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), config.DefaultModelDiscoveryTimeout)
+	defer cancel()
+
+	models, err := api.DiscoverModels(ctx, client, api.NewModelCache(config.DefaultModelCacheTTL), refresh)
+	if err != nil {
+		if !errors.Is(err, api.ErrModelListUnsupported) {
+			log.Printf("Model discovery failed: %v", err)
+		}
+		if len(models) == 0 {
+			return
+		}
+	}
+
+	app.models = models
+	api.Capabilities.SetLive(models)
+	app.cfg.SetLiveModels(api.ModelIDs(models))
+	log.Printf("Discovered %d models", len(models))
+}
+
+// showModels lists the available models, with capabilities when known
+// from the provider, the built-in table or the config
+func (app *App) showModels() {
+	if len(app.models) == 0 {
+		display.ShowModels(app.cfg.AvailableModels, app.cfg.Model)
+		return
+	}
+
+	details := make([]display.ModelDetails, len(app.models))
+	for i, m := range app.models {
+		details[i] = display.ModelDetails{ID: m.ID, Model: m.Model}
+		if c, known := api.Capabilities.Match(m.ID); known {
+			details[i].ContextWindow = c.ContextWindow
+			details[i].MaxOutput = c.MaxOutputTokens
+			details[i].ToolCalls = c.ToolCalls
+			details[i].Vision = c.Vision
+			details[i].Known = true
+		}
+	}
+	display.ShowModelDetails(details, app.cfg.Model)
+}
+
+// modelDescription summarizes a model's capabilities for completion
+func (app *App) modelDescription(id string) string {
+	var parts []string
+	if id == app.cfg.Model {
+		parts = append(parts, "current")
+	}
+	if c, known := api.Capabilities.Match(id); known {
+		if c.ContextWindow > 0 {
+			parts = append(parts, display.FormatTokens(c.ContextWindow)+" ctx")
+		}
+		if c.ToolCalls {
+			parts = append(parts, "tools")
+		}
+		if c.Vision {
+			parts = append(parts, "vision")
+		}
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("(%s)", strings.Join(parts, ", "))
+}
@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/quocvuong92/ai-cli/internal/api"
+	"github.com/quocvuong92/ai-cli/internal/config"
+	"github.com/quocvuong92/ai-cli/internal/display"
+)
+
+// outputResult is the --output json object, and the payload of the final
+// ndjson line
+type outputResult struct {
+	Content      string             `json:"content"`
+	Reasoning    string             `json:"reasoning,omitempty"`
+	Model        string             `json:"model"`
+	Provider     string             `json:"provider"`
+	FinishReason string             `json:"finish_reason,omitempty"`
+	Usage        *api.Usage         `json:"usage,omitempty"`
+	Citations    []display.Citation `json:"citations,omitempty"`
+	ToolCalls    []api.ToolCall     `json:"tool_calls,omitempty"`
+}
+
+// outputEvent is one --output ndjson line: a "delta" per streamed chunk (a
+// "reasoning" per reasoning chunk), then a single "done" carrying the full
+// result, or an "error"
+type outputEvent struct {
+	Type  string `json:"type"`
+	Delta string `json:"delta,omitempty"`
+	Error string `json:"error,omitempty"`
+	*outputResult
+}
+
+// machineOutput reports whether stdout is reserved for JSON
+func (app *App) machineOutput() bool {
+	return app.cfg.Output == config.OutputJSON || app.cfg.Output == config.OutputNDJSON
+}
+
+// runJSON sends the query and writes a single JSON object to stdout
+func (app *App) runJSON(client api.AIClient, systemPrompt, userMessage string) {
+	req := app.newRequest(app.promptMessages(systemPrompt, userMessage), nil)
+
+	var resp *api.ChatResponse
+	var err error
+	if app.cfg.Stream {
+		var content strings.Builder
+		err = app.streamWithContinue(context.Background(), client, req, api.StreamHandler{
+			OnChunk: func(chunk string) {
+				content.WriteString(chunk)
+			},
+			OnDone: func(r *api.ChatResponse) {
+				resp = r
+			},
+		})
+		if err == nil && resp == nil {
+			resp = contentResponse(content.String())
+		}
+	} else {
+		resp, err = client.Complete(context.Background(), req)
+	}
+	if err != nil {
+		app.fail(err.Error())
+	}
+
+	writeJSONLine(os.Stdout, app.newOutputResult(resp))
+}
+
+// runNDJSON streams the query, writing one JSON line per delta and a final
+// "done" line with the full result
+func (app *App) runNDJSON(client api.AIClient, systemPrompt, userMessage string) {
+	var finalResp *api.ChatResponse
+	var content strings.Builder
+
+	err := app.streamWithContinue(context.Background(), client, app.newRequest(app.promptMessages(systemPrompt, userMessage), nil), api.StreamHandler{
+		OnReasoning: func(chunk string) {
+			writeJSONLine(os.Stdout, outputEvent{Type: "reasoning", Delta: chunk})
+		},
+		OnChunk: func(chunk string) {
+			content.WriteString(chunk)
+			writeJSONLine(os.Stdout, outputEvent{Type: "delta", Delta: chunk})
+		},
+		OnDone: func(resp *api.ChatResponse) {
+			finalResp = resp
+		},
+	})
+	if err != nil {
+		app.fail(err.Error())
+	}
+
+	if finalResp == nil {
+		finalResp = contentResponse(content.String())
+	}
+	result := app.newOutputResult(finalResp)
+	writeJSONLine(os.Stdout, outputEvent{Type: "done", outputResult: &result})
+}
+
+// writeResult writes already-complete content in the configured machine format
+func (app *App) writeResult(content string) {
+	result := app.newOutputResult(contentResponse(content))
+	if app.cfg.Output == config.OutputNDJSON {
+		writeJSONLine(os.Stdout, outputEvent{Type: "done", outputResult: &result})
+		return
+	}
+	writeJSONLine(os.Stdout, result)
+}
+
+// fail reports a fatal error and exits. In json and ndjson modes the error
+// is written to stdout as JSON so consumers don't have to parse stderr.
+func (app *App) fail(message string) {
+	switch app.cfg.Output {
+	case config.OutputJSON:
+		writeJSONLine(os.Stdout, map[string]string{"error": message})
+	case config.OutputNDJSON:
+		writeJSONLine(os.Stdout, outputEvent{Type: "error", Error: message})
+	default:
+		display.ShowError(message)
+	}
+	os.Exit(1)
+}
+
+// newOutputResult builds the result object for resp, attributing it to the
+// fallback backend that answered when a chain is configured
+func (app *App) newOutputResult(resp *api.ChatResponse) outputResult {
+	result := outputResult{
+		Model:    app.cfg.Model,
+		Provider: app.providerID(),
+	}
+	if resp != nil {
+		if resp.Backend != "" {
+			result.Provider, result.Model, _ = strings.Cut(resp.Backend, ":")
+		}
+		result.Content = resp.GetContent()
+		result.Reasoning = resp.GetReasoning()
+		if resp.Usage.TotalTokens > 0 {
+			usage := resp.Usage
+			result.Usage = &usage
+		}
+		if len(resp.Choices) > 0 {
+			result.FinishReason = resp.Choices[0].FinishReason
+			result.ToolCalls = resp.Choices[0].GetToolCalls()
+		}
+	}
+
+	if app.searchResults != nil {
+		for _, r := range app.searchResults.Results {
+			result.Citations = append(result.Citations, display.Citation{Title: r.Title, URL: r.URL})
+		}
+	}
+	return result
+}
+
+// contentResponse wraps content in a response, for providers that finish a
+// stream without a final response and for already-validated structured output
+func contentResponse(content string) *api.ChatResponse {
+	return &api.ChatResponse{
+		Choices: []api.Choice{{
+			Message: api.Message{Role: "assistant", Content: content},
+		}},
+	}
+}
+
+// writeJSONLine writes v as a single line of JSON
+func writeJSONLine(w io.Writer, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		data, _ = json.Marshal(map[string]string{"error": err.Error()})
+	}
+	_, _ = fmt.Fprintf(w, "%s\n", data)
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/quocvuong92/ai-cli/internal/api"
+	"github.com/quocvuong92/ai-cli/internal/config"
+)
+
+func TestWriteJSONLine_Events(t *testing.T) {
+	result := outputResult{
+		Content:  "Hello",
+		Model:    "gpt-4o",
+		Provider: "azure",
+		Usage:    &api.Usage{PromptTokens: 3, CompletionTokens: 1, TotalTokens: 4},
+	}
+
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{"delta", outputEvent{Type: "delta", Delta: "He"}, `{"type":"delta","delta":"He"}`},
+		{"error", outputEvent{Type: "error", Error: "boom"}, `{"type":"error","error":"boom"}`},
+		{
+			"done",
+			outputEvent{Type: "done", outputResult: &result},
+			`{"type":"done","content":"Hello","model":"gpt-4o","provider":"azure","usage":{"prompt_tokens":3,"completion_tokens":1,"total_tokens":4}}`,
+		},
+		{"result", outputResult{Content: "", Model: "m", Provider: "openai"}, `{"content":"","model":"m","provider":"openai"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			writeJSONLine(&buf, tt.value)
+			if got := buf.String(); got != tt.want+"\n" {
+				t.Errorf("writeJSONLine() = %s, want %s", strings.TrimSpace(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestNewOutputResult(t *testing.T) {
+	app := NewApp()
+	app.cfg.Provider = "anthropic"
+	app.cfg.Model = "claude-sonnet-4"
+	app.searchResults = &api.TavilyResponse{Results: []api.TavilyResult{{Title: "Go", URL: "https://go.dev"}}}
+
+	call := api.ToolCall{ID: "call_1", Type: "function"}
+	call.Function.Name = "execute_command"
+	call.Function.Arguments = `{"command":"ls"}`
+
+	resp := &api.ChatResponse{
+		Choices: []api.Choice{{
+			Message:      api.Message{Role: "assistant", Content: "done", ToolCalls: []api.ToolCall{call}},
+			FinishReason: "tool_calls",
+		}},
+		Usage: api.Usage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15},
+	}
+
+	got := app.newOutputResult(resp)
+	if got.Content != "done" || got.Model != "claude-sonnet-4" || got.Provider != "anthropic" {
+		t.Errorf("newOutputResult() = %+v, want content, model and provider filled in", got)
+	}
+	if got.FinishReason != "tool_calls" || len(got.ToolCalls) != 1 || got.ToolCalls[0].ID != "call_1" {
+		t.Errorf("newOutputResult() finish_reason = %q, tool_calls = %+v", got.FinishReason, got.ToolCalls)
+	}
+	if got.Usage == nil || got.Usage.TotalTokens != 15 {
+		t.Errorf("newOutputResult() usage = %+v, want total_tokens 15", got.Usage)
+	}
+	if len(got.Citations) != 1 || got.Citations[0].URL != "https://go.dev" {
+		t.Errorf("newOutputResult() citations = %+v", got.Citations)
+	}
+
+	empty := app.newOutputResult(contentResponse("{}"))
+	if empty.Content != "{}" || empty.Usage != nil {
+		t.Errorf("newOutputResult(contentResponse) = %+v, want content and no usage", empty)
+	}
+}
+
+func TestMachineOutput(t *testing.T) {
+	app := NewApp()
+	for _, tt := range []struct {
+		output string
+		want   bool
+	}{
+		{config.OutputText, false},
+		{config.OutputJSON, true},
+		{config.OutputNDJSON, true},
+	} {
+		app.cfg.Output = tt.output
+		if got := app.machineOutput(); got != tt.want {
+			t.Errorf("machineOutput() with %q = %v, want %v", tt.output, got, tt.want)
+		}
+	}
+}
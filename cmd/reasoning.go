@@ -0,0 +1,23 @@
+package cmd
+
+import "github.com/quocvuong92/ai-cli/internal/display"
+
+// reasoningDisplay shows streamed reasoning and separates it from the
+// answer that follows
+type reasoningDisplay struct {
+	active bool
+}
+
+// chunk displays a piece of reasoning
+func (r *reasoningDisplay) chunk(content string) {
+	r.active = true
+	display.ShowReasoningChunk(content)
+}
+
+// end closes the reasoning block, if one is open, before content is shown
+func (r *reasoningDisplay) end() {
+	if r.active {
+		r.active = false
+		display.EndReasoning()
+	}
+}
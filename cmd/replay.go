@@ -0,0 +1,327 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/google/uuid"
+	"github.com/mattn/go-runewidth"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/quocvuong92/ai-cli/internal/api"
+	"github.com/quocvuong92/ai-cli/internal/config"
+	"github.com/quocvuong92/ai-cli/internal/display"
+)
+
+const (
+	// defaultReplayWidth is the width of the comparison when stdout is not
+	// a terminal
+	defaultReplayWidth = 120
+	// minReplayWidth keeps the comparison columns usable
+	minReplayWidth = 40
+	// replayPromptLines limits how much of each user message is shown
+	replayPromptLines = 4
+)
+
+// replayFlags holds the replay command's flag values
+type replayFlags struct {
+	save  bool
+	width int
+}
+
+// replayTurn is a user message of a replayed conversation with the answer
+// it got originally and the one it gets now
+type replayTurn struct {
+	prompt string
+	before string
+	after  string
+}
+
+// newReplayCmd creates the replay command
+func (app *App) newReplayCmd() *cobra.Command {
+	var flags replayFlags
+
+	cmd := &cobra.Command{
+		Use:   "replay <id|index> --model <model>",
+		Short: "Re-run a saved conversation against another model",
+		Long: `Re-run the user messages of a saved conversation against another model and
+show the original and new answers side by side.
+
+Each message is sent with the new model's own earlier answers as context.
+Tools are not offered, so answers that relied on tool results in the original
+conversation may differ for that reason alone.
+
+Examples:
+  ai-cli replay 1 --model claude-sonnet-4.5
+  ai-cli replay 3f9a1c2e --provider anthropic --model claude-sonnet-4.5 --save`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			app.runReplay(cmd, args[0], flags)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&app.verbose, "verbose", "v", false, "Enable debug mode")
+	cmd.Flags().StringVarP(&app.cfg.Model, "model", "m", "", "Model to replay the conversation against")
+	cmd.Flags().StringVar(&app.cfg.Provider, "provider", "", "AI provider: copilot, azure, openai, anthropic (default: auto-detect)")
+	cmd.Flags().BoolVar(&app.cfg.NoCache, "no-cache", false, "Bypass the response cache for this run")
+	cmd.Flags().BoolVar(&flags.save, "save", false, "Save the replayed conversation to history")
+	cmd.Flags().IntVar(&flags.width, "width", 0, "Width of the comparison (default: terminal width)")
+	_ = cmd.MarkFlagRequired("model")
+	app.addSamplingFlags(cmd)
+
+	return cmd
+}
+
+// runReplay replays a saved conversation and prints the comparison
+func (app *App) runReplay(cmd *cobra.Command, ref string, flags replayFlags) {
+	app.setupLogging()
+	app.applySamplingFlags(cmd)
+	if err := app.cfg.Validate(); err != nil {
+		display.ShowError(err.Error())
+		os.Exit(1)
+	}
+
+	hist, err := app.openHistory()
+	if err != nil {
+		display.ShowError(err.Error())
+		os.Exit(1)
+	}
+	conv, err := hist.Resolve(ref)
+	if err != nil {
+		display.ShowError(err.Error())
+		os.Exit(1)
+	}
+	turns := originalTurns(conv.Messages)
+	if len(turns) == 0 {
+		display.ShowError("the conversation has no user messages to replay")
+		os.Exit(1)
+	}
+
+	app.usageCommand = usageCommandReplay
+	app.conversationID = uuid.New().String()
+	client, err := app.newClient()
+	if err != nil {
+		display.ShowError(err.Error())
+		os.Exit(1)
+	}
+	defer client.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	width := flags.width
+	if width <= 0 {
+		width = replayWidth()
+	}
+	before := "Before"
+	if conv.Model != "" {
+		before += ": " + conv.Model
+	}
+	after := "After: " + app.cfg.Model
+
+	fmt.Printf("Replaying %d messages of %q against %s\n", len(turns), conv.Title(), app.cfg.Model)
+	messages, err := app.replay(ctx, client, conv.Messages, turns, func(i int, t replayTurn) {
+		fmt.Println()
+		fmt.Print(formatReplayTurn(i+1, len(turns), t, before, after, width))
+	})
+	if err != nil {
+		display.ShowError(err.Error())
+	}
+
+	if flags.save && len(messages) > 1 {
+		hist.AddConversation(app.conversationID, app.cfg.Model, app.getProviderName(), messages)
+		if err := hist.Save(); err != nil {
+			display.ShowError(err.Error())
+			os.Exit(1)
+		}
+		fmt.Printf("\nSaved the replay as conversation %s\n", shortID(app.conversationID))
+	}
+	if err != nil {
+		os.Exit(1)
+	}
+}
+
+// replay sends each turn's user message with the replayed conversation so
+// far, starting from the original system message, and calls onTurn with
+// each answer. It returns the replayed messages, up to the failed turn if a
+// request fails.
+func (app *App) replay(ctx context.Context, client api.AIClient, original []api.Message, turns []replayTurn, onTurn func(int, replayTurn)) ([]api.Message, error) {
+	system := config.DefaultSystemMessage
+	if len(original) > 0 && original[0].Role == "system" {
+		system = original[0].Content
+	}
+	messages := []api.Message{{Role: "system", Content: system}}
+
+	for i, t := range turns {
+		if err := app.checkLimits(); err != nil {
+			return messages, err
+		}
+		messages = append(messages, api.Message{Role: "user", Content: t.prompt})
+
+		sp := display.NewSpinner(fmt.Sprintf("Replaying message %d of %d...", i+1, len(turns)))
+		sp.Start()
+		resp, err := client.Complete(ctx, app.newRequest(messages, nil))
+		sp.Stop()
+		if err != nil {
+			return messages[:len(messages)-1], fmt.Errorf("message %d: %w", i+1, err)
+		}
+
+		t.after = resp.GetContent()
+		messages = append(messages, api.Message{Role: "assistant", Content: t.after})
+		onTurn(i, t)
+	}
+	return messages, nil
+}
+
+// originalTurns splits messages into the user messages and the answer each
+// got. Tool calls are listed after the answer; the follow-ups /continue
+// sends are folded into the turn they continue.
+func originalTurns(messages []api.Message) []replayTurn {
+	var turns []replayTurn
+	var answer strings.Builder
+	var tools []string
+	continued := false
+
+	flush := func() {
+		if len(turns) == 0 {
+			return
+		}
+		text := answer.String()
+		if len(tools) > 0 {
+			if text != "" {
+				text += "\n\n"
+			}
+			text += "[tools: " + strings.Join(tools, ", ") + "]"
+		}
+		turns[len(turns)-1].before = text
+	}
+
+	for _, m := range messages {
+		switch m.Role {
+		case "user":
+			if m.Content == continuePrompt {
+				continued = true
+				continue
+			}
+			flush()
+			answer.Reset()
+			tools = nil
+			turns = append(turns, replayTurn{prompt: m.Content})
+		case "assistant":
+			if m.Content != "" {
+				// A continuation picks up mid-sentence
+				if answer.Len() > 0 && !continued {
+					answer.WriteString("\n\n")
+				}
+				answer.WriteString(m.Content)
+				continued = false
+			}
+			for _, tc := range m.ToolCalls {
+				tools = append(tools, tc.Function.Name)
+			}
+		}
+	}
+	flush()
+	return turns
+}
+
+// replayWidth returns the terminal width, or defaultReplayWidth when stdout
+// is not a terminal
+func replayWidth() int {
+	if w, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil && w > 0 {
+		return w
+	}
+	return defaultReplayWidth
+}
+
+// formatReplayTurn formats turn n of total: the user message, then the
+// original and replayed answers side by side
+func formatReplayTurn(n, total int, t replayTurn, before, after string, width int) string {
+	width = max(width, minReplayWidth)
+	var b strings.Builder
+
+	heading := fmt.Sprintf("━━ Message %d of %d ", n, total)
+	b.WriteString(heading + strings.Repeat("━", max(0, width-runewidth.StringWidth(heading))) + "\n")
+
+	prompt := wrapText("👤 "+strings.TrimSpace(t.prompt), width)
+	if len(prompt) > replayPromptLines {
+		prompt = append(prompt[:replayPromptLines], "...")
+	}
+	b.WriteString(strings.Join(prompt, "\n") + "\n\n")
+
+	b.WriteString(sideBySide(before, t.before, after, t.after, width))
+	return b.String()
+}
+
+// sideBySide lays out left and right as two columns under their titles,
+// wrapping lines to fit width
+func sideBySide(leftTitle, left, rightTitle, right string, width int) string {
+	col := (width - 3) / 2
+	leftLines := append([]string{leftTitle, strings.Repeat("─", col)}, wrapText(strings.TrimSpace(left), col)...)
+	rightLines := append([]string{rightTitle, strings.Repeat("─", col)}, wrapText(strings.TrimSpace(right), col)...)
+
+	var b strings.Builder
+	for i := 0; i < max(len(leftLines), len(rightLines)); i++ {
+		var l, r string
+		if i < len(leftLines) {
+			l = leftLines[i]
+		}
+		if i < len(rightLines) {
+			r = rightLines[i]
+		}
+		sep := " │ "
+		if i == 1 {
+			sep = "─┼─"
+		}
+		b.WriteString(strings.TrimRight(runewidth.FillRight(runewidth.Truncate(l, col, ""), col)+sep+runewidth.Truncate(r, col, ""), " ") + "\n")
+	}
+	return b.String()
+}
+
+// wrapText breaks text into lines no wider than width, at spaces where
+// possible. Indentation at the start of a line is kept.
+func wrapText(text string, width int) []string {
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\t", "    "), "\n") {
+		line = strings.TrimRight(line, " \r")
+		for runewidth.StringWidth(line) > width {
+			cut := breakPoint(line, width)
+			lines = append(lines, strings.TrimRight(line[:cut], " "))
+			line = strings.TrimLeft(line[cut:], " ")
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// breakPoint returns where to break line so the first part fits in width:
+// at the last space that fits, else mid-word
+func breakPoint(line string, width int) int {
+	w, lastSpace := 0, -1
+	for i, r := range line {
+		rw := runewidth.RuneWidth(r)
+		if w+rw > width {
+			if r == ' ' && i > 0 {
+				return i
+			}
+			if lastSpace > 0 {
+				return lastSpace
+			}
+			if i == 0 {
+				// A character wider than the column still has to go somewhere
+				return len(string(r))
+			}
+			return i
+		}
+		if r == ' ' && strings.TrimLeft(line[:i], " ") != "" {
+			lastSpace = i
+		}
+		w += rw
+	}
+	return len(line)
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mattn/go-runewidth"
+
+	"github.com/quocvuong92/ai-cli/internal/api"
+)
+
+func TestOriginalTurns(t *testing.T) {
+	call := api.ToolCall{ID: "c1"}
+	call.Function.Name = "read_file"
+	messages := []api.Message{
+		{Role: "system", Content: "sys"},
+		{Role: "user", Content: "Read main.go"},
+		{Role: "assistant", ToolCalls: []api.ToolCall{call}},
+		{Role: "tool", ToolCallID: "c1", Content: "package main"},
+		{Role: "assistant", Content: "It is the main package."},
+		{Role: "user", Content: "Explain it"},
+		{Role: "assistant", Content: "It starts "},
+		{Role: "user", Content: continuePrompt},
+		{Role: "assistant", Content: "the program."},
+	}
+
+	turns := originalTurns(messages)
+	if len(turns) != 2 {
+		t.Fatalf("originalTurns() = %d turns, want 2", len(turns))
+	}
+	if turns[0].prompt != "Read main.go" || turns[0].before != "It is the main package.\n\n[tools: read_file]" {
+		t.Errorf("turn 1 = %+v", turns[0])
+	}
+	if turns[1].before != "It starts the program." {
+		t.Errorf("turn 2 = %+v, want the continuation joined", turns[1])
+	}
+}
+
+func TestApp_Replay(t *testing.T) {
+	provider := &scriptedProvider{replies: []string{"A1", "A2"}}
+	app := newTestApp()
+	original := []api.Message{
+		{Role: "system", Content: "Original system"},
+		{Role: "user", Content: "Q1"},
+		{Role: "assistant", Content: "old 1"},
+		{Role: "user", Content: "Q2"},
+		{Role: "assistant", Content: "old 2"},
+	}
+
+	var seen []replayTurn
+	messages, err := app.replay(context.Background(), api.NewClientAdapter(provider), original, originalTurns(original), func(i int, t replayTurn) {
+		seen = append(seen, t)
+	})
+	if err != nil {
+		t.Fatalf("replay() error = %v", err)
+	}
+	if len(seen) != 2 || seen[1].before != "old 2" || seen[1].after != "A2" {
+		t.Errorf("replayed turns = %+v", seen)
+	}
+	if len(messages) != 5 || messages[0].Content != "Original system" || messages[2].Content != "A1" {
+		t.Errorf("replayed messages = %+v", messages)
+	}
+	// The second request carries the new model's first answer, not the old one
+	if sent := provider.requests[1].Messages; len(sent) != 4 || sent[2].Content != "A1" {
+		t.Errorf("second request = %+v", sent)
+	}
+	if provider.requests[0].Tools != nil {
+		t.Error("replay should not offer tools")
+	}
+}
+
+func TestSideBySide(t *testing.T) {
+	out := sideBySide("Before", "short", "After", strings.Repeat("word ", 20), 41)
+	lines := strings.Split(strings.TrimRight(out, "\n"), "\n")
+	if len(lines) < 4 || !strings.HasPrefix(lines[0], "Before") || !strings.Contains(lines[0], "│ After") {
+		t.Fatalf("sideBySide() =\n%s", out)
+	}
+	for _, line := range lines {
+		if w := runewidth.StringWidth(line); w > 41 {
+			t.Errorf("line %q is %d wide, want at most 41", line, w)
+		}
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	tests := []struct {
+		text  string
+		width int
+		want  []string
+	}{
+		{"one two three", 7, []string{"one two", "three"}},
+		{"abcdefghij", 4, []string{"abcd", "efgh", "ij"}},
+		{"    indented code line", 12, []string{"    indented", "code line"}},
+		{"a\n\nb", 10, []string{"a", "", "b"}},
+		{"日本語テキスト", 6, []string{"日本語", "テキス", "ト"}},
+	}
+	for _, tt := range tests {
+		got := wrapText(tt.text, tt.width)
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("wrapText(%q, %d) = %q, want %q", tt.text, tt.width, got, tt.want)
+		}
+	}
+}
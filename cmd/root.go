@@ -5,12 +5,14 @@ import (
 	"io"
 	"log"
 	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
 
 	"github.com/quocvuong92/ai-cli/internal/api"
 	"github.com/quocvuong92/ai-cli/internal/config"
 	"github.com/quocvuong92/ai-cli/internal/display"
+	"github.com/quocvuong92/ai-cli/internal/usage"
 )
 
 // App holds the application state
@@ -19,7 +21,20 @@ type App struct {
 	client        api.AIClient
 	verbose       bool
 	listModels    bool
+	refreshModels bool
+	models        []api.ModelInfo // Live model list, when the provider reports one
+	sampling      samplingFlags
+	files         []string            // -f attachments ("-" for stdin)
+	images        []string            // --image attachments
+	imageParts    []api.ContentPart   // Loaded --image attachments
+	approval      approvalPolicy      // agent --auto-approve policy; empty prompts the user
+	maxIterations int                 // agent --max-iterations
 	searchResults *api.TavilyResponse // Store search results for citations
+
+	usageLedger    *usage.Ledger // Where response usage is recorded; nil for the default
+	usageCommand   string        // Command recorded with usage: query, interactive, agent or serve
+	conversationID string        // Interactive conversation recorded with usage
+	budget         budget        // Usage counted against the configured limits
 }
 
 // NewApp creates a new App instance with default configuration
@@ -34,9 +49,10 @@ func Execute() {
 	app := NewApp()
 
 	rootCmd := &cobra.Command{
-		Use:   "ai-cli [query]",
+		Use:   "ai-cli [query...]",
 		Short: "A CLI client for AI models with web search",
-		Long: `AI CLI is a command-line client for AI models (GitHub Copilot, Azure OpenAI),
+		Long: `AI CLI is a command-line client for AI models (GitHub Copilot, Azure OpenAI,
+Anthropic, OpenAI-compatible servers),
 with optional web search powered by Tavily, Linkup, or Brave.
 
 Supports multiple providers and API keys with automatic rotation.
@@ -46,9 +62,14 @@ Examples:
   ai-cli -m gpt-4o "Explain Docker"
   ai-cli --web "Latest news on Go 1.24"
   ai-cli --web --provider brave "Latest AI news"
+  ai-cli --provider anthropic "Review this design"
+  ai-cli --temperature 0.2 --max-tokens 500 "Summarize RFC 9110"
+  ai-cli --json-schema person.json "Extract: Ada, 36, admin" | jq .name
+  git diff | ai-cli "review this"
+  ai-cli -f main.go -f main_test.go "Why does the test fail?"
   ai-cli -i                             # Interactive mode
   ai-cli -ir                            # Interactive with markdown rendering`,
-		Args: cobra.MaximumNArgs(1),
+		Args: cobra.ArbitraryArgs,
 		Run: func(cmd *cobra.Command, args []string) {
 			app.run(cmd, args)
 		},
@@ -57,44 +78,70 @@ Examples:
 	rootCmd.Flags().BoolVarP(&app.verbose, "verbose", "v", false, "Enable debug mode")
 	rootCmd.Flags().BoolVarP(&app.cfg.Usage, "usage", "u", false, "Show token usage statistics")
 	rootCmd.Flags().BoolVarP(&app.cfg.Stream, "stream", "s", false, "Stream output in real-time")
+	rootCmd.Flags().StringVarP(&app.cfg.Output, "output", "o", config.OutputText, "Output format for one-shot queries: text, json, or ndjson")
 	rootCmd.Flags().BoolVarP(&app.cfg.Render, "render", "r", false, "Render markdown with colors and formatting")
 	rootCmd.Flags().BoolVarP(&app.cfg.WebSearch, "web", "w", false, "Search web first (requires TAVILY_API_KEYS, LINKUP_API_KEYS, or BRAVE_API_KEYS)")
 	rootCmd.Flags().BoolVarP(&app.cfg.Citations, "citations", "c", false, "Show citations/sources from web search")
 	rootCmd.Flags().BoolVarP(&app.cfg.Interactive, "interactive", "i", false, "Interactive chat mode")
 	rootCmd.Flags().StringVarP(&app.cfg.Model, "model", "m", "", "Model name (e.g., gpt-4.1, claude-3.7-sonnet)")
 	rootCmd.Flags().StringVarP(&app.cfg.WebSearchProvider, "search-provider", "p", "", "Web search provider: tavily, linkup, or brave (default: auto-detect)")
-	rootCmd.Flags().StringVar(&app.cfg.Provider, "provider", "", "AI provider: copilot, azure (default: auto-detect)")
+	rootCmd.Flags().StringVar(&app.cfg.Provider, "provider", "", "AI provider: copilot, azure, openai, anthropic (default: auto-detect)")
 	rootCmd.Flags().BoolVar(&app.listModels, "list-models", false, "List available models")
+	rootCmd.Flags().BoolVar(&app.refreshModels, "refresh-models", false, "Fetch the model list from the provider, bypassing the cache")
+	rootCmd.Flags().StringArrayVarP(&app.files, "file", "f", nil, "Attach a file to the query (repeatable, - for stdin)")
+	rootCmd.Flags().StringArrayVar(&app.images, "image", nil, "Attach an image to the query (repeatable; PNG, JPEG, GIF or WebP)")
+	rootCmd.Flags().StringVar(&app.cfg.JSONSchemaFile, "json-schema", "", "Require JSON output matching this JSON Schema file (printed raw to stdout)")
+	rootCmd.Flags().IntVar(&app.cfg.JSONSchemaRetries, "json-retries", config.DefaultJSONSchemaRetries, "Re-prompts allowed when output fails --json-schema validation")
+	rootCmd.Flags().BoolVar(&app.cfg.NoCache, "no-cache", false, "Bypass the response cache for this run")
+	app.addSamplingFlags(rootCmd)
 
 	// Add subcommands
 	rootCmd.AddCommand(NewLoginCmd())
 	rootCmd.AddCommand(NewLogoutCmd())
 	rootCmd.AddCommand(NewStatusCmd())
+	rootCmd.AddCommand(app.newAgentCmd())
+	rootCmd.AddCommand(app.newServeCmd())
+	rootCmd.AddCommand(app.newCacheCmd())
+	rootCmd.AddCommand(app.newUsageCmd())
+	rootCmd.AddCommand(app.newHistoryCmd())
+	rootCmd.AddCommand(app.newReplayCmd())
 
 	if err := rootCmd.Execute(); err != nil {
 		os.Exit(1)
 	}
 }
 
-func (app *App) run(cmd *cobra.Command, args []string) {
+// setupLogging sends debug logs to stderr with --verbose and discards them otherwise
+func (app *App) setupLogging() {
 	if app.verbose {
 		log.SetOutput(os.Stderr)
 		log.SetFlags(log.Ldate | log.Ltime | log.Lshortfile)
 	} else {
 		log.SetOutput(io.Discard)
 	}
+}
+
+func (app *App) run(cmd *cobra.Command, args []string) {
+	app.setupLogging()
+	app.applySamplingFlags(cmd)
 
 	// Handle --list-models flag
 	if app.listModels {
 		if err := app.cfg.Validate(); err != nil {
 			log.Printf("Config validation warning: %v", err)
 		}
+		if client, err := app.newClient(); err == nil {
+			app.loadModels(client, app.refreshModels)
+			client.Close()
+		} else {
+			log.Printf("Skipping model discovery: %v", err)
+		}
 		if len(app.cfg.AvailableModels) == 0 {
 			fmt.Println("No models configured.")
 			fmt.Println("Run 'ai-cli login' for GitHub Copilot or set AZURE_OPENAI_MODELS.")
 			os.Exit(1)
 		}
-		display.ShowModels(app.cfg.AvailableModels, app.cfg.Model)
+		app.showModels()
 		return
 	}
 
@@ -117,45 +164,82 @@ func (app *App) run(cmd *cobra.Command, args []string) {
 		return
 	}
 
-	// Require query if not interactive mode
-	if len(args) == 0 {
+	// Read attachments: -f files and piped stdin
+	inputs, err := collectInputs(app.files, os.Stdin, stdinIsPiped())
+	if err != nil {
+		display.ShowError(err.Error())
+		os.Exit(1)
+	}
+
+	// Read --image attachments
+	for _, path := range app.images {
+		part, err := api.LoadImage(path)
+		if err != nil {
+			display.ShowError(err.Error())
+			os.Exit(1)
+		}
+		app.imageParts = append(app.imageParts, part)
+	}
+	if len(app.imageParts) > 0 {
+		app.warnIfNoVision()
+	}
+
+	// Require a query or piped input if not interactive mode
+	query := strings.Join(args, " ")
+	if query == "" && len(inputs) == 0 && len(app.imageParts) == 0 {
 		_ = cmd.Help()
 		os.Exit(1)
 	}
 
-	query := args[0]
 	log.Printf("Query: %s", query)
 	log.Printf("Model: %s", app.cfg.Model)
 	log.Printf("Stream: %v", app.cfg.Stream)
 	log.Printf("WebSearch: %v", app.cfg.WebSearch)
+	log.Printf("Attachments: %d files, %d images", len(inputs), len(app.imageParts))
 
 	// Build system prompt and user message
 	systemPrompt := config.DefaultSystemMessage
-	userMessage := query
+	userMessage := buildUserMessage(query, inputs)
 
 	// Web search if requested
 	if app.cfg.WebSearch {
+		if query == "" {
+			display.ShowError("--web requires a query argument to search for")
+			os.Exit(1)
+		}
 		searchContext, err := app.performWebSearch(query)
 		if err != nil {
-			display.ShowError(err.Error())
-			os.Exit(1)
+			app.fail(err.Error())
 		}
 		systemPrompt = buildWebSearchPrompt(searchContext)
 	}
 
 	// Create AI client (auto-detects provider)
-	client, err := api.NewClient(app.cfg)
+	client, err := app.newClient()
 	if err != nil {
-		display.ShowError(err.Error())
-		os.Exit(1)
+		app.fail(err.Error())
 	}
 	app.client = client
 
+	if err := app.checkLimits(); err != nil {
+		app.fail(err.Error())
+	}
+
 	log.Printf("Sending request...")
 
-	if app.cfg.Stream {
+	switch {
+	case app.cfg.JSONSchemaFile != "":
+		app.runStructured(client, systemPrompt, userMessage)
+		return
+	case app.cfg.Output == config.OutputJSON:
+		app.runJSON(client, systemPrompt, userMessage)
+		return
+	case app.cfg.Output == config.OutputNDJSON:
+		app.runNDJSON(client, systemPrompt, userMessage)
+		return
+	case app.cfg.Stream:
 		app.runStream(client, systemPrompt, userMessage)
-	} else {
+	default:
 		app.runNormal(client, systemPrompt, userMessage)
 	}
 
@@ -169,3 +253,32 @@ func (app *App) run(cmd *cobra.Command, args []string) {
 		display.ShowCitations(citations)
 	}
 }
+
+// newClient creates the AI client for the current configuration, wires
+// fallback chain notifications to the display, and counts usage against the
+// limits and in the ledger.
+func (app *App) newClient() (api.AIClient, error) {
+	api.Capabilities.SetOverrides(app.cfg.ModelCapabilities)
+	client, err := api.NewClient(app.cfg)
+	if err != nil {
+		return nil, err
+	}
+	if fc, ok := client.(*api.FallbackClient); ok {
+		fc.SetFailoverCallback(display.ShowFailover)
+		fc.SetAnsweredCallback(display.ShowBackend)
+	}
+	if !app.cfg.NoUsageLedger && app.usageLedger == nil {
+		app.usageLedger = usage.NewLedger()
+	}
+	client = api.NewUsageClient(client, func(r api.Request, resp *api.ChatResponse) {
+		app.trackUsage(resp)
+		if !app.cfg.NoUsageLedger {
+			app.recordUsage(app.usageLedger, r, resp)
+		}
+	})
+	// The cache wraps the usage recorder so cached answers aren't counted
+	if app.cfg.Cache && !app.cfg.NoCache {
+		client = api.NewCachingClient(client, app.responseCache(), app.cacheProvider(), app.cfg)
+	}
+	return client, nil
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"strings"
@@ -13,7 +14,7 @@ func (app *App) runNormal(client api.AIClient, systemPrompt, userMessage string)
 	sp := display.NewSpinner("Waiting for response...")
 	sp.Start()
 
-	resp, err := client.Query(systemPrompt, userMessage)
+	resp, err := client.Complete(context.Background(), app.newRequest(app.promptMessages(systemPrompt, userMessage), nil))
 	sp.Stop()
 
 	if err != nil {
@@ -21,6 +22,7 @@ func (app *App) runNormal(client api.AIClient, systemPrompt, userMessage string)
 		os.Exit(1)
 	}
 
+	display.ShowReasoning(resp.GetReasoning())
 	if app.cfg.Render {
 		display.ShowContentRendered(resp.GetContent())
 	} else {
@@ -36,13 +38,19 @@ func (app *App) runNormal(client api.AIClient, systemPrompt, userMessage string)
 func (app *App) runStream(client api.AIClient, systemPrompt, userMessage string) {
 	var finalResp *api.ChatResponse
 	var fullContent strings.Builder
+	var reasoning reasoningDisplay
 	firstChunk := true
 
 	sp := display.NewSpinner("Waiting for response...")
 	sp.Start()
 
-	err := client.QueryStream(systemPrompt, userMessage,
-		func(content string) {
+	err := app.streamWithContinue(context.Background(), client, app.newRequest(app.promptMessages(systemPrompt, userMessage), nil), api.StreamHandler{
+		OnReasoning: func(content string) {
+			sp.Stop()
+			reasoning.chunk(content)
+		},
+		OnChunk: func(content string) {
+			reasoning.end()
 			if firstChunk {
 				firstChunk = false
 				if app.cfg.Render {
@@ -58,12 +66,13 @@ func (app *App) runStream(client api.AIClient, systemPrompt, userMessage string)
 				fmt.Print(content)
 			}
 		},
-		func(resp *api.ChatResponse) {
+		OnDone: func(resp *api.ChatResponse) {
 			finalResp = resp
 		},
-	)
+	})
 
 	sp.Stop()
+	reasoning.end()
 
 	if err != nil {
 		display.ShowError(err.Error())
@@ -81,3 +90,12 @@ func (app *App) runStream(client api.AIClient, systemPrompt, userMessage string)
 		display.ShowUsage(finalResp.GetUsageMap())
 	}
 }
+
+// promptMessages builds the system + user message pair for a one-shot
+// query, with any --image attachments
+func (app *App) promptMessages(systemPrompt, userMessage string) []api.Message {
+	return []api.Message{
+		{Role: "system", Content: systemPrompt},
+		api.NewUserMessage(userMessage, app.imageParts),
+	}
+}
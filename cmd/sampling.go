@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/quocvuong92/ai-cli/internal/api"
+)
+
+// samplingFlags holds raw sampling flag values until we know which were set
+type samplingFlags struct {
+	temperature float64
+	topP        float64
+	seed        int
+}
+
+// addSamplingFlags registers the sampling parameter flags on a command
+func (app *App) addSamplingFlags(cmd *cobra.Command) {
+	cmd.Flags().Float64Var(&app.sampling.temperature, "temperature", 0, "Sampling temperature (0-2)")
+	cmd.Flags().Float64Var(&app.sampling.topP, "top-p", 0, "Nucleus sampling probability mass (0-1)")
+	cmd.Flags().IntVar(&app.cfg.MaxTokens, "max-tokens", 0, "Maximum number of tokens to generate")
+	cmd.Flags().IntVar(&app.sampling.seed, "seed", 0, "Seed for deterministic sampling (where supported)")
+	cmd.Flags().StringArrayVar(&app.cfg.Stop, "stop", nil, "Stop sequence (repeatable)")
+	cmd.Flags().StringVar(&app.cfg.ReasoningEffort, "reasoning-effort", "", "Reasoning effort for reasoning models: low, medium, high")
+}
+
+// applySamplingFlags copies explicitly set sampling flags into the config,
+// so unset flags fall back to config file defaults and then provider defaults
+func (app *App) applySamplingFlags(cmd *cobra.Command) {
+	if cmd.Flags().Changed("temperature") {
+		app.cfg.Temperature = &app.sampling.temperature
+	}
+	if cmd.Flags().Changed("top-p") {
+		app.cfg.TopP = &app.sampling.topP
+	}
+	if cmd.Flags().Changed("seed") {
+		app.cfg.Seed = &app.sampling.seed
+	}
+}
+
+// newRequest builds an API request for messages and tools using the
+// current sampling settings
+func (app *App) newRequest(messages []api.Message, tools []api.Tool) api.Request {
+	return api.Request{
+		Messages:    messages,
+		Tools:       tools,
+		Temperature: app.cfg.Temperature,
+		TopP:        app.cfg.TopP,
+		MaxTokens:   app.cfg.MaxTokens,
+		Seed:        app.cfg.Seed,
+		Stop:        app.cfg.Stop,
+
+		ReasoningEffort: app.cfg.ReasoningEffort,
+	}
+}
+
+// samplingSettings lists the parameters accepted by /set
+var samplingSettings = []string{"temperature", "top_p", "max_tokens", "seed", "stop", "reasoning_effort"}
+
+// handleSetCommand processes /set <param> <value> to change sampling parameters.
+// "/set <param> default" clears a parameter; "/set" alone shows current values.
+func (app *App) handleSetCommand(parts []string) {
+	if len(parts) < 2 || strings.TrimSpace(parts[1]) == "" {
+		app.showSampling()
+		return
+	}
+
+	fields := strings.SplitN(strings.TrimSpace(parts[1]), " ", 2)
+	name := strings.ReplaceAll(strings.ToLower(fields[0]), "-", "_")
+	if len(fields) < 2 || strings.TrimSpace(fields[1]) == "" {
+		fmt.Println("Usage: /set <parameter> <value|default>")
+		fmt.Printf("Parameters: %s\n", strings.Join(samplingSettings, ", "))
+		return
+	}
+	value := strings.TrimSpace(fields[1])
+
+	previous := *app.cfg
+	if err := app.setSampling(name, value); err != nil {
+		fmt.Printf("Invalid value for %s: %v\n", name, err)
+		return
+	}
+	if err := app.cfg.ValidateSampling(); err != nil {
+		*app.cfg = previous
+		fmt.Println(err)
+		return
+	}
+
+	if value == "default" {
+		fmt.Printf("Reset %s to provider default\n", name)
+	} else {
+		fmt.Printf("Set %s to %s\n", name, value)
+	}
+}
+
+// setSampling parses value and stores it in the named sampling parameter
+func (app *App) setSampling(name, value string) error {
+	reset := value == "default"
+
+	switch name {
+	case "temperature", "top_p":
+		var v *float64
+		if !reset {
+			f, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return fmt.Errorf("expected a number")
+			}
+			v = &f
+		}
+		if name == "temperature" {
+			app.cfg.Temperature = v
+		} else {
+			app.cfg.TopP = v
+		}
+
+	case "max_tokens":
+		if reset {
+			app.cfg.MaxTokens = 0
+			return nil
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("expected a positive integer")
+		}
+		app.cfg.MaxTokens = n
+
+	case "seed":
+		if reset {
+			app.cfg.Seed = nil
+			return nil
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("expected an integer")
+		}
+		app.cfg.Seed = &n
+
+	case "stop":
+		if reset {
+			app.cfg.Stop = nil
+			return nil
+		}
+		// "+X" adds to the current list; a quoted "+X" is a literal sequence
+		if extra, ok := strings.CutPrefix(value, "+"); ok {
+			app.cfg.Stop = append(slices.Clone(app.cfg.Stop), unquote(strings.TrimSpace(extra)))
+		} else {
+			app.cfg.Stop = []string{unquote(value)}
+		}
+
+	case "reasoning_effort":
+		if reset {
+			app.cfg.ReasoningEffort = ""
+			return nil
+		}
+		app.cfg.ReasoningEffort = strings.ToLower(value)
+
+	default:
+		return fmt.Errorf("unknown parameter (available: %s)", strings.Join(samplingSettings, ", "))
+	}
+	return nil
+}
+
+// showSampling prints the current sampling parameters
+func (app *App) showSampling() {
+	fmt.Println("Sampling parameters:")
+	fmt.Printf("  %-12s %s\n", "temperature", formatFloat(app.cfg.Temperature))
+	fmt.Printf("  %-12s %s\n", "top_p", formatFloat(app.cfg.TopP))
+	maxTokens := "default"
+	if app.cfg.MaxTokens > 0 {
+		maxTokens = strconv.Itoa(app.cfg.MaxTokens)
+	}
+	fmt.Printf("  %-12s %s\n", "max_tokens", maxTokens)
+	seed := "default"
+	if app.cfg.Seed != nil {
+		seed = strconv.Itoa(*app.cfg.Seed)
+	}
+	fmt.Printf("  %-12s %s\n", "seed", seed)
+	stop := "default"
+	if len(app.cfg.Stop) > 0 {
+		quoted := make([]string, len(app.cfg.Stop))
+		for i, s := range app.cfg.Stop {
+			quoted[i] = strconv.Quote(s)
+		}
+		stop = strings.Join(quoted, ", ")
+	}
+	fmt.Printf("  %-12s %s\n", "stop", stop)
+	effort := "default"
+	if app.cfg.ReasoningEffort != "" {
+		effort = app.cfg.ReasoningEffort
+	}
+	fmt.Printf("  %-12s %s\n", "reasoning", effort)
+}
+
+// formatFloat formats an optional float, showing "default" when unset
+func formatFloat(v *float64) string {
+	if v == nil {
+		return "default"
+	}
+	return strconv.FormatFloat(*v, 'g', -1, 64)
+}
+
+// unquote strips one pair of surrounding double quotes, so stop sequences
+// with leading or trailing spaces can be entered
+func unquote(s string) string {
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		if u, err := strconv.Unquote(s); err == nil {
+			return u
+		}
+		return s[1 : len(s)-1]
+	}
+	return s
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/quocvuong92/ai-cli/internal/config"
+)
+
+func TestHandleSetCommand(t *testing.T) {
+	app := &App{cfg: config.NewConfig()}
+
+	app.handleSetCommand([]string{"/set", "temperature 0.2"})
+	app.handleSetCommand([]string{"/set", "top-p 0.5"})
+	app.handleSetCommand([]string{"/set", "max_tokens 256"})
+	app.handleSetCommand([]string{"/set", "seed 7"})
+	app.handleSetCommand([]string{"/set", `stop " END"`})
+
+	req := app.newRequest(nil, nil)
+	if req.Temperature == nil || *req.Temperature != 0.2 {
+		t.Errorf("Temperature = %v, want 0.2", req.Temperature)
+	}
+	if req.TopP == nil || *req.TopP != 0.5 {
+		t.Errorf("TopP = %v, want 0.5", req.TopP)
+	}
+	if req.MaxTokens != 256 {
+		t.Errorf("MaxTokens = %d, want 256", req.MaxTokens)
+	}
+	if req.Seed == nil || *req.Seed != 7 {
+		t.Errorf("Seed = %v, want 7", req.Seed)
+	}
+	if len(req.Stop) != 1 || req.Stop[0] != " END" {
+		t.Errorf("Stop = %q, want [\" END\"]", req.Stop)
+	}
+
+	// A stop sequence replaces the list, including config defaults; "+" adds one
+	app.cfg.Stop = []string{"DEFAULT"}
+	app.handleSetCommand([]string{"/set", "stop ###"})
+	app.handleSetCommand([]string{"/set", "stop +DONE"})
+	app.handleSetCommand([]string{"/set", `stop "+1"`})
+	if got := app.newRequest(nil, nil).Stop; len(got) != 1 || got[0] != "+1" {
+		t.Errorf("Stop = %q, want [\"+1\"]", got)
+	}
+	app.handleSetCommand([]string{"/set", "stop ###"})
+	app.handleSetCommand([]string{"/set", "stop +DONE"})
+	if got := app.newRequest(nil, nil).Stop; len(got) != 2 || got[0] != "###" || got[1] != "DONE" {
+		t.Errorf("Stop = %q, want [\"###\" \"DONE\"]", got)
+	}
+
+	// Out-of-range values are rejected and leave the previous value in place
+	app.handleSetCommand([]string{"/set", "temperature 3"})
+	if *app.cfg.Temperature != 0.2 {
+		t.Errorf("Temperature = %v after invalid /set, want 0.2", *app.cfg.Temperature)
+	}
+
+	app.handleSetCommand([]string{"/set", "temperature default"})
+	if app.cfg.Temperature != nil {
+		t.Errorf("Temperature = %v after reset, want nil", *app.cfg.Temperature)
+	}
+}
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/quocvuong92/ai-cli/internal/config"
+	"github.com/quocvuong92/ai-cli/internal/display"
+	"github.com/quocvuong92/ai-cli/internal/server"
+)
+
+// serveShutdownTimeout is how long in-flight requests get to finish on Ctrl+C
+const serveShutdownTimeout = 10 * time.Second
+
+// serveFlags holds the serve command's flag values
+type serveFlags struct {
+	host  string
+	port  int
+	token string
+}
+
+// newServeCmd creates the serve command
+func (app *App) newServeCmd() *cobra.Command {
+	var flags serveFlags
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve an OpenAI-compatible API backed by the configured provider",
+		Long: `Serve /v1/chat/completions (streaming and non-streaming) and /v1/models,
+backed by the configured provider, so other tools can use it as a standard
+OpenAI-compatible endpoint.
+
+Set --token or AI_SERVE_TOKEN to require "Authorization: Bearer <token>".
+The server listens on localhost unless --host is given.
+
+Examples:
+  ai-cli serve --port 8080
+  AI_SERVE_TOKEN=secret ai-cli serve --provider copilot -m gpt-4.1
+  curl http://127.0.0.1:8080/v1/models`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			app.runServe(cmd, flags)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&app.verbose, "verbose", "v", false, "Enable debug mode")
+	cmd.Flags().StringVar(&flags.host, "host", "127.0.0.1", "Address to listen on")
+	cmd.Flags().IntVar(&flags.port, "port", 8080, "Port to listen on")
+	cmd.Flags().StringVar(&flags.token, "token", "", "Bearer token clients must send (default: $AI_SERVE_TOKEN)")
+	cmd.Flags().StringVarP(&app.cfg.Model, "model", "m", "", "Default model for requests that don't name one")
+	cmd.Flags().StringVar(&app.cfg.Provider, "provider", "", "AI provider: copilot, azure, openai, anthropic (default: auto-detect)")
+
+	return cmd
+}
+
+// runServe starts the server and blocks until it is interrupted
+func (app *App) runServe(cmd *cobra.Command, flags serveFlags) {
+	app.setupLogging()
+
+	if err := app.cfg.Validate(); err != nil {
+		display.ShowError(err.Error())
+		os.Exit(1)
+	}
+
+	token := flags.token
+	if !cmd.Flags().Changed("token") {
+		token = os.Getenv(config.EnvServeToken)
+	}
+
+	app.usageCommand = usageCommandServe
+	client, err := app.newClient()
+	if err != nil {
+		display.ShowError(err.Error())
+		os.Exit(1)
+	}
+	app.client = client
+	defer client.Close()
+	app.loadModels(client, false)
+
+	addr := net.JoinHostPort(flags.host, strconv.Itoa(flags.port))
+	srv := &http.Server{
+		Addr: addr,
+		Handler: server.New(client, server.Options{
+			Token:        token,
+			DefaultModel: app.cfg.Model,
+			Models:       app.cfg.AvailableModels,
+			OwnedBy:      app.providerID(),
+		}),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), serveShutdownTimeout)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Server shutdown: %v", err)
+		}
+	}()
+
+	fmt.Fprintf(os.Stderr, "Serving %s (%s) at http://%s/v1\n", app.getProviderName(), app.cfg.Model, addr)
+	if token == "" {
+		if ip := net.ParseIP(flags.host); flags.host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+			display.ShowWarning("No token set and not bound to localhost; anyone who can reach this port can use your provider")
+		}
+	}
+
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		display.ShowError(err.Error())
+		os.Exit(1)
+	}
+}
@@ -2,7 +2,9 @@
 package cmd
 
 import (
+	"bufio"
 	"fmt"
+	"maps"
 	"os"
 	"os/exec"
 	"strings"
@@ -12,6 +14,7 @@ import (
 	"github.com/quocvuong92/ai-cli/internal/config"
 	"github.com/quocvuong92/ai-cli/internal/display"
 	"github.com/quocvuong92/ai-cli/internal/executor"
+	"github.com/quocvuong92/ai-cli/internal/history"
 	settingspkg "github.com/quocvuong92/ai-cli/internal/settings"
 )
 
@@ -30,14 +33,24 @@ func (app *App) handleCommand(input string, messages *[]api.Message, client *api
 		return true
 
 	case "/clear", "/c":
-		*messages = []api.Message{
-			{Role: "system", Content: config.DefaultSystemMessage},
+		app.newConversation(messages, session)
+		fmt.Println("Conversation cleared.")
+
+	case "/compact":
+		app.handleCompactCommand(parts, messages, *client, session)
+
+	case "/image":
+		app.handleImageCommand(parts, session)
+
+	case "/retry":
+		if session != nil {
+			session.handleRetryCommand()
 		}
-		// Start a new conversation ID when clearing
+
+	case "/continue":
 		if session != nil {
-			session.conversationID = uuid.New().String()
+			session.handleContinueCommand()
 		}
-		fmt.Println("Conversation cleared.")
 
 	case "/help", "/h":
 		app.showHelp()
@@ -46,19 +59,24 @@ func (app *App) handleCommand(input string, messages *[]api.Message, client *api
 		app.showHistory(session)
 
 	case "/resume":
-		app.resumeConversation(session, messages)
+		ref := ""
+		if len(parts) > 1 {
+			ref = parts[1]
+		}
+		app.resumeConversation(session, messages, ref)
 
 	case "/model":
 		app.handleModelCommand(parts)
 
 	case "/provider":
 		if app.handleProviderCommand(parts, client) {
-			// Provider switched, clear conversation history
-			*messages = []api.Message{
-				{Role: "system", Content: config.DefaultSystemMessage},
-			}
+			// Provider switched; the saved conversation stays as it was
+			app.newConversation(messages, session)
 		}
 
+	case "/set":
+		app.handleSetCommand(parts)
+
 	case "/web":
 		app.handleWebCommand(parts, messages, *client, exec, session)
 
@@ -92,6 +110,11 @@ func (app *App) handleCommand(input string, messages *[]api.Message, client *api
 	case "/plan":
 		app.handlePlanCommand(session)
 
+	case "/export":
+		if session != nil {
+			session.handleExportCommand(parts)
+		}
+
 	default:
 		fmt.Printf("Unknown command: %s\n", cmd)
 		fmt.Println("Type /help for available commands")
@@ -105,16 +128,25 @@ func (app *App) showHelp() {
 	fmt.Println("\nCommands:")
 	fmt.Printf("  %-24s %s\n", "/exit, /quit, /q", "Exit interactive mode")
 	fmt.Printf("  %-24s %s\n", "/clear, /c", "Clear conversation history")
+	fmt.Printf("  %-24s %s\n", "/compact [instructions]", "Summarize older turns to free context")
+	fmt.Printf("  %-24s %s\n", "/image <path>", "Attach an image to the next message")
+	fmt.Printf("  %-24s %s\n", "/image clear", "Drop attached images")
+	fmt.Printf("  %-24s %s\n", "/retry", "Send the last message again")
+	fmt.Printf("  %-24s %s\n", "/continue", "Continue an interrupted response")
 	fmt.Printf("  %-24s %s\n", "/history", "Show recent conversations")
-	fmt.Printf("  %-24s %s\n", "/resume", "Resume last conversation")
+	fmt.Printf("  %-24s %s\n", "/resume [number|id]", "Resume a conversation (picker without argument)")
+	fmt.Printf("  %-24s %s\n", "/export [md|html|json] [path]", "Export the conversation (--redact hides secrets)")
 	fmt.Printf("  %-24s %s\n", "/web <query>", "Search web and ask about results")
 	fmt.Printf("  %-24s %s\n", "/web on", "Enable auto web search for all messages")
 	fmt.Printf("  %-24s %s\n", "/web off", "Disable auto web search")
 	fmt.Printf("  %-24s %s\n", "/web <provider>", "Switch provider (tavily, linkup, brave)")
 	fmt.Printf("  %-24s %s\n", "/model <name>", "Switch model")
 	fmt.Printf("  %-24s %s\n", "/model", "Show current model")
-	fmt.Printf("  %-24s %s\n", "/provider <name>", "Switch AI provider (copilot, azure)")
+	fmt.Printf("  %-24s %s\n", "/provider <name>", "Switch AI provider (copilot, azure, openai, anthropic)")
 	fmt.Printf("  %-24s %s\n", "/provider", "Show current provider")
+	fmt.Printf("  %-24s %s\n", "/set <param> <value>", "Set temperature, top_p, max_tokens, seed, stop, or reasoning_effort")
+	fmt.Printf("  %-24s %s\n", "/set stop +<sequence>", "Add a stop sequence instead of replacing them")
+	fmt.Printf("  %-24s %s\n", "/set", "Show sampling parameters")
 	fmt.Println()
 	fmt.Println("Git commands:")
 	fmt.Printf("  %-24s %s\n", "/diff", "Show current git changes (staged/unstaged)")
@@ -139,7 +171,7 @@ func (app *App) showHistory(session *InteractiveSession) {
 		return
 	}
 
-	conversations := session.history.GetRecentConversations(10)
+	conversations := session.history.Newest(pickerSize)
 	if len(conversations) == 0 {
 		fmt.Println("No conversation history.")
 		return
@@ -147,76 +179,52 @@ func (app *App) showHistory(session *InteractiveSession) {
 
 	fmt.Println("\nRecent conversations:")
 	for i, conv := range conversations {
-		msgCount := len(conv.Messages) - 1 // Exclude system message
-		if msgCount < 0 {
-			msgCount = 0
-		}
-		fmt.Printf("  %d. [%s] %s - %s (%d messages)\n",
-			i+1,
-			conv.UpdatedAt.Format("2006-01-02 15:04"),
-			conv.Provider,
-			conv.Model,
-			msgCount,
-		)
+		fmt.Println(formatConversation(i+1, conv))
 	}
+	fmt.Println("\nUse /resume <number|id> to continue one.")
 	fmt.Println()
 }
 
-// resumeConversation resumes the last conversation from history.
-func (app *App) resumeConversation(session *InteractiveSession, messages *[]api.Message) {
+// resumeConversation resumes a conversation from history: the one ref
+// names (a /history number, ID or ID prefix), or one picked interactively.
+func (app *App) resumeConversation(session *InteractiveSession, messages *[]api.Message, ref string) {
 	if session == nil || session.history == nil {
 		fmt.Println("History not available.")
 		return
 	}
-
-	lastConv := session.history.GetLastConversation()
-	if lastConv == nil {
+	if len(session.history.Conversations) == 0 {
 		fmt.Println("No conversation to resume.")
 		return
 	}
 
-	*messages = make([]api.Message, len(lastConv.Messages))
-	copy(*messages, lastConv.Messages)
-	session.conversationID = lastConv.ID
-	msgCount := len(lastConv.Messages) - 1
-	if msgCount < 0 {
-		msgCount = 0
-	}
-	fmt.Printf("Resumed conversation from %s (%d messages)\n\n",
-		lastConv.UpdatedAt.Format("2006-01-02 15:04"),
-		msgCount,
-	)
-
-	// Display the conversation history
-	for _, msg := range lastConv.Messages {
-		// Skip system messages
-		if msg.Role == "system" {
-			continue
-		}
-
-		// Display user messages
-		if msg.Role == "user" {
-			fmt.Printf("👤 You:\n%s\n\n", msg.Content)
-		}
-
-		// Display assistant messages
-		if msg.Role == "assistant" && msg.Content != "" {
-			fmt.Printf("🤖 Assistant:\n")
-			if app.cfg.Render {
-				display.ShowContentRendered(msg.Content)
-			} else {
-				display.ShowContent(msg.Content)
-			}
-			fmt.Println()
+	var conv *history.ConversationEntry
+	if ref = strings.TrimSpace(ref); ref != "" {
+		var err error
+		if conv, err = session.history.Resolve(ref); err != nil {
+			display.ShowError(err.Error())
+			return
 		}
-
-		// Display tool messages (if any)
-		if msg.Role == "tool" && msg.Content != "" {
-			fmt.Printf("🔧 Tool Result:\n%s\n\n", msg.Content)
+	} else {
+		fmt.Println("\nRecent conversations:")
+		if conv = pickConversation(session.history.Newest(0), bufio.NewReader(os.Stdin), os.Stdout); conv == nil {
+			fmt.Println("Cancelled.")
+			return
 		}
+		fmt.Println()
 	}
 
-	fmt.Println("--- End of conversation history ---\n")
+	*messages = make([]api.Message, len(conv.Messages))
+	copy(*messages, conv.Messages)
+	session.setConversation(conv.ID)
+	session.sources = maps.Clone(conv.Sources)
+	fmt.Printf("Resumed conversation from %s (%d messages)\n\n",
+		conv.UpdatedAt.Format("2006-01-02 15:04"),
+		conv.MessageCount(),
+	)
+
+	app.showConversation(*conv)
+	fmt.Println("--- End of conversation history ---")
+	fmt.Println()
 }
 
 // handleModelCommand processes the /model command to show or switch models.
@@ -224,10 +232,7 @@ func (app *App) handleModelCommand(parts []string) {
 	if len(parts) > 1 {
 		newModel := strings.TrimSpace(parts[1])
 		if newModel == "" {
-			fmt.Printf("Current model: %s\n", app.cfg.Model)
-			if len(app.cfg.AvailableModels) > 0 {
-				fmt.Printf("Available: %s\n", app.cfg.GetAvailableModelsString())
-			}
+			app.showCurrentModel()
 		} else if len(app.cfg.AvailableModels) > 0 && !app.cfg.ValidateModel(newModel) {
 			fmt.Printf("Invalid model: %s\n", newModel)
 			fmt.Printf("Available: %s\n", app.cfg.GetAvailableModelsString())
@@ -236,10 +241,29 @@ func (app *App) handleModelCommand(parts []string) {
 			fmt.Printf("Switched to model: %s\n", app.cfg.Model)
 		}
 	} else {
-		fmt.Printf("Current model: %s\n", app.cfg.Model)
-		if len(app.cfg.AvailableModels) > 0 {
-			fmt.Printf("Available: %s\n", app.cfg.GetAvailableModelsString())
-		}
+		app.showCurrentModel()
+	}
+}
+
+// showCurrentModel prints the current model and what else is available
+func (app *App) showCurrentModel() {
+	fmt.Printf("Current model: %s\n", app.cfg.Model)
+	if len(app.models) > 0 {
+		app.showModels()
+	} else if len(app.cfg.AvailableModels) > 0 {
+		fmt.Printf("Available: %s\n", app.cfg.GetAvailableModelsString())
+	}
+}
+
+// newConversation clears messages and starts a new conversation ID, so the
+// next save doesn't overwrite the conversation saved so far
+func (app *App) newConversation(messages *[]api.Message, session *InteractiveSession) {
+	*messages = []api.Message{
+		{Role: "system", Content: config.DefaultSystemMessage},
+	}
+	if session != nil {
+		session.setConversation(uuid.New().String())
+		session.interrupted = false
 	}
 }
 
@@ -250,13 +274,15 @@ func (app *App) handleProviderCommand(parts []string, client *api.AIClient) bool
 		newProvider := strings.ToLower(strings.TrimSpace(parts[1]))
 		if newProvider == "" {
 			fmt.Printf("Current provider: %s\n", app.getProviderName())
-			fmt.Println("Available: copilot, azure")
+			fmt.Println("Available: copilot, azure, openai, anthropic")
 			return false
 		}
 
-		if newProvider != "copilot" && newProvider != "azure" && newProvider != "github" {
+		switch newProvider {
+		case "copilot", "github", "azure", "openai", "anthropic":
+		default:
 			fmt.Printf("Invalid provider: %s\n", newProvider)
-			fmt.Println("Available: copilot, azure")
+			fmt.Println("Available: copilot, azure, openai, anthropic")
 			return false
 		}
 
@@ -272,8 +298,14 @@ func (app *App) handleProviderCommand(parts []string, client *api.AIClient) bool
 			return false
 		}
 
+		// An explicit switch pins the provider, bypassing any fallback chain
+		if len(app.cfg.FallbackChain) > 0 {
+			app.cfg.FallbackChain = nil
+			fmt.Println("  Fallback chain disabled for this session")
+		}
+
 		// Recreate client with new provider
-		newClient, err := api.NewClient(app.cfg)
+		newClient, err := app.newClient()
 		if err != nil {
 			display.ShowError(fmt.Sprintf("Failed to switch provider: %v", err))
 			return false
@@ -283,6 +315,13 @@ func (app *App) handleProviderCommand(parts []string, client *api.AIClient) bool
 		(*client).Close()
 		*client = newClient
 
+		app.models = nil
+		api.Capabilities.SetLive(nil)
+		app.loadModels(newClient, false)
+		if !app.cfg.ValidateModel(app.cfg.Model) {
+			app.cfg.Model = app.cfg.AvailableModels[0]
+		}
+
 		fmt.Printf("✓ Switched to %s\n", app.getProviderName())
 		fmt.Printf("  Model: %s\n", app.cfg.Model)
 		fmt.Printf("  Available models: %s\n", app.cfg.GetAvailableModelsString())
@@ -291,7 +330,7 @@ func (app *App) handleProviderCommand(parts []string, client *api.AIClient) bool
 	}
 
 	fmt.Printf("Current provider: %s\n", app.getProviderName())
-	fmt.Println("Available: copilot, azure")
+	fmt.Println("Available: copilot, azure, openai, anthropic")
 	return false
 }
 
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/quocvuong92/ai-cli/internal/api"
+	"github.com/quocvuong92/ai-cli/internal/display"
+	"github.com/quocvuong92/ai-cli/internal/jsonschema"
+)
+
+// schemaNamePattern matches characters not allowed in a json_schema name
+var schemaNamePattern = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// runStructured asks for JSON matching the --json-schema file and writes
+// only the raw, validated JSON to stdout so it can be piped into jq.
+func (app *App) runStructured(client api.AIClient, systemPrompt, userMessage string) {
+	schema, err := jsonschema.Load(app.cfg.JSONSchemaFile)
+	if err != nil {
+		app.fail(err.Error())
+	}
+
+	content, err := app.completeStructured(context.Background(), client, schema, app.promptMessages(systemPrompt, userMessage))
+	if err != nil {
+		app.fail(err.Error())
+	}
+	if app.machineOutput() {
+		app.writeResult(content)
+		return
+	}
+	fmt.Println(content)
+}
+
+// completeStructured sends messages with a response_format for schema,
+// validates the answer locally and re-prompts with the validation errors
+// until it passes or the retry budget is spent.
+func (app *App) completeStructured(ctx context.Context, client api.AIClient, schema *jsonschema.Schema, messages []api.Message) (string, error) {
+	req := app.newRequest(messages, nil)
+	req.ResponseFormat = &api.ResponseFormat{
+		Type: api.ResponseFormatJSONSchema,
+		JSONSchema: &api.JSONSchema{
+			Name:   schemaName(schema),
+			Schema: schema.Raw(),
+		},
+	}
+
+	var errs []string
+	for attempt := 0; attempt <= app.cfg.JSONSchemaRetries; attempt++ {
+		content, err := app.completeBuffered(ctx, client, req)
+
+		// Not every server supports json_schema; fall back to json_object
+		// and rely on local validation
+		var apiErr *api.APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusBadRequest &&
+			req.ResponseFormat.Type == api.ResponseFormatJSONSchema {
+			log.Printf("json_schema rejected (%v), retrying with json_object", err)
+			req.ResponseFormat = &api.ResponseFormat{Type: api.ResponseFormatJSONObject}
+			content, err = app.completeBuffered(ctx, client, req)
+		}
+		if err != nil {
+			return "", err
+		}
+
+		content = extractJSON(content)
+		errs = schema.Validate([]byte(content))
+		if len(errs) == 0 {
+			return content, nil
+		}
+
+		log.Printf("Attempt %d failed schema validation: %v", attempt+1, errs)
+		req.Messages = append(req.Messages,
+			api.Message{Role: "assistant", Content: content},
+			api.Message{Role: "user", Content: schemaFeedback(errs)},
+		)
+	}
+
+	return "", fmt.Errorf("response did not match the JSON schema after %d attempts:\n  %s",
+		app.cfg.JSONSchemaRetries+1, strings.Join(errs, "\n  "))
+}
+
+// completeBuffered sends req and returns the full response content without
+// printing it, streaming under the hood when --stream is set
+func (app *App) completeBuffered(ctx context.Context, client api.AIClient, req api.Request) (string, error) {
+	if !app.machineOutput() {
+		sp := display.NewSpinner("Waiting for response...")
+		sp.Start()
+		defer sp.Stop()
+	}
+
+	if !app.cfg.Stream {
+		resp, err := client.Complete(ctx, req)
+		if err != nil {
+			return "", err
+		}
+		return resp.GetContent(), nil
+	}
+
+	var content strings.Builder
+	err := client.Stream(ctx, req, api.StreamHandler{
+		OnChunk: func(chunk string) {
+			content.WriteString(chunk)
+		},
+	})
+	return content.String(), err
+}
+
+// schemaName derives a json_schema name from the schema title
+func schemaName(schema *jsonschema.Schema) string {
+	name := schemaNamePattern.ReplaceAllString(schema.Title(), "_")
+	name = strings.Trim(name, "_")
+	if name == "" {
+		return "response"
+	}
+	return name
+}
+
+// extractJSON trims whitespace and a surrounding Markdown code fence,
+// which models sometimes add even when asked for raw JSON
+func extractJSON(content string) string {
+	content = strings.TrimSpace(content)
+	if !strings.HasPrefix(content, "```") {
+		return content
+	}
+	if i := strings.Index(content, "\n"); i >= 0 {
+		content = content[i+1:]
+	} else {
+		return content
+	}
+	content = strings.TrimSuffix(strings.TrimSpace(content), "```")
+	return strings.TrimSpace(content)
+}
+
+// schemaFeedback builds the re-prompt sent after a validation failure
+func schemaFeedback(errs []string) string {
+	var b strings.Builder
+	b.WriteString("Your response does not match the required JSON schema:\n")
+	for _, e := range errs {
+		b.WriteString("- ")
+		b.WriteString(e)
+		b.WriteString("\n")
+	}
+	b.WriteString("Respond again with only the corrected JSON, without any explanation or code fences.")
+	return b.String()
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/quocvuong92/ai-cli/internal/api"
+	"github.com/quocvuong92/ai-cli/internal/config"
+	"github.com/quocvuong92/ai-cli/internal/jsonschema"
+)
+
+// scriptedProvider returns canned replies in order and records requests
+type scriptedProvider struct {
+	replies  []string
+	errs     []error
+	requests []api.Request
+}
+
+func (p *scriptedProvider) Complete(ctx context.Context, r api.Request) (*api.ChatResponse, error) {
+	i := len(p.requests)
+	p.requests = append(p.requests, r)
+	if i < len(p.errs) && p.errs[i] != nil {
+		return nil, p.errs[i]
+	}
+	reply := ""
+	if i < len(p.replies) {
+		reply = p.replies[i]
+	}
+	return &api.ChatResponse{Choices: []api.Choice{{Message: api.Message{Role: "assistant", Content: reply}}}}, nil
+}
+
+func (p *scriptedProvider) Stream(ctx context.Context, r api.Request, handler api.StreamHandler) error {
+	resp, err := p.Complete(ctx, r)
+	if err != nil {
+		return err
+	}
+	handler.OnChunk(resp.GetContent())
+	if handler.OnDone != nil {
+		handler.OnDone(resp)
+	}
+	return nil
+}
+
+func TestCompleteStructured(t *testing.T) {
+	schema, err := jsonschema.Parse([]byte(`{"title":"Person record","type":"object","properties":{"age":{"type":"integer"}},"required":["age"]}`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	provider := &scriptedProvider{replies: []string{
+		`{"age":"thirty"}`,
+		"```json\n{\"age\":30}\n```",
+	}}
+	app := &App{cfg: &config.Config{JSONSchemaRetries: 2}}
+
+	got, err := app.completeStructured(context.Background(), api.NewClientAdapter(provider), schema,
+		[]api.Message{{Role: "user", Content: "extract"}})
+	if err != nil {
+		t.Fatalf("completeStructured() error = %v", err)
+	}
+	if got != `{"age":30}` {
+		t.Errorf("completeStructured() = %q, want %q", got, `{"age":30}`)
+	}
+
+	if len(provider.requests) != 2 {
+		t.Fatalf("sent %d requests, want 2", len(provider.requests))
+	}
+	first := provider.requests[0]
+	if first.ResponseFormat == nil || first.ResponseFormat.JSONSchema == nil || first.ResponseFormat.JSONSchema.Name != "Person_record" {
+		t.Errorf("ResponseFormat = %+v, want json_schema named Person_record", first.ResponseFormat)
+	}
+	retry := provider.requests[1].Messages
+	if len(retry) != 3 || !strings.Contains(retry[2].Content, "$.age: expected integer, got string") {
+		t.Errorf("re-prompt messages = %+v, want validation errors fed back", retry)
+	}
+}
+
+func TestCompleteStructured_FallsBackToJSONObject(t *testing.T) {
+	schema, _ := jsonschema.Parse([]byte(`{"type":"object"}`))
+	provider := &scriptedProvider{
+		errs:    []error{&api.APIError{StatusCode: 400, Message: "response_format json_schema not supported"}},
+		replies: []string{"", `{}`},
+	}
+	app := &App{cfg: &config.Config{Stream: true}}
+
+	got, err := app.completeStructured(context.Background(), api.NewClientAdapter(provider), schema, nil)
+	if err != nil {
+		t.Fatalf("completeStructured() error = %v", err)
+	}
+	if got != "{}" || provider.requests[1].ResponseFormat.Type != api.ResponseFormatJSONObject {
+		t.Errorf("got %q with format %+v, want {} via json_object", got, provider.requests[1].ResponseFormat)
+	}
+}
+
+func TestCompleteStructured_RetriesExhausted(t *testing.T) {
+	schema, _ := jsonschema.Parse([]byte(`{"type":"array"}`))
+	provider := &scriptedProvider{replies: []string{`{}`, `{}`}}
+	app := &App{cfg: &config.Config{JSONSchemaRetries: 1}}
+
+	_, err := app.completeStructured(context.Background(), api.NewClientAdapter(provider), schema, nil)
+	if err == nil || !strings.Contains(err.Error(), "after 2 attempts") {
+		t.Errorf("completeStructured() error = %v, want failure after 2 attempts", err)
+	}
+}
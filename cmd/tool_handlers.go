@@ -56,7 +56,7 @@ func (app *App) handleExecuteCommand(tc api.ToolCall, exec *executor.Executor, c
 
 	// Ask for confirmation if needed
 	if needsConfirm {
-		choice := display.AskCommandConfirmationExtended(args.Command, args.Reasoning)
+		choice := app.confirmCommand(args.Command, args.Reasoning)
 		if choice == display.ApprovalDenied {
 			return "Command execution denied by user"
 		}
@@ -126,7 +126,7 @@ func (app *App) handleWriteFile(tc api.ToolCall) string {
 	display.ShowFileOperation("write", args.Path)
 	fmt.Fprintf(os.Stderr, "Content length: %d bytes\n", len(args.Content))
 
-	choice := display.AskFileConfirmation("write", args.Path)
+	choice := app.confirmFile("write", args.Path)
 	if choice == display.ApprovalDenied {
 		return "Write denied by user"
 	}
@@ -158,7 +158,7 @@ func (app *App) handleEditFile(tc api.ToolCall) string {
 	display.ShowDiff(args.Path, diff)
 
 	// Ask for confirmation
-	choice := display.AskFileConfirmation("edit", args.Path)
+	choice := app.confirmFile("edit", args.Path)
 	if choice == display.ApprovalDenied {
 		return "Edit denied by user"
 	}
@@ -235,7 +235,7 @@ func (app *App) handleDeleteFile(tc api.ToolCall, exec *executor.Executor) strin
 	display.ShowFileOperation("delete", args.Path)
 
 	// Always ask for confirmation for delete
-	choice := display.AskFileConfirmation("delete", args.Path)
+	choice := app.confirmFile("delete", args.Path)
 	if choice == display.ApprovalDenied {
 		return "Delete denied by user"
 	}
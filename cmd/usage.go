@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/quocvuong92/ai-cli/internal/api"
+	"github.com/quocvuong92/ai-cli/internal/display"
+	"github.com/quocvuong92/ai-cli/internal/usage"
+)
+
+// Commands recorded with each usage ledger entry
+const (
+	usageCommandQuery       = "query"
+	usageCommandInteractive = "interactive"
+	usageCommandAgent       = "agent"
+	usageCommandServe       = "serve"
+	usageCommandReplay      = "replay"
+)
+
+// usageFlags holds the usage command's flag values
+type usageFlags struct {
+	since string
+	by    string
+}
+
+// newUsageCmd creates the usage command
+func (app *App) newUsageCmd() *cobra.Command {
+	var flags usageFlags
+
+	cmd := &cobra.Command{
+		Use:   "usage",
+		Short: "Summarize token usage from the local ledger",
+		Long: `Summarize the token usage recorded in ~/.local/share/ai-cli/usage.jsonl.
+
+Every completed response is recorded with its provider, model, command and
+conversation. Set per-model prices under usage.prices in the config file to
+estimate spend.
+
+Examples:
+  ai-cli usage
+  ai-cli usage --since 7d --by model
+  ai-cli usage --since 2026-01-01 --by day`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			app.runUsage(flags)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&app.verbose, "verbose", "v", false, "Enable debug mode")
+	cmd.Flags().StringVar(&flags.since, "since", "30d", "Only include usage since a duration ago (7d, 12h) or a date (2026-01-31); empty for all")
+	cmd.Flags().StringVar(&flags.by, "by", usage.ByModel, "Group by: "+strings.Join(usage.Groupings, ", "))
+
+	return cmd
+}
+
+// runUsage prints the usage report
+func (app *App) runUsage(flags usageFlags) {
+	app.setupLogging()
+	if err := app.cfg.Validate(); err != nil {
+		log.Printf("Config validation warning: %v", err)
+	}
+
+	since, err := usage.ParseSince(flags.since, time.Now())
+	if err != nil {
+		display.ShowError(err.Error())
+		os.Exit(1)
+	}
+	entries, err := usage.NewLedger().Load(since)
+	if err != nil {
+		display.ShowError(err.Error())
+		os.Exit(1)
+	}
+	rows, total, err := usage.Summarize(entries, flags.by, app.cfg.ModelPrices)
+	if err != nil {
+		display.ShowError(err.Error())
+		os.Exit(1)
+	}
+
+	if len(rows) == 0 {
+		fmt.Println("No usage recorded in this period.")
+		return
+	}
+
+	priced := len(app.cfg.ModelPrices) > 0
+	writeUsageTable(os.Stdout, flags.by, append(rows, total), priced)
+	if priced && total.Unpriced > 0 {
+		fmt.Printf("\n%d of %d requests have no price in usage.prices and are not in the cost.\n", total.Unpriced, total.Requests)
+	}
+}
+
+// minUsageKeyWidth is the narrowest the key column of the report gets
+const minUsageKeyWidth = 24
+
+// writeUsageTable writes the report rows, with the key column as wide as
+// the longest key (conversation IDs are 36 characters)
+func writeUsageTable(w io.Writer, by string, rows []usage.Row, priced bool) {
+	width := max(minUsageKeyWidth, len(by))
+	for _, row := range rows {
+		width = max(width, len(row.Key))
+	}
+
+	header := fmt.Sprintf("%-*s %8s %10s %10s %10s", width, strings.ToUpper(by), "REQUESTS", "INPUT", "OUTPUT", "TOTAL")
+	if priced {
+		header += fmt.Sprintf(" %10s", "COST")
+	}
+	fmt.Fprintln(w, header)
+	for _, row := range rows {
+		line := fmt.Sprintf("%-*s %8d %10d %10d %10d", width, row.Key, row.Requests, row.PromptTokens, row.CompletionTokens, row.TotalTokens)
+		if priced {
+			line += fmt.Sprintf(" %10s", formatCost(row))
+		}
+		fmt.Fprintln(w, line)
+	}
+}
+
+// formatCost formats a row's estimated cost; "-" when nothing in it is priced
+func formatCost(row usage.Row) string {
+	if row.Unpriced == row.Requests {
+		return "-"
+	}
+	cost := fmt.Sprintf("$%.2f", row.Cost)
+	if row.Unpriced > 0 {
+		cost += "*"
+	}
+	return cost
+}
+
+// recordUsage appends the usage of a completed response to the ledger,
+// attributed to the fallback backend that answered when a chain is configured
+func (app *App) recordUsage(ledger *usage.Ledger, r api.Request, resp *api.ChatResponse) {
+	provider, model := app.providerID(), app.cfg.Model
+	if r.Model != "" {
+		model = r.Model
+	}
+	if resp.Backend != "" {
+		var backendModel string
+		provider, backendModel, _ = strings.Cut(resp.Backend, ":")
+		if backendModel != "" {
+			model = backendModel
+		}
+	}
+
+	command := app.usageCommand
+	if command == "" {
+		command = usageCommandQuery
+	}
+	err := ledger.Append(usage.Entry{
+		Time:             time.Now(),
+		Provider:         provider,
+		Model:            model,
+		ConversationID:   app.conversationID,
+		Command:          command,
+		PromptTokens:     resp.Usage.PromptTokens,
+		CompletionTokens: resp.Usage.CompletionTokens,
+		ReasoningTokens:  resp.Usage.ReasoningTokens(),
+		TotalTokens:      resp.Usage.TotalTokens,
+	})
+	if err != nil {
+		log.Printf("Failed to record usage: %v", err)
+	}
+}
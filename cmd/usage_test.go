@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/quocvuong92/ai-cli/internal/usage"
+)
+
+func TestWriteUsageTable_AlignsLongKeys(t *testing.T) {
+	rows := []usage.Row{
+		{Key: "3f9a1c2e-5b7d-4e8f-9a0b-1c2d3e4f5a6b", Requests: 2, TotalTokens: 120},
+		{Key: "short", Requests: 1, TotalTokens: 7},
+		{Key: "TOTAL", Requests: 3, TotalTokens: 127},
+	}
+	var b strings.Builder
+	writeUsageTable(&b, "conversation", rows, false)
+
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("got %d lines, want 4:\n%s", len(lines), b.String())
+	}
+	col := strings.Index(lines[0], "REQUESTS") + len("REQUESTS")
+	for _, line := range lines {
+		if len(line) != len(lines[0]) {
+			t.Errorf("line %q is %d wide, want %d", line, len(line), len(lines[0]))
+		}
+		if line[col-1] == ' ' {
+			t.Errorf("line %q: REQUESTS column not right-aligned at %d", line, col)
+		}
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/quocvuong92/ai-cli/internal/api"
 	"github.com/quocvuong92/ai-cli/internal/display"
 	"github.com/quocvuong92/ai-cli/internal/executor"
+	"github.com/quocvuong92/ai-cli/internal/history"
 )
 
 func (app *App) optimizeSearchQuery(query string, messages []api.Message, client api.AIClient) (string, error) {
@@ -124,6 +125,8 @@ func (app *App) handleWebSearch(query string, messages *[]api.Message, client ap
 		// If no response, add it manually
 		*messages = append(*messages, api.Message{Role: "assistant", Content: response})
 	}
+	session.recordSources(app.searchResults)
+	session.saveHistory()
 
 	// Show citations if enabled
 	if app.cfg.Citations && app.searchResults != nil && len(app.searchResults.Results) > 0 {
@@ -196,3 +199,20 @@ func (app *App) performWebSearch(query string) (string, error) {
 func buildWebSearchPrompt(searchContext string) string {
 	return fmt.Sprintf(WebSearchPromptTemplate, searchContext)
 }
+
+// recordSources keeps the search results the last reply may cite, so
+// exports can link its [1], [2], ... citations
+func (s *InteractiveSession) recordSources(results *api.TavilyResponse) {
+	last := len(s.messages) - 1
+	if results == nil || len(results.Results) == 0 || last < 0 || s.messages[last].Role != "assistant" {
+		return
+	}
+	sources := make([]history.Source, len(results.Results))
+	for i, r := range results.Results {
+		sources[i] = history.Source{Title: r.Title, URL: r.URL}
+	}
+	if s.sources == nil {
+		s.sources = make(map[string][]history.Source)
+	}
+	s.sources[history.ReplyKey(s.messages[last].Content)] = sources
+}
@@ -0,0 +1,80 @@
+package api
+
+import "context"
+
+// ClientAdapter implements the convenience methods of AIClient on top of a
+// Provider's Complete and Stream. Built-in clients embed it; third-party
+// providers can be turned into an AIClient with NewClientAdapter.
+type ClientAdapter struct {
+	Provider
+}
+
+// NewClientAdapter wraps a Provider so it satisfies AIClient
+func NewClientAdapter(p Provider) *ClientAdapter {
+	return &ClientAdapter{Provider: p}
+}
+
+// Query sends a simple query (non-streaming)
+func (a ClientAdapter) Query(systemPrompt, userMessage string) (*ChatResponse, error) {
+	return a.QueryWithContext(context.Background(), systemPrompt, userMessage)
+}
+
+// QueryWithContext sends a query with context support (non-streaming)
+func (a ClientAdapter) QueryWithContext(ctx context.Context, systemPrompt, userMessage string) (*ChatResponse, error) {
+	return a.Complete(ctx, Request{Messages: promptMessages(systemPrompt, userMessage)})
+}
+
+// QueryWithHistory sends a query with full message history (non-streaming)
+func (a ClientAdapter) QueryWithHistory(messages []Message) (*ChatResponse, error) {
+	return a.Complete(context.Background(), Request{Messages: messages})
+}
+
+// QueryWithHistoryContext sends a query with full message history and context support (non-streaming)
+func (a ClientAdapter) QueryWithHistoryContext(ctx context.Context, messages []Message) (*ChatResponse, error) {
+	return a.Complete(ctx, Request{Messages: messages})
+}
+
+// QueryWithHistoryAndToolsContext sends a query with full message history, tools, and context support (non-streaming)
+func (a ClientAdapter) QueryWithHistoryAndToolsContext(ctx context.Context, messages []Message, tools []Tool) (*ChatResponse, error) {
+	return a.Complete(ctx, Request{Messages: messages, Tools: tools})
+}
+
+// QueryStream sends a streaming query
+func (a ClientAdapter) QueryStream(systemPrompt, userMessage string, onChunk func(content string), onDone func(resp *ChatResponse)) error {
+	return a.QueryStreamWithContext(context.Background(), systemPrompt, userMessage, onChunk, onDone)
+}
+
+// QueryStreamWithContext sends a streaming query with context support
+func (a ClientAdapter) QueryStreamWithContext(ctx context.Context, systemPrompt, userMessage string, onChunk func(content string), onDone func(resp *ChatResponse)) error {
+	return a.Stream(ctx, Request{Messages: promptMessages(systemPrompt, userMessage)}, StreamHandler{OnChunk: onChunk, OnDone: onDone})
+}
+
+// QueryStreamWithHistory sends a streaming query with full message history
+func (a ClientAdapter) QueryStreamWithHistory(messages []Message, onChunk func(content string), onDone func(resp *ChatResponse)) error {
+	return a.Stream(context.Background(), Request{Messages: messages}, StreamHandler{OnChunk: onChunk, OnDone: onDone})
+}
+
+// QueryStreamWithHistoryContext sends a streaming query with full message history and context support
+func (a ClientAdapter) QueryStreamWithHistoryContext(ctx context.Context, messages []Message, onChunk func(content string), onDone func(resp *ChatResponse)) error {
+	return a.Stream(ctx, Request{Messages: messages}, StreamHandler{OnChunk: onChunk, OnDone: onDone})
+}
+
+// QueryStreamWithHistoryAndToolsContext sends a streaming query with full message history, tools, and context support
+func (a ClientAdapter) QueryStreamWithHistoryAndToolsContext(ctx context.Context, messages []Message, tools []Tool, onChunk func(content string), onDone func(resp *ChatResponse)) error {
+	return a.Stream(ctx, Request{Messages: messages, Tools: tools}, StreamHandler{OnChunk: onChunk, OnDone: onDone})
+}
+
+// Close closes the wrapped provider if it holds resources
+func (a ClientAdapter) Close() {
+	if closer, ok := a.Provider.(interface{ Close() }); ok {
+		closer.Close()
+	}
+}
+
+// promptMessages builds the system + user message pair used by the simple query methods
+func promptMessages(systemPrompt, userMessage string) []Message {
+	return []Message{
+		{Role: "system", Content: systemPrompt},
+		{Role: "user", Content: userMessage},
+	}
+}
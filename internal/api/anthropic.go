@@ -0,0 +1,640 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/quocvuong92/ai-cli/internal/config"
+)
+
+// Anthropic API constants
+const (
+	AnthropicVersion = "2023-06-01"
+	// AnthropicDefaultMaxTokens is sent as max_tokens, which the Messages API requires
+	AnthropicDefaultMaxTokens = 8192
+	// anthropicMinThinkingBudget is the smallest thinking budget the Messages API accepts
+	anthropicMinThinkingBudget = 1024
+)
+
+// anthropicThinkingBudgets maps a reasoning effort to an extended thinking budget
+var anthropicThinkingBudgets = map[string]int{
+	"low":    2048,
+	"medium": 8192,
+	"high":   24576,
+}
+
+// anthropicRequest represents the Messages API request
+type anthropicRequest struct {
+	Model         string               `json:"model"`
+	System        string               `json:"system,omitempty"`
+	Messages      []anthropicMessage   `json:"messages"`
+	Tools         []anthropicTool      `json:"tools,omitempty"`
+	ToolChoice    *anthropicToolChoice `json:"tool_choice,omitempty"`
+	MaxTokens     int                  `json:"max_tokens"`
+	Temperature   *float64             `json:"temperature,omitempty"`
+	TopP          *float64             `json:"top_p,omitempty"`
+	StopSequences []string             `json:"stop_sequences,omitempty"`
+	Stream        bool                 `json:"stream,omitempty"`
+	Thinking      *anthropicThinking   `json:"thinking,omitempty"`
+}
+
+// anthropicThinking enables extended thinking with a token budget
+type anthropicThinking struct {
+	Type         string `json:"type"`
+	BudgetTokens int    `json:"budget_tokens"`
+}
+
+// anthropicToolChoice is the Anthropic form of tool_choice ("auto", "any", "none" or "tool")
+type anthropicToolChoice struct {
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"`
+}
+
+// anthropicMessage is a single turn made of content blocks
+type anthropicMessage struct {
+	Role    string                  `json:"role"`
+	Content []anthropicContentBlock `json:"content"`
+}
+
+// anthropicContentBlock covers the text, image, tool_use and tool_result block types
+type anthropicContentBlock struct {
+	Type      string                `json:"type"`
+	Text      string                `json:"text,omitempty"`
+	Source    *anthropicImageSource `json:"source,omitempty"`
+	ID        string                `json:"id,omitempty"`
+	Name      string                `json:"name,omitempty"`
+	Input     json.RawMessage       `json:"input,omitempty"`
+	ToolUseID string                `json:"tool_use_id,omitempty"`
+	Content   string                `json:"content,omitempty"`
+	Thinking  string                `json:"thinking,omitempty"`
+}
+
+// anthropicImageSource is the base64 payload of an image block
+type anthropicImageSource struct {
+	Type      string `json:"type"`
+	MediaType string `json:"media_type"`
+	Data      string `json:"data"`
+}
+
+// anthropicTool is a tool definition in Anthropic format
+type anthropicTool struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	InputSchema interface{} `json:"input_schema"`
+}
+
+// anthropicUsage represents Anthropic token usage
+type anthropicUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+// anthropicResponse represents the non-streaming Messages API response
+type anthropicResponse struct {
+	ID         string                  `json:"id"`
+	Content    []anthropicContentBlock `json:"content"`
+	StopReason string                  `json:"stop_reason"`
+	Usage      anthropicUsage          `json:"usage"`
+}
+
+// anthropicErrorResponse represents an Anthropic API error
+type anthropicErrorResponse struct {
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// AnthropicClient is the Anthropic Messages API client
+type AnthropicClient struct {
+	ClientAdapter
+	httpClient *http.Client
+	config     *config.Config
+}
+
+// NewAnthropicClient creates a new Anthropic client
+func NewAnthropicClient(cfg *config.Config) *AnthropicClient {
+	c := &AnthropicClient{
+		httpClient: newAPIHTTPClient(),
+		config:     cfg,
+	}
+	c.ClientAdapter = ClientAdapter{Provider: c}
+	return c
+}
+
+// setHeaders applies the Anthropic authentication and version headers
+func (c *AnthropicClient) setHeaders(req *http.Request) {
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-api-key", c.config.AnthropicAPIKey)
+	req.Header.Set("anthropic-version", AnthropicVersion)
+}
+
+// buildRequest translates a Request into an Anthropic request body
+func (c *AnthropicClient) buildRequest(r Request, stream bool) ([]byte, error) {
+	system, converted := toAnthropicMessages(r.Messages)
+	reqBody := anthropicRequest{
+		Model:         r.model(c.config.Model),
+		System:        system,
+		Messages:      converted,
+		Tools:         toAnthropicTools(r.Tools),
+		ToolChoice:    toAnthropicToolChoice(r.ToolChoice),
+		MaxTokens:     AnthropicDefaultMaxTokens,
+		Temperature:   r.Temperature,
+		TopP:          r.TopP,
+		StopSequences: r.Stop,
+		Stream:        stream,
+	}
+	if r.MaxTokens > 0 {
+		reqBody.MaxTokens = r.MaxTokens
+	}
+	reqBody.applyThinking(r)
+	// The Messages API has no response_format, so ask for JSON in the system prompt
+	if instruction := jsonInstruction(r.ResponseFormat); instruction != "" {
+		if reqBody.System != "" {
+			reqBody.System += "\n\n"
+		}
+		reqBody.System += instruction
+	}
+
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	return jsonData, nil
+}
+
+// applyThinking maps the request's reasoning effort to extended thinking.
+// The budget counts toward max_tokens, which is raised to leave room for the
+// answer unless it was set explicitly. Thinking stays off where the Messages
+// API would reject it: with a forced tool choice, and when continuing after
+// tool results, because earlier thinking blocks aren't kept to send back.
+func (req *anthropicRequest) applyThinking(r Request) {
+	budget, ok := anthropicThinkingBudgets[r.ReasoningEffort]
+	if !ok {
+		return
+	}
+	if req.ToolChoice != nil && (req.ToolChoice.Type == "any" || req.ToolChoice.Type == "tool") {
+		return
+	}
+	if n := len(r.Messages); n > 0 && r.Messages[n-1].Role == "tool" {
+		return
+	}
+	if r.MaxTokens > 0 {
+		budget = min(budget, r.MaxTokens-1)
+	} else {
+		req.MaxTokens = budget + AnthropicDefaultMaxTokens
+	}
+	if budget < anthropicMinThinkingBudget {
+		return
+	}
+
+	req.Thinking = &anthropicThinking{Type: "enabled", BudgetTokens: budget}
+	// Thinking requires the default temperature and a top_p of at least 0.95
+	req.Temperature = nil
+	if req.TopP != nil && *req.TopP < 0.95 {
+		req.TopP = nil
+	}
+}
+
+// Complete sends a request to Anthropic (non-streaming)
+func (c *AnthropicClient) Complete(ctx context.Context, r Request) (*ChatResponse, error) {
+	jsonData, err := c.buildRequest(r, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// Use retry logic for transient failures
+	return WithRetry(ctx, func() (*ChatResponse, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.config.GetAnthropicAPIURL(), bytes.NewBuffer(jsonData))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		c.setHeaders(req)
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to send request: %w", err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response: %w", err)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, c.handleError(resp.StatusCode, body)
+		}
+
+		var msgResp anthropicResponse
+		if err := json.Unmarshal(body, &msgResp); err != nil {
+			return nil, fmt.Errorf("failed to parse response: %w", err)
+		}
+
+		return msgResp.toChatResponse(), nil
+	})
+}
+
+// Stream sends a streaming request to Anthropic
+func (c *AnthropicClient) Stream(ctx context.Context, r Request, handler StreamHandler) error {
+	jsonData, err := c.buildRequest(r, true)
+	if err != nil {
+		return err
+	}
+
+	newProcessor := func(r io.Reader) StreamProcessor { return NewAnthropicStreamProcessor(r) }
+
+	// Use retry logic for transient failures (before stream starts)
+	return WithStreamRetryProcessor(ctx, func() (*http.Response, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.config.GetAnthropicAPIURL(), bytes.NewBuffer(jsonData))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		c.setHeaders(req)
+		req.Header.Set("Accept", "text/event-stream")
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to send request: %w", err)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			_ = resp.Body.Close()
+			return nil, c.handleError(resp.StatusCode, body)
+		}
+
+		return resp, nil
+	}, newProcessor, handler)
+}
+
+// Close is a no-op for AnthropicClient as it doesn't hold any resources
+func (c *AnthropicClient) Close() {
+	// No resources to clean up
+}
+
+// handleError creates an appropriate error from the API response
+func (c *AnthropicClient) handleError(statusCode int, body []byte) error {
+	var errResp anthropicErrorResponse
+	errMsg := fmt.Sprintf("status code %d", statusCode)
+	if err := json.Unmarshal(body, &errResp); err == nil && errResp.Error.Message != "" {
+		errMsg = errResp.Error.Message
+	}
+
+	switch statusCode {
+	case http.StatusUnauthorized:
+		return &APIError{
+			StatusCode: statusCode,
+			Message:    "Anthropic API key is invalid. Check ANTHROPIC_API_KEY",
+		}
+	default:
+		return &APIError{
+			StatusCode: statusCode,
+			Message:    fmt.Sprintf("Anthropic API error: %s", errMsg),
+		}
+	}
+}
+
+// toAnthropicMessages converts chat messages into Anthropic content-block turns.
+// System messages are joined into the top-level system prompt, tool results
+// become tool_result blocks in a user turn, and consecutive turns with the same
+// role are merged because the Messages API requires alternating roles.
+func toAnthropicMessages(messages []Message) (string, []anthropicMessage) {
+	var systemParts []string
+	var result []anthropicMessage
+
+	for _, msg := range messages {
+		var role string
+		var blocks []anthropicContentBlock
+
+		switch msg.Role {
+		case "system":
+			if msg.Content != "" {
+				systemParts = append(systemParts, msg.Content)
+			}
+			continue
+		case "tool":
+			role = "user"
+			blocks = append(blocks, anthropicContentBlock{
+				Type:      "tool_result",
+				ToolUseID: msg.ToolCallID,
+				Content:   msg.Content,
+			})
+		case "assistant":
+			role = "assistant"
+			if msg.Content != "" {
+				blocks = append(blocks, anthropicContentBlock{Type: "text", Text: msg.Content})
+			}
+			for _, tc := range msg.ToolCalls {
+				input := json.RawMessage(tc.Function.Arguments)
+				if !json.Valid(input) {
+					input = json.RawMessage("{}")
+				}
+				blocks = append(blocks, anthropicContentBlock{
+					Type:  "tool_use",
+					ID:    tc.ID,
+					Name:  tc.Function.Name,
+					Input: input,
+				})
+			}
+		default:
+			role = "user"
+			if msg.Content != "" {
+				blocks = append(blocks, anthropicContentBlock{Type: "text", Text: msg.Content})
+			}
+			for _, img := range msg.Images() {
+				// Only inline images are supported; URLs would need fetching
+				if mediaType, data, ok := ParseDataURL(img.ImageURL.URL); ok {
+					blocks = append(blocks, anthropicContentBlock{
+						Type:   "image",
+						Source: &anthropicImageSource{Type: "base64", MediaType: mediaType, Data: data},
+					})
+				}
+			}
+		}
+
+		if len(blocks) == 0 {
+			continue
+		}
+
+		if n := len(result); n > 0 && result[n-1].Role == role {
+			result[n-1].Content = append(result[n-1].Content, blocks...)
+		} else {
+			result = append(result, anthropicMessage{Role: role, Content: blocks})
+		}
+	}
+
+	return strings.Join(systemParts, "\n\n"), result
+}
+
+// toAnthropicTools converts function tools into Anthropic tool definitions
+func toAnthropicTools(tools []Tool) []anthropicTool {
+	if len(tools) == 0 {
+		return nil
+	}
+	result := make([]anthropicTool, 0, len(tools))
+	for _, t := range tools {
+		result = append(result, anthropicTool{
+			Name:        t.Function.Name,
+			Description: t.Function.Description,
+			InputSchema: t.Function.Parameters,
+		})
+	}
+	return result
+}
+
+// toAnthropicToolChoice converts an OpenAI-style tool choice to Anthropic's form
+func toAnthropicToolChoice(tc *ToolChoice) *anthropicToolChoice {
+	switch {
+	case tc == nil:
+		return nil
+	case tc.Function != "":
+		return &anthropicToolChoice{Type: "tool", Name: tc.Function}
+	case tc.Mode == ToolChoiceRequired:
+		return &anthropicToolChoice{Type: "any"}
+	case tc.Mode == ToolChoiceNone:
+		return &anthropicToolChoice{Type: "none"}
+	case tc.Mode == ToolChoiceAuto:
+		return &anthropicToolChoice{Type: "auto"}
+	default:
+		return nil
+	}
+}
+
+// jsonInstruction returns a system prompt addition requesting JSON output,
+// or "" if the response format doesn't call for JSON
+func jsonInstruction(rf *ResponseFormat) string {
+	switch {
+	case rf == nil:
+		return ""
+	case rf.Type == ResponseFormatJSONSchema && rf.JSONSchema != nil:
+		return "Respond only with a JSON value that conforms to this JSON Schema, without any surrounding text:\n" + string(rf.JSONSchema.Schema)
+	case rf.Type == ResponseFormatJSONObject || rf.Type == ResponseFormatJSONSchema:
+		return "Respond only with a valid JSON object, without any surrounding text."
+	default:
+		return ""
+	}
+}
+
+// anthropicFinishReason maps an Anthropic stop_reason to an OpenAI finish_reason
+func anthropicFinishReason(stopReason string) string {
+	switch stopReason {
+	case "tool_use":
+		return "tool_calls"
+	case "max_tokens":
+		return "length"
+	default:
+		return "stop"
+	}
+}
+
+// toChatResponse converts a Messages API response into a ChatResponse
+func (r *anthropicResponse) toChatResponse() *ChatResponse {
+	var content, thinking strings.Builder
+	var toolCalls []ToolCall
+	for _, block := range r.Content {
+		switch block.Type {
+		case "text":
+			content.WriteString(block.Text)
+		case "thinking":
+			thinking.WriteString(block.Thinking)
+		case "tool_use":
+			tc := ToolCall{ID: block.ID, Type: "function", Index: len(toolCalls)}
+			tc.Function.Name = block.Name
+			tc.Function.Arguments = string(block.Input)
+			toolCalls = append(toolCalls, tc)
+		}
+	}
+
+	return &ChatResponse{
+		ID: r.ID,
+		Choices: []Choice{
+			{
+				Index: 0,
+				Message: Message{
+					Role:      "assistant",
+					Content:   content.String(),
+					Reasoning: thinking.String(),
+					ToolCalls: toolCalls,
+				},
+				FinishReason: anthropicFinishReason(r.StopReason),
+			},
+		},
+		Usage: Usage{
+			PromptTokens:     r.Usage.InputTokens,
+			CompletionTokens: r.Usage.OutputTokens,
+			TotalTokens:      r.Usage.InputTokens + r.Usage.OutputTokens,
+		},
+	}
+}
+
+// anthropicStreamEvent covers the fields used across Anthropic SSE event types
+type anthropicStreamEvent struct {
+	Type    string `json:"type"`
+	Index   int    `json:"index"`
+	Message struct {
+		ID    string         `json:"id"`
+		Usage anthropicUsage `json:"usage"`
+	} `json:"message"`
+	ContentBlock anthropicContentBlock `json:"content_block"`
+	Delta        struct {
+		Type        string `json:"type"`
+		Text        string `json:"text"`
+		Thinking    string `json:"thinking"`
+		PartialJSON string `json:"partial_json"`
+		StopReason  string `json:"stop_reason"`
+	} `json:"delta"`
+	Usage anthropicUsage `json:"usage"`
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// AnthropicStreamProcessor handles the Anthropic Messages SSE stream
+type AnthropicStreamProcessor struct {
+	reader           *bufio.Reader
+	contentBuilder   strings.Builder
+	reasoningBuilder strings.Builder
+	onReasoning      func(content string)
+	toolCalls        []*ToolCall
+	toolCallsMap     map[int]*ToolCall // content block index -> tool call
+	usage            anthropicUsage
+	stopReason       string
+	responseID       string
+}
+
+// NewAnthropicStreamProcessor creates a new Anthropic stream processor
+func NewAnthropicStreamProcessor(r io.Reader) *AnthropicStreamProcessor {
+	return &AnthropicStreamProcessor{
+		reader:       bufio.NewReader(r),
+		toolCallsMap: make(map[int]*ToolCall),
+	}
+}
+
+// SetReasoningHandler sets the function called for each thinking chunk
+func (p *AnthropicStreamProcessor) SetReasoningHandler(onReasoning func(content string)) {
+	p.onReasoning = onReasoning
+}
+
+// Process reads the Anthropic event stream, calling onChunk for each text delta
+func (p *AnthropicStreamProcessor) Process(ctx context.Context, onChunk func(content string)) error {
+	for {
+		// Check for context cancellation
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		line, err := p.reader.ReadString('\n')
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return err
+		}
+
+		// Event names are repeated in the data payload's "type" field,
+		// so only data lines need to be parsed
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+
+		var event anthropicStreamEvent
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			log.Printf("Failed to parse streaming event: %v (data: %s)", err, data)
+			continue
+		}
+
+		switch event.Type {
+		case "message_start":
+			p.responseID = event.Message.ID
+			p.usage.InputTokens = event.Message.Usage.InputTokens
+			p.usage.OutputTokens = event.Message.Usage.OutputTokens
+		case "content_block_start":
+			if event.ContentBlock.Type == "tool_use" {
+				tc := &ToolCall{ID: event.ContentBlock.ID, Type: "function", Index: len(p.toolCalls)}
+				tc.Function.Name = event.ContentBlock.Name
+				p.toolCalls = append(p.toolCalls, tc)
+				p.toolCallsMap[event.Index] = tc
+			}
+		case "content_block_delta":
+			switch event.Delta.Type {
+			case "text_delta":
+				if event.Delta.Text != "" {
+					p.contentBuilder.WriteString(event.Delta.Text)
+					onChunk(event.Delta.Text)
+				}
+			case "thinking_delta":
+				if event.Delta.Thinking != "" {
+					p.reasoningBuilder.WriteString(event.Delta.Thinking)
+					if p.onReasoning != nil {
+						p.onReasoning(event.Delta.Thinking)
+					}
+				}
+			case "input_json_delta":
+				if tc, ok := p.toolCallsMap[event.Index]; ok {
+					tc.Function.Arguments += event.Delta.PartialJSON
+				}
+			}
+		case "message_delta":
+			if event.Delta.StopReason != "" {
+				p.stopReason = event.Delta.StopReason
+			}
+			if event.Usage.OutputTokens > 0 {
+				p.usage.OutputTokens = event.Usage.OutputTokens
+			}
+		case "message_stop":
+			return nil
+		case "error":
+			return fmt.Errorf("Anthropic stream error: %s", event.Error.Message)
+		}
+	}
+
+	return nil
+}
+
+// BuildResponse constructs the final ChatResponse from accumulated data
+func (p *AnthropicStreamProcessor) BuildResponse() *ChatResponse {
+	var toolCalls []ToolCall
+	for _, tc := range p.toolCalls {
+		if tc.Function.Arguments == "" {
+			tc.Function.Arguments = "{}"
+		}
+		toolCalls = append(toolCalls, *tc)
+	}
+
+	finishReason := anthropicFinishReason(p.stopReason)
+	if len(toolCalls) > 0 {
+		finishReason = "tool_calls"
+	}
+
+	return &ChatResponse{
+		ID: p.responseID,
+		Choices: []Choice{
+			{
+				Index: 0,
+				Message: Message{
+					Role:      "assistant",
+					Content:   p.contentBuilder.String(),
+					Reasoning: p.reasoningBuilder.String(),
+					ToolCalls: toolCalls,
+				},
+				FinishReason: finishReason,
+			},
+		},
+		Usage: Usage{
+			PromptTokens:     p.usage.InputTokens,
+			CompletionTokens: p.usage.OutputTokens,
+			TotalTokens:      p.usage.InputTokens + p.usage.OutputTokens,
+		},
+	}
+}
@@ -0,0 +1,303 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/quocvuong92/ai-cli/internal/config"
+)
+
+// newAnthropicTestClient creates an AnthropicClient pointed at a test server
+func newAnthropicTestClient(t *testing.T, handler http.HandlerFunc) *AnthropicClient {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return NewAnthropicClient(&config.Config{
+		Model:            "claude-test",
+		AnthropicBaseURL: server.URL,
+		AnthropicAPIKey:  "test-key",
+	})
+}
+
+func TestToAnthropicMessages(t *testing.T) {
+	assistant := Message{Role: "assistant", Content: "Let me check."}
+	tc := ToolCall{ID: "toolu_1", Type: "function"}
+	tc.Function.Name = "read_file"
+	tc.Function.Arguments = `{"path":"go.mod"}`
+	assistant.ToolCalls = []ToolCall{tc, {ID: "toolu_2", Type: "function"}}
+
+	system, messages := toAnthropicMessages([]Message{
+		{Role: "system", Content: "Be precise."},
+		{Role: "user", Content: "Read go.mod"},
+		assistant,
+		{Role: "tool", Content: "module x", ToolCallID: "toolu_1"},
+		{Role: "tool", Content: "", ToolCallID: "toolu_2"},
+		{Role: "system", Content: "Web results."},
+		{Role: "user", Content: "Thanks"},
+	})
+
+	if system != "Be precise.\n\nWeb results." {
+		t.Errorf("system = %q, want both system messages joined", system)
+	}
+	if len(messages) != 3 {
+		t.Fatalf("got %d messages, want 3 (user, assistant, merged user)", len(messages))
+	}
+
+	if messages[1].Role != "assistant" || len(messages[1].Content) != 3 {
+		t.Fatalf("assistant turn = %+v, want text + 2 tool_use blocks", messages[1])
+	}
+	if messages[1].Content[1].Type != "tool_use" || string(messages[1].Content[1].Input) != `{"path":"go.mod"}` {
+		t.Errorf("tool_use block = %+v", messages[1].Content[1])
+	}
+	// Empty arguments must still produce a valid JSON object
+	if string(messages[1].Content[2].Input) != "{}" {
+		t.Errorf("empty tool_use input = %q, want {}", string(messages[1].Content[2].Input))
+	}
+
+	// Tool results and the following user text merge into one user turn
+	last := messages[2]
+	if last.Role != "user" || len(last.Content) != 3 {
+		t.Fatalf("last turn = %+v, want 2 tool_result + 1 text block", last)
+	}
+	if last.Content[0].Type != "tool_result" || last.Content[0].ToolUseID != "toolu_1" {
+		t.Errorf("tool_result block = %+v", last.Content[0])
+	}
+	if last.Content[2].Type != "text" || last.Content[2].Text != "Thanks" {
+		t.Errorf("text block = %+v", last.Content[2])
+	}
+}
+
+func TestAnthropicClient_QueryWithHistoryAndToolsContext(t *testing.T) {
+	client := newAnthropicTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/messages" {
+			t.Errorf("path = %q, want /v1/messages", r.URL.Path)
+		}
+		if got := r.Header.Get("x-api-key"); got != "test-key" {
+			t.Errorf("x-api-key = %q, want %q", got, "test-key")
+		}
+		if got := r.Header.Get("anthropic-version"); got != AnthropicVersion {
+			t.Errorf("anthropic-version = %q, want %q", got, AnthropicVersion)
+		}
+
+		var req anthropicRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+		if req.System != "sys" {
+			t.Errorf("System = %q, want %q", req.System, "sys")
+		}
+		if req.MaxTokens != AnthropicDefaultMaxTokens {
+			t.Errorf("MaxTokens = %d, want %d", req.MaxTokens, AnthropicDefaultMaxTokens)
+		}
+		if len(req.Tools) != 1 || req.Tools[0].Name != "read_file" || req.Tools[0].InputSchema == nil {
+			t.Errorf("Tools = %+v, want read_file with input_schema", req.Tools)
+		}
+
+		fmt.Fprint(w, `{"id":"msg_1","type":"message","role":"assistant","content":[{"type":"text","text":"Reading."},{"type":"tool_use","id":"toolu_1","name":"read_file","input":{"path":"go.mod"}}],"stop_reason":"tool_use","usage":{"input_tokens":20,"output_tokens":7}}`)
+	})
+
+	resp, err := client.QueryWithHistoryAndToolsContext(context.Background(),
+		[]Message{{Role: "system", Content: "sys"}, {Role: "user", Content: "read go.mod"}},
+		[]Tool{ReadFileTool},
+	)
+	if err != nil {
+		t.Fatalf("QueryWithHistoryAndToolsContext() error = %v", err)
+	}
+
+	if resp.GetContent() != "Reading." {
+		t.Errorf("GetContent() = %q, want %q", resp.GetContent(), "Reading.")
+	}
+	if resp.Choices[0].FinishReason != "tool_calls" {
+		t.Errorf("FinishReason = %q, want tool_calls", resp.Choices[0].FinishReason)
+	}
+	toolCalls := resp.Choices[0].GetToolCalls()
+	if len(toolCalls) != 1 || toolCalls[0].ID != "toolu_1" || toolCalls[0].Function.Arguments != `{"path":"go.mod"}` {
+		t.Errorf("tool calls = %+v", toolCalls)
+	}
+	if resp.Usage.PromptTokens != 20 || resp.Usage.CompletionTokens != 7 || resp.Usage.TotalTokens != 27 {
+		t.Errorf("Usage = %+v, want 20/7/27", resp.Usage)
+	}
+}
+
+func TestAnthropicClient_QueryStreamWithTools(t *testing.T) {
+	client := newAnthropicTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, `event: message_start
+data: {"type":"message_start","message":{"id":"msg_s","usage":{"input_tokens":12,"output_tokens":1}}}
+
+event: content_block_start
+data: {"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}
+
+event: ping
+data: {"type":"ping"}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Checking"}}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":" now."}}
+
+event: content_block_stop
+data: {"type":"content_block_stop","index":0}
+
+event: content_block_start
+data: {"type":"content_block_start","index":1,"content_block":{"type":"tool_use","id":"toolu_9","name":"list_directory","input":{}}}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"{\"path\":"}}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"\".\"}"}}
+
+event: content_block_stop
+data: {"type":"content_block_stop","index":1}
+
+event: message_delta
+data: {"type":"message_delta","delta":{"stop_reason":"tool_use"},"usage":{"output_tokens":30}}
+
+event: message_stop
+data: {"type":"message_stop"}
+`)
+	})
+
+	var chunks []string
+	var final *ChatResponse
+	err := client.QueryStreamWithHistoryAndToolsContext(context.Background(),
+		[]Message{{Role: "user", Content: "list files"}},
+		[]Tool{ListDirectoryTool},
+		func(content string) { chunks = append(chunks, content) },
+		func(resp *ChatResponse) { final = resp },
+	)
+	if err != nil {
+		t.Fatalf("QueryStreamWithHistoryAndToolsContext() error = %v", err)
+	}
+
+	if strings.Join(chunks, "") != "Checking now." {
+		t.Errorf("streamed content = %q, want %q", strings.Join(chunks, ""), "Checking now.")
+	}
+	if final == nil {
+		t.Fatal("onDone was not called")
+	}
+	if final.ID != "msg_s" {
+		t.Errorf("ID = %q, want %q", final.ID, "msg_s")
+	}
+	toolCalls := final.Choices[0].GetToolCalls()
+	if len(toolCalls) != 1 {
+		t.Fatalf("got %d tool calls, want 1", len(toolCalls))
+	}
+	if toolCalls[0].Index != 0 || toolCalls[0].Function.Name != "list_directory" || toolCalls[0].Function.Arguments != `{"path":"."}` {
+		t.Errorf("tool call = %+v", toolCalls[0])
+	}
+	if final.Usage.PromptTokens != 12 || final.Usage.CompletionTokens != 30 || final.Usage.TotalTokens != 42 {
+		t.Errorf("Usage = %+v, want 12/30/42", final.Usage)
+	}
+}
+
+func TestAnthropicStreamProcessor_ErrorEvent(t *testing.T) {
+	input := `event: error
+data: {"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}
+`
+	processor := NewAnthropicStreamProcessor(strings.NewReader(input))
+	err := processor.Process(context.Background(), func(string) {})
+	if err == nil || !strings.Contains(err.Error(), "Overloaded") {
+		t.Errorf("Process() error = %v, want overloaded error", err)
+	}
+}
+
+func TestAnthropicClient_ErrorResponse(t *testing.T) {
+	client := newAnthropicTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"type":"error","error":{"type":"invalid_request_error","message":"messages: roles must alternate"}}`)
+	})
+
+	_, err := client.Query("sys", "hi")
+	apiErr, ok := err.(*APIError)
+	if !ok {
+		t.Fatalf("error type = %T, want *APIError", err)
+	}
+	if apiErr.StatusCode != http.StatusBadRequest || !strings.Contains(apiErr.Message, "roles must alternate") {
+		t.Errorf("APIError = %+v", apiErr)
+	}
+}
+
+func TestAnthropicStreamProcessor_Thinking(t *testing.T) {
+	input := `data: {"type":"message_start","message":{"id":"msg_1","usage":{"input_tokens":5,"output_tokens":1}}}
+
+data: {"type":"content_block_start","index":0,"content_block":{"type":"thinking","thinking":""}}
+
+data: {"type":"content_block_delta","index":0,"delta":{"type":"thinking_delta","thinking":"Let me see."}}
+
+data: {"type":"content_block_start","index":1,"content_block":{"type":"text","text":""}}
+
+data: {"type":"content_block_delta","index":1,"delta":{"type":"text_delta","text":"Yes."}}
+
+data: {"type":"message_stop"}
+`
+	processor := NewAnthropicStreamProcessor(strings.NewReader(input))
+	var thinking string
+	processor.SetReasoningHandler(func(chunk string) { thinking += chunk })
+	if err := processor.Process(context.Background(), func(string) {}); err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	if thinking != "Let me see." {
+		t.Errorf("reasoning = %q, want %q", thinking, "Let me see.")
+	}
+	resp := processor.BuildResponse()
+	if resp.GetContent() != "Yes." || resp.GetReasoning() != "Let me see." {
+		t.Errorf("response content = %q, reasoning = %q", resp.GetContent(), resp.GetReasoning())
+	}
+}
+
+func TestAnthropicClient_BuildRequest_ReasoningEffort(t *testing.T) {
+	client := NewAnthropicClient(&config.Config{Model: "claude-test"})
+	temperature := 0.2
+	user := []Message{{Role: "user", Content: "hi"}}
+	afterTool := []Message{
+		{Role: "user", Content: "read go.mod"},
+		{Role: "assistant", ToolCalls: []ToolCall{{ID: "toolu_1", Type: "function"}}},
+		{Role: "tool", ToolCallID: "toolu_1", Content: "module x"},
+	}
+
+	tests := []struct {
+		name          string
+		req           Request
+		wantBudget    int
+		wantMaxTokens int
+	}{
+		{"no effort", Request{Messages: user}, 0, AnthropicDefaultMaxTokens},
+		{"medium", Request{Messages: user, ReasoningEffort: "medium", Temperature: &temperature}, 8192, 8192 + AnthropicDefaultMaxTokens},
+		{"explicit max tokens", Request{Messages: user, ReasoningEffort: "high", MaxTokens: 4096}, 4095, 4096},
+		{"max tokens below minimum budget", Request{Messages: user, ReasoningEffort: "low", MaxTokens: 512}, 0, 512},
+		{"forced tool", Request{Messages: user, ReasoningEffort: "low", ToolChoice: &ToolChoice{Mode: ToolChoiceRequired}}, 0, AnthropicDefaultMaxTokens},
+		{"after tool results", Request{Messages: afterTool, ReasoningEffort: "low"}, 0, AnthropicDefaultMaxTokens},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := client.buildRequest(tt.req, false)
+			if err != nil {
+				t.Fatalf("buildRequest() error = %v", err)
+			}
+			var req anthropicRequest
+			if err := json.Unmarshal(data, &req); err != nil {
+				t.Fatalf("failed to decode request: %v", err)
+			}
+
+			budget := 0
+			if req.Thinking != nil {
+				budget = req.Thinking.BudgetTokens
+				if req.Thinking.Type != "enabled" || req.Temperature != nil {
+					t.Errorf("thinking = %+v, temperature = %v; want enabled without temperature", req.Thinking, req.Temperature)
+				}
+			}
+			if budget != tt.wantBudget || req.MaxTokens != tt.wantMaxTokens {
+				t.Errorf("budget = %d, max_tokens = %d; want %d, %d", budget, req.MaxTokens, tt.wantBudget, tt.wantMaxTokens)
+			}
+		})
+	}
+}
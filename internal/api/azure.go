@@ -9,13 +9,17 @@ import (
 	"net/http"
 
 	"github.com/quocvuong92/ai-cli/internal/config"
-	"github.com/quocvuong92/ai-cli/internal/constants"
 )
 
-// Message represents a chat message
+// Message represents a chat message. Content is the message text; Parts,
+// when set, is the full multimodal content (text and images) sent instead.
+// See MarshalJSON.
 type Message struct {
-	Role       string     `json:"role"`
-	Content    string     `json:"content,omitempty"`
+	Role    string        `json:"role"`
+	Content string        `json:"content,omitempty"`
+	Parts   []ContentPart `json:"-"`
+	// Reasoning is the model's reasoning, received but never sent back
+	Reasoning  string     `json:"-"`
 	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`
 	ToolCallID string     `json:"tool_call_id,omitempty"`
 }
@@ -46,24 +50,70 @@ type ToolCall struct {
 
 // ChatRequest represents the Chat Completions API request
 type ChatRequest struct {
-	Model    string    `json:"model"`
-	Messages []Message `json:"messages"`
-	Tools    []Tool    `json:"tools,omitempty"`
-	Stream   bool      `json:"stream,omitempty"`
+	Model       string    `json:"model"`
+	Messages    []Message `json:"messages"`
+	Tools       []Tool    `json:"tools,omitempty"`
+	Stream      bool      `json:"stream,omitempty"`
+	Temperature *float64  `json:"temperature,omitempty"`
+	TopP        *float64  `json:"top_p,omitempty"`
+	MaxTokens   int       `json:"max_tokens,omitempty"`
+	// MaxCompletionTokens replaces MaxTokens for o-series and gpt-5 models
+	MaxCompletionTokens int             `json:"max_completion_tokens,omitempty"`
+	Seed                *int            `json:"seed,omitempty"`
+	Stop                []string        `json:"stop,omitempty"`
+	ReasoningEffort     string          `json:"reasoning_effort,omitempty"`
+	ToolChoice          *ToolChoice     `json:"tool_choice,omitempty"`
+	ResponseFormat      *ResponseFormat `json:"response_format,omitempty"`
+	StreamOptions       *StreamOptions  `json:"stream_options,omitempty"`
+}
+
+// StreamOptions asks for the token usage in a final stream chunk
+type StreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
 }
 
 // Usage represents token usage statistics
 type Usage struct {
-	PromptTokens     int `json:"prompt_tokens"`
-	CompletionTokens int `json:"completion_tokens"`
-	TotalTokens      int `json:"total_tokens"`
+	PromptTokens            int                      `json:"prompt_tokens"`
+	CompletionTokens        int                      `json:"completion_tokens"`
+	TotalTokens             int                      `json:"total_tokens"`
+	CompletionTokensDetails *CompletionTokensDetails `json:"completion_tokens_details,omitempty"`
 }
 
-// Delta represents streaming delta content
+// CompletionTokensDetails breaks down the completion tokens
+type CompletionTokensDetails struct {
+	ReasoningTokens int `json:"reasoning_tokens"`
+}
+
+// ReasoningTokens returns the completion tokens spent on reasoning, if reported
+func (u Usage) ReasoningTokens() int {
+	if u.CompletionTokensDetails == nil {
+		return 0
+	}
+	return u.CompletionTokensDetails.ReasoningTokens
+}
+
+// Delta represents streaming delta content. Providers name the reasoning
+// field differently; see ReasoningText.
 type Delta struct {
-	Role      string     `json:"role,omitempty"`
-	Content   string     `json:"content,omitempty"`
-	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
+	Role             string     `json:"role,omitempty"`
+	Content          string     `json:"content,omitempty"`
+	ReasoningContent string     `json:"reasoning_content,omitempty"`
+	Reasoning        string     `json:"reasoning,omitempty"`
+	ReasoningTextRaw string     `json:"reasoning_text,omitempty"`
+	ToolCalls        []ToolCall `json:"tool_calls,omitempty"`
+}
+
+// ReasoningText returns the delta's reasoning, whichever field carries it
+func (d Delta) ReasoningText() string {
+	switch {
+	case d.ReasoningContent != "":
+		return d.ReasoningContent
+	case d.Reasoning != "":
+		return d.Reasoning
+	default:
+		return d.ReasoningTextRaw
+	}
 }
 
 // Choice represents a response choice
@@ -92,6 +142,9 @@ type ChatResponse struct {
 	ID      string   `json:"id"`
 	Choices []Choice `json:"choices"`
 	Usage   Usage    `json:"usage"`
+	// Backend is the "provider:model" label of the fallback backend that
+	// answered; empty outside a fallback chain
+	Backend string `json:"-"`
 }
 
 // AzureErrorResponse represents an Azure API error
@@ -114,52 +167,24 @@ func (e *APIError) Error() string {
 
 // AzureClient is the Azure OpenAI API client
 type AzureClient struct {
+	ClientAdapter
 	httpClient *http.Client
 	config     *config.Config
 }
 
 // NewAzureClient creates a new Azure OpenAI client
 func NewAzureClient(cfg *config.Config) *AzureClient {
-	return &AzureClient{
-		httpClient: &http.Client{
-			Timeout: constants.DefaultAPITimeout,
-		},
-		config: cfg,
+	c := &AzureClient{
+		httpClient: newAPIHTTPClient(),
+		config:     cfg,
 	}
+	c.ClientAdapter = ClientAdapter{Provider: c}
+	return c
 }
 
-// Query sends a query to Azure OpenAI (non-streaming)
-func (c *AzureClient) Query(systemPrompt, userMessage string) (*ChatResponse, error) {
-	return c.QueryWithContext(context.Background(), systemPrompt, userMessage)
-}
-
-// QueryWithContext sends a query to Azure OpenAI with context support (non-streaming)
-func (c *AzureClient) QueryWithContext(ctx context.Context, systemPrompt, userMessage string) (*ChatResponse, error) {
-	messages := []Message{
-		{Role: "system", Content: systemPrompt},
-		{Role: "user", Content: userMessage},
-	}
-	return c.QueryWithHistoryContext(ctx, messages)
-}
-
-// QueryWithHistory sends a query with full message history (non-streaming)
-func (c *AzureClient) QueryWithHistory(messages []Message) (*ChatResponse, error) {
-	return c.QueryWithHistoryContext(context.Background(), messages)
-}
-
-// QueryWithHistoryContext sends a query with full message history and context support (non-streaming)
-func (c *AzureClient) QueryWithHistoryContext(ctx context.Context, messages []Message) (*ChatResponse, error) {
-	return c.QueryWithHistoryAndToolsContext(ctx, messages, nil)
-}
-
-// QueryWithHistoryAndToolsContext sends a query with full message history, tools, and context support (non-streaming)
-func (c *AzureClient) QueryWithHistoryAndToolsContext(ctx context.Context, messages []Message, tools []Tool) (*ChatResponse, error) {
-	reqBody := ChatRequest{
-		Model:    c.config.Model,
-		Messages: messages,
-		Tools:    tools,
-		Stream:   false,
-	}
+// Complete sends a request to Azure OpenAI (non-streaming)
+func (c *AzureClient) Complete(ctx context.Context, r Request) (*ChatResponse, error) {
+	reqBody := r.toChatRequest(c.config.Model, false)
 
 	jsonData, err := json.Marshal(reqBody)
 	if err != nil {
@@ -208,38 +233,9 @@ func (c *AzureClient) QueryWithHistoryAndToolsContext(ctx context.Context, messa
 	})
 }
 
-// QueryStream sends a streaming query to Azure OpenAI
-func (c *AzureClient) QueryStream(systemPrompt, userMessage string, onChunk func(content string), onDone func(resp *ChatResponse)) error {
-	return c.QueryStreamWithContext(context.Background(), systemPrompt, userMessage, onChunk, onDone)
-}
-
-// QueryStreamWithContext sends a streaming query to Azure OpenAI with context support
-func (c *AzureClient) QueryStreamWithContext(ctx context.Context, systemPrompt, userMessage string, onChunk func(content string), onDone func(resp *ChatResponse)) error {
-	messages := []Message{
-		{Role: "system", Content: systemPrompt},
-		{Role: "user", Content: userMessage},
-	}
-	return c.QueryStreamWithHistoryContext(ctx, messages, onChunk, onDone)
-}
-
-// QueryStreamWithHistory sends a streaming query with full message history
-func (c *AzureClient) QueryStreamWithHistory(messages []Message, onChunk func(content string), onDone func(resp *ChatResponse)) error {
-	return c.QueryStreamWithHistoryContext(context.Background(), messages, onChunk, onDone)
-}
-
-// QueryStreamWithHistoryContext sends a streaming query with full message history and context support
-func (c *AzureClient) QueryStreamWithHistoryContext(ctx context.Context, messages []Message, onChunk func(content string), onDone func(resp *ChatResponse)) error {
-	return c.QueryStreamWithHistoryAndToolsContext(ctx, messages, nil, onChunk, onDone)
-}
-
-// QueryStreamWithHistoryAndToolsContext sends a streaming query with full message history, tools, and context support
-func (c *AzureClient) QueryStreamWithHistoryAndToolsContext(ctx context.Context, messages []Message, tools []Tool, onChunk func(content string), onDone func(resp *ChatResponse)) error {
-	reqBody := ChatRequest{
-		Model:    c.config.Model,
-		Messages: messages,
-		Tools:    tools,
-		Stream:   true,
-	}
+// Stream sends a streaming request to Azure OpenAI
+func (c *AzureClient) Stream(ctx context.Context, r Request, handler StreamHandler) error {
+	reqBody := r.toChatRequest(c.config.Model, true)
 
 	jsonData, err := json.Marshal(reqBody)
 	if err != nil {
@@ -277,7 +273,7 @@ func (c *AzureClient) QueryStreamWithHistoryAndToolsContext(ctx context.Context,
 		}
 
 		return resp, nil
-	}, onChunk, onDone)
+	}, handler)
 }
 
 // Close is a no-op for AzureClient as it doesn't hold any resources
@@ -298,9 +294,21 @@ func (r *ChatResponse) GetContent() string {
 
 // GetUsageMap returns usage as a map for display
 func (r *ChatResponse) GetUsageMap() map[string]int {
-	return map[string]int{
+	usage := map[string]int{
 		"input_tokens":  r.Usage.PromptTokens,
 		"output_tokens": r.Usage.CompletionTokens,
 		"total_tokens":  r.Usage.TotalTokens,
 	}
+	if n := r.Usage.ReasoningTokens(); n > 0 {
+		usage["reasoning_tokens"] = n
+	}
+	return usage
+}
+
+// GetReasoning returns the reasoning of the first choice, if any
+func (r *ChatResponse) GetReasoning() string {
+	if len(r.Choices) > 0 {
+		return r.Choices[0].Message.Reasoning
+	}
+	return ""
 }
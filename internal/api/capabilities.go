@@ -0,0 +1,244 @@
+package api
+
+import (
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/quocvuong92/ai-cli/internal/config"
+)
+
+// ModelCapabilities describes what a model supports and how requests to it
+// must be shaped
+type ModelCapabilities struct {
+	ContextWindow   int  `json:"context_window,omitempty"`
+	MaxOutputTokens int  `json:"max_output_tokens,omitempty"`
+	ToolCalls       bool `json:"tool_calls"`
+	Vision          bool `json:"vision"`
+	// SystemRole is the role system messages are sent with: "system",
+	// "developer", or "user" for models that reject system messages
+	// (they are folded into the first user message)
+	SystemRole string `json:"system_role,omitempty"`
+	// MaxCompletionTokens sends the output limit as max_completion_tokens
+	// instead of max_tokens
+	MaxCompletionTokens bool `json:"max_completion_tokens,omitempty"`
+}
+
+// HistoryBudget returns the tokens available for the conversation: the
+// context window less the room reserved for the reply (at most a quarter
+// of the window)
+func (c ModelCapabilities) HistoryBudget() int {
+	reserve := c.MaxOutputTokens
+	if reserve <= 0 || reserve > c.ContextWindow/4 {
+		reserve = c.ContextWindow / 4
+	}
+	return c.ContextWindow - reserve
+}
+
+// defaultCapabilities applies to models the registry knows nothing about
+var defaultCapabilities = ModelCapabilities{
+	ContextWindow:   128000,
+	MaxOutputTokens: 4096,
+	ToolCalls:       true,
+	SystemRole:      config.SystemRoleSystem,
+}
+
+// builtinCapabilities are keyed by model name prefix; the longest matching
+// prefix wins
+var builtinCapabilities = map[string]ModelCapabilities{
+	"gpt-4o":         {ContextWindow: 128000, MaxOutputTokens: 16384, ToolCalls: true, Vision: true, SystemRole: config.SystemRoleSystem},
+	"gpt-4.1":        {ContextWindow: 1047576, MaxOutputTokens: 32768, ToolCalls: true, Vision: true, SystemRole: config.SystemRoleSystem},
+	"gpt-5":          {ContextWindow: 400000, MaxOutputTokens: 128000, ToolCalls: true, Vision: true, SystemRole: config.SystemRoleSystem, MaxCompletionTokens: true},
+	"o1":             {ContextWindow: 200000, MaxOutputTokens: 100000, ToolCalls: true, Vision: true, SystemRole: config.SystemRoleDeveloper, MaxCompletionTokens: true},
+	"o1-mini":        {ContextWindow: 128000, MaxOutputTokens: 65536, SystemRole: config.SystemRoleUser, MaxCompletionTokens: true},
+	"o1-preview":     {ContextWindow: 128000, MaxOutputTokens: 32768, SystemRole: config.SystemRoleUser, MaxCompletionTokens: true},
+	"o3":             {ContextWindow: 200000, MaxOutputTokens: 100000, ToolCalls: true, Vision: true, SystemRole: config.SystemRoleDeveloper, MaxCompletionTokens: true},
+	"o3-mini":        {ContextWindow: 200000, MaxOutputTokens: 100000, ToolCalls: true, SystemRole: config.SystemRoleDeveloper, MaxCompletionTokens: true},
+	"o4-mini":        {ContextWindow: 200000, MaxOutputTokens: 100000, ToolCalls: true, Vision: true, SystemRole: config.SystemRoleDeveloper, MaxCompletionTokens: true},
+	"claude-":        {ContextWindow: 200000, MaxOutputTokens: 32000, ToolCalls: true, Vision: true, SystemRole: config.SystemRoleSystem},
+	"gemini-2.5":     {ContextWindow: 1048576, MaxOutputTokens: 65536, ToolCalls: true, Vision: true, SystemRole: config.SystemRoleSystem},
+	"grok-code-fast": {ContextWindow: 256000, MaxOutputTokens: 10000, ToolCalls: true, SystemRole: config.SystemRoleSystem},
+}
+
+// CapabilityRegistry resolves a model's capabilities. Config overrides take
+// precedence over what the provider reports, which takes precedence over
+// the built-in table.
+type CapabilityRegistry struct {
+	mu        sync.RWMutex
+	overrides map[string]config.ModelCapabilityOverride
+	live      map[string]ModelCapabilities
+	aliases   map[string]string // Azure deployment -> underlying model
+}
+
+// NewCapabilityRegistry creates a registry with only the built-in table
+func NewCapabilityRegistry() *CapabilityRegistry {
+	return &CapabilityRegistry{}
+}
+
+// Capabilities is the registry consulted when building requests
+var Capabilities = NewCapabilityRegistry()
+
+// SetOverrides replaces the config overrides. Keys are model names or
+// prefixes ending in "*".
+func (r *CapabilityRegistry) SetOverrides(overrides map[string]config.ModelCapabilityOverride) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.overrides = overrides
+}
+
+// SetLive replaces the capabilities reported by the provider's model list.
+// Deployments that name their underlying model resolve through it.
+func (r *CapabilityRegistry) SetLive(models []ModelInfo) {
+	live := make(map[string]ModelCapabilities)
+	aliases := make(map[string]string)
+	for _, m := range models {
+		if m.Capabilities != nil {
+			live[m.ID] = *m.Capabilities
+		}
+		if m.Model != "" && m.Model != m.ID {
+			aliases[m.ID] = m.Model
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.live = live
+	r.aliases = aliases
+}
+
+// Lookup returns the capabilities of model, falling back to defaults
+func (r *CapabilityRegistry) Lookup(model string) ModelCapabilities {
+	caps, _ := r.Match(model)
+	return caps
+}
+
+// Match returns the capabilities of model and whether anything specific is
+// known about it
+func (r *CapabilityRegistry) Match(model string) (ModelCapabilities, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := []string{model}
+	if alias, ok := r.aliases[model]; ok {
+		names = append(names, alias)
+	}
+
+	caps, known := defaultCapabilities, false
+	for _, name := range names {
+		if builtin, ok := longestPrefix(builtinCapabilities, name); ok {
+			caps, known = builtin, true
+			break
+		}
+	}
+
+	// The provider knows limits and features, but not the wire format
+	if live, ok := r.live[model]; ok {
+		if live.ContextWindow > 0 {
+			caps.ContextWindow = live.ContextWindow
+		}
+		if live.MaxOutputTokens > 0 {
+			caps.MaxOutputTokens = live.MaxOutputTokens
+		}
+		caps.ToolCalls = live.ToolCalls
+		caps.Vision = live.Vision
+		known = true
+	}
+
+	for _, name := range names {
+		if o, ok := r.override(name); ok {
+			applyOverride(&caps, o)
+			known = true
+			break
+		}
+	}
+	return caps, known
+}
+
+// override finds the config override for name: an exact key, else the
+// longest matching "prefix*" key
+func (r *CapabilityRegistry) override(name string) (config.ModelCapabilityOverride, bool) {
+	if o, ok := r.overrides[name]; ok {
+		return o, true
+	}
+	best := -1
+	var found config.ModelCapabilityOverride
+	for key, o := range r.overrides {
+		prefix, ok := strings.CutSuffix(key, "*")
+		if ok && strings.HasPrefix(name, prefix) && len(prefix) > best {
+			best, found = len(prefix), o
+		}
+	}
+	return found, best >= 0
+}
+
+// longestPrefix returns the entry whose key is the longest prefix of name
+func longestPrefix(table map[string]ModelCapabilities, name string) (ModelCapabilities, bool) {
+	var best string
+	for prefix := range table {
+		if strings.HasPrefix(name, prefix) && len(prefix) > len(best) {
+			best = prefix
+		}
+	}
+	if best == "" {
+		return ModelCapabilities{}, false
+	}
+	return table[best], true
+}
+
+// applyOverride sets the fields the override specifies
+func applyOverride(caps *ModelCapabilities, o config.ModelCapabilityOverride) {
+	if o.ContextWindow > 0 {
+		caps.ContextWindow = o.ContextWindow
+	}
+	if o.MaxOutputTokens > 0 {
+		caps.MaxOutputTokens = o.MaxOutputTokens
+	}
+	if o.ToolCalls != nil {
+		caps.ToolCalls = *o.ToolCalls
+	}
+	if o.Vision != nil {
+		caps.Vision = *o.Vision
+	}
+	if o.SystemRole != "" {
+		caps.SystemRole = o.SystemRole
+	}
+	if o.MaxCompletionTokens != nil {
+		caps.MaxCompletionTokens = *o.MaxCompletionTokens
+	}
+}
+
+// shapeMessages rewrites system messages for models that need a different
+// role. The input slice is not modified.
+func shapeMessages(messages []Message, systemRole string) []Message {
+	if systemRole == "" || systemRole == config.SystemRoleSystem {
+		return messages
+	}
+
+	shaped := make([]Message, 0, len(messages))
+	var pending []string
+	for _, m := range messages {
+		if m.Role != "system" {
+			if len(pending) > 0 && m.Role == "user" {
+				m.Content = strings.Join(append(pending, m.Content), "\n\n")
+				if len(m.Parts) > 0 {
+					m.Parts = append([]ContentPart{TextPart(strings.Join(pending, "\n\n"))}, m.Parts...)
+				}
+				pending = nil
+			}
+			shaped = append(shaped, m)
+			continue
+		}
+		if systemRole == config.SystemRoleUser {
+			pending = append(pending, m.Content)
+			continue
+		}
+		m.Role = systemRole
+		shaped = append(shaped, m)
+	}
+	if len(pending) > 0 {
+		// No user message to fold into; send the instructions as one
+		log.Printf("No user message for %d system message(s); sending as user", len(pending))
+		shaped = append([]Message{{Role: "user", Content: strings.Join(pending, "\n\n")}}, shaped...)
+	}
+	return shaped
+}
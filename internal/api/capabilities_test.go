@@ -0,0 +1,113 @@
+package api
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/quocvuong92/ai-cli/internal/config"
+)
+
+func TestCapabilityRegistry_Precedence(t *testing.T) {
+	r := NewCapabilityRegistry()
+
+	if caps, known := r.Match("my-local-model"); known || caps != defaultCapabilities {
+		t.Errorf("unknown model = %+v, %v; want defaults", caps, known)
+	}
+	if caps := r.Lookup("o1-mini-2024"); caps.ToolCalls || caps.SystemRole != config.SystemRoleUser {
+		t.Errorf("o1-mini should win over o1, got %+v", caps)
+	}
+
+	// Provider-reported limits replace the table but keep its wire format
+	r.SetLive([]ModelInfo{
+		{ID: "o3-mini", Capabilities: &ModelCapabilities{ContextWindow: 64000, ToolCalls: true}},
+		{ID: "prod-reasoner", Model: "o4-mini"},
+	})
+	caps := r.Lookup("o3-mini")
+	if caps.ContextWindow != 64000 || caps.MaxOutputTokens != 100000 || !caps.MaxCompletionTokens {
+		t.Errorf("live o3-mini = %+v", caps)
+	}
+	if caps := r.Lookup("prod-reasoner"); caps.SystemRole != config.SystemRoleDeveloper {
+		t.Errorf("deployment should resolve through its model, got %+v", caps)
+	}
+
+	no := false
+	r.SetOverrides(map[string]config.ModelCapabilityOverride{
+		"o3*":     {ContextWindow: 32000},
+		"o3-mini": {ToolCalls: &no},
+		"local-*": {ContextWindow: 8192, SystemRole: config.SystemRoleUser},
+	})
+	caps = r.Lookup("o3-mini")
+	if caps.ToolCalls || caps.ContextWindow != 64000 {
+		t.Errorf("exact override should win over prefix, got %+v", caps)
+	}
+	if caps := r.Lookup("o3-pro"); caps.ContextWindow != 32000 {
+		t.Errorf("prefix override not applied, got %+v", caps)
+	}
+	if caps, known := r.Match("local-llama"); !known || caps.ContextWindow != 8192 || !caps.ToolCalls {
+		t.Errorf("override of unknown model = %+v, %v", caps, known)
+	}
+}
+
+func TestModelCapabilities_HistoryBudget(t *testing.T) {
+	tests := []struct {
+		caps ModelCapabilities
+		want int
+	}{
+		{ModelCapabilities{ContextWindow: 128000, MaxOutputTokens: 16000}, 112000},
+		{ModelCapabilities{ContextWindow: 400000, MaxOutputTokens: 128000}, 300000},
+		{ModelCapabilities{ContextWindow: 8000}, 6000},
+	}
+	for _, tt := range tests {
+		if got := tt.caps.HistoryBudget(); got != tt.want {
+			t.Errorf("%+v.HistoryBudget() = %d, want %d", tt.caps, got, tt.want)
+		}
+	}
+}
+
+func TestShapeMessages(t *testing.T) {
+	messages := []Message{
+		{Role: "system", Content: "Be brief."},
+		{Role: "user", Content: "Hi"},
+		{Role: "assistant", Content: "Hello"},
+	}
+
+	if got := shapeMessages(messages, config.SystemRoleSystem); got[0].Role != "system" {
+		t.Errorf("system role changed: %+v", got)
+	}
+	if got := shapeMessages(messages, config.SystemRoleDeveloper); got[0].Role != "developer" || len(got) != 3 {
+		t.Errorf("developer shaping = %+v", got)
+	}
+
+	got := shapeMessages(messages, config.SystemRoleUser)
+	if len(got) != 2 || got[0].Role != "user" || got[0].Content != "Be brief.\n\nHi" {
+		t.Errorf("user shaping = %+v", got)
+	}
+	if messages[0].Role != "system" || messages[1].Content != "Hi" {
+		t.Error("shapeMessages modified its input")
+	}
+
+	got = shapeMessages([]Message{{Role: "system", Content: "Only instructions"}}, config.SystemRoleUser)
+	if len(got) != 1 || got[0].Role != "user" {
+		t.Errorf("lone system message = %+v", got)
+	}
+}
+
+func TestRequest_ToChatRequest_ShapedForModel(t *testing.T) {
+	r := &Request{
+		Model:     "o3-mini",
+		Messages:  []Message{{Role: "system", Content: "sys"}, {Role: "user", Content: "q"}},
+		MaxTokens: 256,
+	}
+	data, err := json.Marshal(r.toChatRequest("default", false))
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	body := string(data)
+	if !strings.Contains(body, `"max_completion_tokens":256`) || strings.Contains(body, `"max_tokens"`) {
+		t.Errorf("o3-mini body should use max_completion_tokens: %s", body)
+	}
+	if !strings.Contains(body, `"role":"developer"`) {
+		t.Errorf("o3-mini body should use the developer role: %s", body)
+	}
+}
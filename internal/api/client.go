@@ -1,5 +1,5 @@
 // Package api provides unified AI client interfaces for multiple providers.
-// It supports GitHub Copilot and Azure OpenAI with automatic provider detection,
+// It supports GitHub Copilot, Azure OpenAI, Anthropic and OpenAI-compatible servers with automatic provider detection,
 // streaming responses, tool/function calling, and retry logic for transient failures.
 package api
 
@@ -11,10 +11,24 @@ import (
 	"github.com/quocvuong92/ai-cli/internal/config"
 )
 
+// Provider is the minimal interface a chat backend implements.
+// Everything else AIClient offers is derived from these two methods by ClientAdapter.
+type Provider interface {
+	// Complete sends a request and returns the full response (non-streaming)
+	Complete(ctx context.Context, req Request) (*ChatResponse, error)
+
+	// Stream sends a request and delivers the response through handler as it arrives
+	Stream(ctx context.Context, req Request, handler StreamHandler) error
+}
+
 // AIClient defines the interface for AI API clients.
-// Both CopilotClient and AzureClient implement this interface,
+// CopilotClient, AzureClient, OpenAIClient and AnthropicClient implement this interface,
 // allowing transparent switching between providers.
+// Implementations only need Complete, Stream and Close; the Query* methods
+// come from embedding ClientAdapter (or wrapping a Provider with NewClientAdapter).
 type AIClient interface {
+	Provider
+
 	// Query sends a simple query (non-streaming)
 	Query(systemPrompt, userMessage string) (*ChatResponse, error)
 
@@ -49,18 +63,30 @@ type AIClient interface {
 	Close()
 }
 
-// Ensure both clients implement AIClient interface
+// Ensure all clients implement AIClient interface
 var _ AIClient = (*AzureClient)(nil)
 var _ AIClient = (*CopilotClient)(nil)
+var _ AIClient = (*OpenAIClient)(nil)
+var _ AIClient = (*AnthropicClient)(nil)
+var _ AIClient = (*FallbackClient)(nil)
+var _ AIClient = (*CachingClient)(nil)
+var _ AIClient = (*UsageClient)(nil)
+var _ AIClient = (*ClientAdapter)(nil)
 
 // NewClient creates an AI client based on configuration.
-// Provider selection follows this priority:
-//  1. Explicit provider in cfg.Provider ("copilot", "github", or "azure")
-//  2. Auto-detect: GitHub Copilot if logged in, otherwise Azure if configured
+// If cfg.FallbackChain is set, a FallbackClient over the chain is returned.
+// Otherwise provider selection follows this priority:
+//  1. Explicit provider in cfg.Provider ("copilot", "github", "azure", "openai", or "anthropic")
+//  2. Auto-detect: GitHub Copilot if logged in, otherwise Azure if configured,
+//     otherwise an OpenAI-compatible server if a base URL is configured
 //
 // For Copilot, it automatically manages token refresh in the background.
 // Returns an error if no provider is available or configured.
 func NewClient(cfg *config.Config) (AIClient, error) {
+	if len(cfg.FallbackChain) > 0 {
+		return NewFallbackClient(cfg)
+	}
+
 	switch cfg.Provider {
 	case "copilot", "github":
 		// Load GitHub token
@@ -83,6 +109,18 @@ func NewClient(cfg *config.Config) (AIClient, error) {
 		}
 		return NewAzureClient(cfg), nil
 
+	case "openai":
+		if cfg.OpenAIBaseURL == "" {
+			return nil, fmt.Errorf("OpenAI provider requires OPENAI_BASE_URL or openai.base_url in config")
+		}
+		return NewOpenAIClient(cfg), nil
+
+	case "anthropic":
+		if cfg.AnthropicAPIKey == "" {
+			return nil, fmt.Errorf("Anthropic provider requires ANTHROPIC_API_KEY")
+		}
+		return NewAnthropicClient(cfg), nil
+
 	default:
 		// Auto-detect: prefer Copilot if logged in, otherwise Azure
 		if auth.IsLoggedIn() {
@@ -96,7 +134,11 @@ func NewClient(cfg *config.Config) (AIClient, error) {
 			return NewAzureClient(cfg), nil
 		}
 
-		return nil, fmt.Errorf("no AI provider configured. Run 'ai login' for GitHub Copilot or set AZURE_OPENAI_* environment variables")
+		if cfg.OpenAIBaseURL != "" {
+			return NewOpenAIClient(cfg), nil
+		}
+
+		return nil, fmt.Errorf("no AI provider configured. Run 'ai login' for GitHub Copilot or set AZURE_OPENAI_* or OPENAI_BASE_URL environment variables")
 	}
 }
 
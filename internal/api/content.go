@@ -0,0 +1,190 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MaxImageSize limits the size of an attached image (the limit of the
+// OpenAI and Copilot vision APIs)
+const MaxImageSize = 20 * 1024 * 1024
+
+// Content part types
+const (
+	PartText  = "text"
+	PartImage = "image_url"
+)
+
+// supportedImageTypes are the image formats vision models accept
+var supportedImageTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// ContentPart is one part of a multimodal message
+type ContentPart struct {
+	Type     string    `json:"type"`
+	Text     string    `json:"text,omitempty"`
+	ImageURL *ImageURL `json:"image_url,omitempty"`
+}
+
+// ImageURL is an image reference, usually a base64 data URL
+type ImageURL struct {
+	URL    string `json:"url"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// TextPart creates a text content part
+func TextPart(text string) ContentPart {
+	return ContentPart{Type: PartText, Text: text}
+}
+
+// ImagePart creates an image content part from a URL or data URL
+func ImagePart(url string) ContentPart {
+	return ContentPart{Type: PartImage, ImageURL: &ImageURL{URL: url}}
+}
+
+// LoadImage reads an image file into a data URL content part
+func LoadImage(path string) (ContentPart, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return ContentPart{}, fmt.Errorf("image not found: %s", path)
+		}
+		return ContentPart{}, err
+	}
+	if info.IsDir() {
+		return ContentPart{}, fmt.Errorf("%s is a directory", path)
+	}
+	if info.Size() > MaxImageSize {
+		return ContentPart{}, fmt.Errorf("%s is too large (%d MB max)", path, MaxImageSize/(1024*1024))
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ContentPart{}, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	mediaType := http.DetectContentType(data)
+	if !supportedImageTypes[mediaType] {
+		return ContentPart{}, fmt.Errorf("%s is not a PNG, JPEG, GIF or WebP image (%s)", filepath.Base(path), mediaType)
+	}
+	return ImagePart("data:" + mediaType + ";base64," + base64.StdEncoding.EncodeToString(data)), nil
+}
+
+// ParseDataURL splits a base64 data URL into its media type and data
+func ParseDataURL(url string) (mediaType, data string, ok bool) {
+	rest, ok := strings.CutPrefix(url, "data:")
+	if !ok {
+		return "", "", false
+	}
+	mediaType, data, ok = strings.Cut(rest, ";base64,")
+	return mediaType, data, ok
+}
+
+// NewUserMessage creates a user message with text and, if any, image parts
+func NewUserMessage(text string, images []ContentPart) Message {
+	msg := Message{Role: "user", Content: text}
+	if len(images) > 0 {
+		msg.Parts = append([]ContentPart{TextPart(text)}, images...)
+	}
+	return msg
+}
+
+// Images returns the message's image parts
+func (m Message) Images() []ContentPart {
+	var images []ContentPart
+	for _, p := range m.Parts {
+		if p.Type == PartImage && p.ImageURL != nil {
+			images = append(images, p)
+		}
+	}
+	return images
+}
+
+// hasImages reports whether any message carries an image
+func hasImages(messages []Message) bool {
+	for _, m := range messages {
+		if len(m.Images()) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// messageJSON is the wire form of Message, whose content is a string or
+// an array of parts
+type messageJSON struct {
+	Role       string          `json:"role"`
+	Content    json.RawMessage `json:"content,omitempty"`
+	ToolCalls  []ToolCall      `json:"tool_calls,omitempty"`
+	ToolCallID string          `json:"tool_call_id,omitempty"`
+}
+
+// messageReasoningJSON holds the reasoning fields providers add to
+// response messages
+type messageReasoningJSON struct {
+	ReasoningContent string `json:"reasoning_content"`
+	Reasoning        string `json:"reasoning"`
+	ReasoningText    string `json:"reasoning_text"`
+}
+
+// MarshalJSON sends Parts as the content array when the message has them,
+// and Content as a plain string otherwise
+func (m Message) MarshalJSON() ([]byte, error) {
+	out := messageJSON{Role: m.Role, ToolCalls: m.ToolCalls, ToolCallID: m.ToolCallID}
+	var err error
+	switch {
+	case len(m.Parts) > 0:
+		out.Content, err = json.Marshal(m.Parts)
+	case m.Content != "":
+		out.Content, err = json.Marshal(m.Content)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON accepts string or array content. For arrays, Content is set
+// to the joined text parts.
+func (m *Message) UnmarshalJSON(data []byte) error {
+	var in messageJSON
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	*m = Message{Role: in.Role, ToolCalls: in.ToolCalls, ToolCallID: in.ToolCallID}
+
+	var reasoning messageReasoningJSON
+	if err := json.Unmarshal(data, &reasoning); err == nil {
+		m.Reasoning = Delta{
+			ReasoningContent: reasoning.ReasoningContent,
+			Reasoning:        reasoning.Reasoning,
+			ReasoningTextRaw: reasoning.ReasoningText,
+		}.ReasoningText()
+	}
+
+	if len(in.Content) == 0 || string(in.Content) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(in.Content, &m.Content); err == nil {
+		return nil
+	}
+	if err := json.Unmarshal(in.Content, &m.Parts); err != nil {
+		return fmt.Errorf("content must be a string or an array of content parts")
+	}
+	var texts []string
+	for _, p := range m.Parts {
+		if p.Type == PartText {
+			texts = append(texts, p.Text)
+		}
+	}
+	m.Content = strings.Join(texts, "\n")
+	return nil
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// pngHeader is enough of a PNG for content sniffing
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func TestMessage_JSONRoundTrip(t *testing.T) {
+	plain := Message{Role: "user", Content: "hi"}
+	data, _ := json.Marshal(plain)
+	if string(data) != `{"role":"user","content":"hi"}` {
+		t.Errorf("plain message = %s", data)
+	}
+
+	msg := NewUserMessage("what is this?", []ContentPart{ImagePart("data:image/png;base64,AAAA")})
+	data, err := json.Marshal(msg)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	want := `{"role":"user","content":[{"type":"text","text":"what is this?"},{"type":"image_url","image_url":{"url":"data:image/png;base64,AAAA"}}]}`
+	if string(data) != want {
+		t.Errorf("multimodal message = %s, want %s", data, want)
+	}
+
+	var decoded Message
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if decoded.Content != "what is this?" || len(decoded.Images()) != 1 {
+		t.Errorf("decoded = %+v", decoded)
+	}
+
+	if err := json.Unmarshal([]byte(`{"role":"assistant","content":null}`), &decoded); err != nil || decoded.Content != "" || decoded.Parts != nil {
+		t.Errorf("null content = %+v, %v", decoded, err)
+	}
+}
+
+func TestLoadImage(t *testing.T) {
+	dir := t.TempDir()
+	png := filepath.Join(dir, "shot.png")
+	if err := os.WriteFile(png, pngHeader, 0644); err != nil {
+		t.Fatal(err)
+	}
+	part, err := LoadImage(png)
+	if err != nil {
+		t.Fatalf("LoadImage() error = %v", err)
+	}
+	mediaType, data, ok := ParseDataURL(part.ImageURL.URL)
+	if !ok || mediaType != "image/png" || data == "" {
+		t.Errorf("LoadImage() url = %.40s", part.ImageURL.URL)
+	}
+
+	text := filepath.Join(dir, "notes.txt")
+	_ = os.WriteFile(text, []byte("just text"), 0644)
+	if _, err := LoadImage(text); err == nil || !strings.Contains(err.Error(), "not a PNG") {
+		t.Errorf("LoadImage(text) error = %v", err)
+	}
+	if _, err := LoadImage(filepath.Join(dir, "missing.png")); err == nil {
+		t.Error("LoadImage(missing) should fail")
+	}
+}
+
+func TestToAnthropicMessages_Images(t *testing.T) {
+	msg := NewUserMessage("describe", []ContentPart{ImagePart("data:image/jpeg;base64,QUJD")})
+	_, converted := toAnthropicMessages([]Message{msg})
+	if len(converted) != 1 || len(converted[0].Content) != 2 {
+		t.Fatalf("converted = %+v", converted)
+	}
+	img := converted[0].Content[1]
+	if img.Type != "image" || img.Source == nil || img.Source.MediaType != "image/jpeg" || img.Source.Data != "QUJD" {
+		t.Errorf("image block = %+v", img)
+	}
+}
+
+func TestHasImages(t *testing.T) {
+	if hasImages([]Message{{Role: "user", Content: "hi"}}) {
+		t.Error("plain messages reported images")
+	}
+	if !hasImages([]Message{{Role: "system"}, NewUserMessage("", []ContentPart{ImagePart("https://example.com/a.png")})}) {
+		t.Error("image message not detected")
+	}
+}
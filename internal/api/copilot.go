@@ -11,11 +11,11 @@ import (
 	"github.com/google/uuid"
 	"github.com/quocvuong92/ai-cli/internal/auth"
 	"github.com/quocvuong92/ai-cli/internal/config"
-	"github.com/quocvuong92/ai-cli/internal/constants"
 )
 
 // CopilotClient is the GitHub Copilot API client
 type CopilotClient struct {
+	ClientAdapter
 	httpClient   *http.Client
 	config       *config.Config
 	tokenManager *auth.TokenManager
@@ -23,13 +23,13 @@ type CopilotClient struct {
 
 // NewCopilotClient creates a new GitHub Copilot client
 func NewCopilotClient(cfg *config.Config, tokenManager *auth.TokenManager) *CopilotClient {
-	return &CopilotClient{
-		httpClient: &http.Client{
-			Timeout: constants.DefaultAPITimeout,
-		},
+	c := &CopilotClient{
+		httpClient:   newAPIHTTPClient(),
 		config:       cfg,
 		tokenManager: tokenManager,
 	}
+	c.ClientAdapter = ClientAdapter{Provider: c}
+	return c
 }
 
 // getBaseURL returns the Copilot API base URL
@@ -50,62 +50,32 @@ func (c *CopilotClient) buildHeaders(ctx context.Context, enableVision bool) (ma
 	return headers, nil
 }
 
-// Query sends a query to Copilot (non-streaming)
-func (c *CopilotClient) Query(systemPrompt, userMessage string) (*ChatResponse, error) {
-	return c.QueryWithContext(context.Background(), systemPrompt, userMessage)
-}
-
-// QueryWithContext sends a query to Copilot with context support (non-streaming)
-func (c *CopilotClient) QueryWithContext(ctx context.Context, systemPrompt, userMessage string) (*ChatResponse, error) {
-	messages := []Message{
-		{Role: "system", Content: systemPrompt},
-		{Role: "user", Content: userMessage},
+// initiator returns the X-Initiator header value: "agent" once the
+// conversation contains assistant or tool turns, "user" otherwise
+func initiator(messages []Message) string {
+	for _, msg := range messages {
+		if msg.Role == "assistant" || msg.Role == "tool" {
+			return "agent"
+		}
 	}
-	return c.QueryWithHistoryContext(ctx, messages)
+	return "user"
 }
 
-// QueryWithHistory sends a query with full message history (non-streaming)
-func (c *CopilotClient) QueryWithHistory(messages []Message) (*ChatResponse, error) {
-	return c.QueryWithHistoryContext(context.Background(), messages)
-}
-
-// QueryWithHistoryContext sends a query with full message history and context support (non-streaming)
-func (c *CopilotClient) QueryWithHistoryContext(ctx context.Context, messages []Message) (*ChatResponse, error) {
-	return c.QueryWithHistoryAndToolsContext(ctx, messages, nil)
-}
-
-// QueryWithHistoryAndToolsContext sends a query with full message history, tools, and context support (non-streaming)
-func (c *CopilotClient) QueryWithHistoryAndToolsContext(ctx context.Context, messages []Message, tools []Tool) (*ChatResponse, error) {
-	reqBody := ChatRequest{
-		Model:    c.config.Model,
-		Messages: messages,
-		Tools:    tools,
-		Stream:   false,
-	}
+// Complete sends a request to Copilot (non-streaming)
+func (c *CopilotClient) Complete(ctx context.Context, r Request) (*ChatResponse, error) {
+	reqBody := r.toChatRequest(c.config.Model, false)
 
 	jsonData, err := json.Marshal(reqBody)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	headers, err := c.buildHeaders(ctx, false)
+	headers, err := c.buildHeaders(ctx, hasImages(r.Messages))
 	if err != nil {
 		return nil, err
 	}
 
-	// Set X-Initiator based on message roles
-	hasAgentMessages := false
-	for _, msg := range messages {
-		if msg.Role == "assistant" || msg.Role == "tool" {
-			hasAgentMessages = true
-			break
-		}
-	}
-	if hasAgentMessages {
-		headers["X-Initiator"] = "agent"
-	} else {
-		headers["X-Initiator"] = "user"
-	}
+	headers["X-Initiator"] = initiator(r.Messages)
 
 	url := c.getBaseURL() + "/chat/completions"
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(jsonData))
@@ -140,62 +110,21 @@ func (c *CopilotClient) QueryWithHistoryAndToolsContext(ctx context.Context, mes
 	return &chatResp, nil
 }
 
-// QueryStream sends a streaming query to Copilot
-func (c *CopilotClient) QueryStream(systemPrompt, userMessage string, onChunk func(content string), onDone func(resp *ChatResponse)) error {
-	return c.QueryStreamWithContext(context.Background(), systemPrompt, userMessage, onChunk, onDone)
-}
-
-// QueryStreamWithContext sends a streaming query to Copilot with context support
-func (c *CopilotClient) QueryStreamWithContext(ctx context.Context, systemPrompt, userMessage string, onChunk func(content string), onDone func(resp *ChatResponse)) error {
-	messages := []Message{
-		{Role: "system", Content: systemPrompt},
-		{Role: "user", Content: userMessage},
-	}
-	return c.QueryStreamWithHistoryContext(ctx, messages, onChunk, onDone)
-}
-
-// QueryStreamWithHistory sends a streaming query with full message history
-func (c *CopilotClient) QueryStreamWithHistory(messages []Message, onChunk func(content string), onDone func(resp *ChatResponse)) error {
-	return c.QueryStreamWithHistoryContext(context.Background(), messages, onChunk, onDone)
-}
-
-// QueryStreamWithHistoryContext sends a streaming query with full message history and context support
-func (c *CopilotClient) QueryStreamWithHistoryContext(ctx context.Context, messages []Message, onChunk func(content string), onDone func(resp *ChatResponse)) error {
-	return c.QueryStreamWithHistoryAndToolsContext(ctx, messages, nil, onChunk, onDone)
-}
-
-// QueryStreamWithHistoryAndToolsContext sends a streaming query with full message history, tools, and context support
-func (c *CopilotClient) QueryStreamWithHistoryAndToolsContext(ctx context.Context, messages []Message, tools []Tool, onChunk func(content string), onDone func(resp *ChatResponse)) error {
-	reqBody := ChatRequest{
-		Model:    c.config.Model,
-		Messages: messages,
-		Tools:    tools,
-		Stream:   true,
-	}
+// Stream sends a streaming request to Copilot
+func (c *CopilotClient) Stream(ctx context.Context, r Request, handler StreamHandler) error {
+	reqBody := r.toChatRequest(c.config.Model, true)
 
 	jsonData, err := json.Marshal(reqBody)
 	if err != nil {
 		return fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	headers, err := c.buildHeaders(ctx, false)
+	headers, err := c.buildHeaders(ctx, hasImages(r.Messages))
 	if err != nil {
 		return err
 	}
 
-	// Set X-Initiator based on message roles
-	hasAgentMessages := false
-	for _, msg := range messages {
-		if msg.Role == "assistant" || msg.Role == "tool" {
-			hasAgentMessages = true
-			break
-		}
-	}
-	if hasAgentMessages {
-		headers["X-Initiator"] = "agent"
-	} else {
-		headers["X-Initiator"] = "user"
-	}
+	headers["X-Initiator"] = initiator(r.Messages)
 
 	url := c.getBaseURL() + "/chat/completions"
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(jsonData))
@@ -212,22 +141,22 @@ func (c *CopilotClient) QueryStreamWithHistoryAndToolsContext(ctx context.Contex
 	if err != nil {
 		return fmt.Errorf("failed to send request: %w", err)
 	}
-	defer func() { _ = resp.Body.Close() }()
-
 	if resp.StatusCode != http.StatusOK {
+		defer func() { _ = resp.Body.Close() }()
 		body, _ := io.ReadAll(resp.Body)
 		return c.handleError(resp.StatusCode, body)
 	}
 
 	// Process SSE stream using shared processor
-	processor := NewSSEProcessor(resp.Body)
-	if err := processor.Process(ctx, onChunk); err != nil {
-		return fmt.Errorf("failed to process stream: %w", err)
+	newProcessor := func(r io.Reader) StreamProcessor { return NewSSEProcessor(r) }
+	final, err := consumeStream(ctx, resp.Body, newProcessor, handler)
+	if err != nil {
+		return streamError(final, err)
 	}
 
 	// Build and return final response
-	if onDone != nil {
-		onDone(processor.BuildResponse())
+	if handler.OnDone != nil {
+		handler.OnDone(final)
 	}
 
 	return nil
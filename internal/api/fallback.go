@@ -0,0 +1,218 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+
+	"github.com/quocvuong92/ai-cli/internal/config"
+)
+
+// fallbackBackend is one client in the fallback chain
+type fallbackBackend struct {
+	name   string // "provider:model" label for display
+	client AIClient
+}
+
+// FallbackClient wraps an ordered list of providers and fails over to the
+// next one when a request fails with a configured error class.
+// Messages stay in the shared OpenAI-style format, so tool-call conversations
+// continue across a fail-over; each backend translates them for its own API.
+type FallbackClient struct {
+	ClientAdapter
+	backends    []fallbackBackend
+	classes     map[string]bool
+	mu          sync.Mutex // Guards lastBackend for concurrent requests (ai-cli serve)
+	lastBackend string
+	onFailover  func(from, to string, err error)
+	onAnswered  func(name string)
+}
+
+// NewFallbackClient creates a client for each entry of cfg.FallbackChain.
+// Entries that cannot be constructed (e.g. missing credentials) are skipped
+// with a log message; an error is returned only if none are usable.
+func NewFallbackClient(cfg *config.Config) (*FallbackClient, error) {
+	fc := &FallbackClient{
+		classes: make(map[string]bool),
+	}
+	for _, class := range cfg.FailoverClasses {
+		fc.classes[class] = true
+	}
+
+	var lastErr error
+	for _, target := range cfg.FallbackChain {
+		// Each backend gets its own config copy so provider and model don't leak
+		backendCfg := *cfg
+		backendCfg.FallbackChain = nil
+		backendCfg.Provider = target.Provider
+		if target.Model != "" {
+			backendCfg.Model = target.Model
+		}
+
+		client, err := NewClient(&backendCfg)
+		if err != nil {
+			log.Printf("Skipping fallback backend %s: %v", target, err)
+			lastErr = err
+			continue
+		}
+		fc.backends = append(fc.backends, fallbackBackend{
+			name:   backendCfg.Provider + ":" + backendCfg.Model,
+			client: client,
+		})
+	}
+
+	if len(fc.backends) == 0 {
+		return nil, fmt.Errorf("no usable backend in fallback chain: %w", lastErr)
+	}
+	fc.ClientAdapter = ClientAdapter{Provider: fc}
+	return fc, nil
+}
+
+// SetFailoverCallback sets a callback invoked when switching to the next backend
+func (c *FallbackClient) SetFailoverCallback(callback func(from, to string, err error)) {
+	c.onFailover = callback
+}
+
+// SetAnsweredCallback sets a callback invoked when a backend other than the
+// first one in the chain answers a request
+func (c *FallbackClient) SetAnsweredCallback(callback func(name string)) {
+	c.onAnswered = callback
+}
+
+// LastBackend returns the "provider:model" label of the backend that answered last
+func (c *FallbackClient) LastBackend() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lastBackend
+}
+
+// Backends returns the "provider:model" labels of the chain in order
+func (c *FallbackClient) Backends() []string {
+	names := make([]string, len(c.backends))
+	for i, b := range c.backends {
+		names[i] = b.name
+	}
+	return names
+}
+
+// FailoverClass classifies an error into one of the config.Failover* classes.
+// Returns "" for errors that must never fail over, such as cancellation.
+func FailoverClass(err error) string {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return ""
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return config.FailoverNetwork
+	}
+
+	switch {
+	case apiErr.StatusCode == http.StatusTooManyRequests:
+		return config.FailoverRateLimit
+	case apiErr.StatusCode == http.StatusUnauthorized || apiErr.StatusCode == http.StatusForbidden:
+		return config.FailoverAuth
+	case apiErr.StatusCode >= 500:
+		return config.FailoverServerError
+	case apiErr.StatusCode >= 400:
+		return config.FailoverClientError
+	default:
+		return ""
+	}
+}
+
+// shouldFailover reports whether err belongs to a configured failover class
+func (c *FallbackClient) shouldFailover(err error) bool {
+	class := FailoverClass(err)
+	return class != "" && c.classes[class]
+}
+
+// run tries each backend in order until one succeeds or fails with an error
+// that is not configured for failover.
+func (c *FallbackClient) run(ctx context.Context, call func(backend fallbackBackend) (bool, error)) error {
+	var err error
+	for i, backend := range c.backends {
+		var started bool
+		started, err = call(backend)
+		if err == nil {
+			c.mu.Lock()
+			c.lastBackend = backend.name
+			c.mu.Unlock()
+			log.Printf("Response from fallback backend %s", backend.name)
+			if i > 0 && c.onAnswered != nil {
+				c.onAnswered(backend.name)
+			}
+			return nil
+		}
+
+		// Output already reached the caller, or the error isn't a failover class
+		if started || ctx.Err() != nil || !c.shouldFailover(err) || i == len(c.backends)-1 {
+			return err
+		}
+
+		if c.onFailover != nil {
+			c.onFailover(backend.name, c.backends[i+1].name, err)
+		}
+	}
+	return err
+}
+
+// Complete sends a request to the first backend that answers (non-streaming).
+// The response names the backend, since LastBackend may already belong to
+// another request when several run at once.
+func (c *FallbackClient) Complete(ctx context.Context, r Request) (*ChatResponse, error) {
+	var resp *ChatResponse
+	err := c.run(ctx, func(backend fallbackBackend) (bool, error) {
+		var err error
+		resp, err = backend.client.Complete(ctx, r)
+		if resp != nil {
+			resp.Backend = backend.name
+		}
+		return false, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Stream sends a streaming request to the first backend that answers.
+// Fail-over only happens before the first chunk; once content has been
+// streamed to the caller, switching backends would duplicate output. The
+// final response names the backend, as with Complete.
+func (c *FallbackClient) Stream(ctx context.Context, r Request, handler StreamHandler) error {
+	return c.run(ctx, func(backend fallbackBackend) (bool, error) {
+		started := false
+		err := backend.client.Stream(ctx, r, StreamHandler{
+			OnChunk: func(content string) {
+				started = true
+				handler.OnChunk(content)
+			},
+			OnReasoning: func(content string) {
+				started = true
+				if handler.OnReasoning != nil {
+					handler.OnReasoning(content)
+				}
+			},
+			OnDone: func(resp *ChatResponse) {
+				if resp != nil {
+					resp.Backend = backend.name
+				}
+				if handler.OnDone != nil {
+					handler.OnDone(resp)
+				}
+			},
+		})
+		return started, err
+	})
+}
+
+// Close releases resources held by every backend
+func (c *FallbackClient) Close() {
+	for _, b := range c.backends {
+		b.client.Close()
+	}
+}
@@ -0,0 +1,205 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/quocvuong92/ai-cli/internal/config"
+)
+
+// stubClient is a Provider that returns canned results and records calls
+type stubClient struct {
+	resp     *ChatResponse
+	err      error
+	chunks   []string
+	calls    int
+	messages []Message
+	closed   bool
+}
+
+func (s *stubClient) Complete(ctx context.Context, r Request) (*ChatResponse, error) {
+	s.calls++
+	s.messages = r.Messages
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.resp, nil
+}
+
+func (s *stubClient) Stream(ctx context.Context, r Request, handler StreamHandler) error {
+	s.calls++
+	s.messages = r.Messages
+	for _, c := range s.chunks {
+		handler.OnChunk(c)
+	}
+	if s.err != nil {
+		return s.err
+	}
+	if handler.OnDone != nil {
+		handler.OnDone(s.resp)
+	}
+	return nil
+}
+
+func (s *stubClient) Close() {
+	s.closed = true
+}
+
+// newTestFallbackClient builds a FallbackClient over stub backends
+func newTestFallbackClient(classes []string, stubs ...*stubClient) *FallbackClient {
+	fc := &FallbackClient{classes: make(map[string]bool)}
+	fc.ClientAdapter = ClientAdapter{Provider: fc}
+	for _, class := range classes {
+		fc.classes[class] = true
+	}
+	for i, s := range stubs {
+		fc.backends = append(fc.backends, fallbackBackend{name: fmt.Sprintf("stub:%d", i), client: NewClientAdapter(s)})
+	}
+	return fc
+}
+
+func textResponse(content string) *ChatResponse {
+	return &ChatResponse{Choices: []Choice{{Message: Message{Role: "assistant", Content: content}}}}
+}
+
+func TestFailoverClass(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"rate limited", &APIError{StatusCode: http.StatusTooManyRequests}, config.FailoverRateLimit},
+		{"wrapped rate limit", fmt.Errorf("max retry attempts (3) exceeded: %w", &APIError{StatusCode: 429}), config.FailoverRateLimit},
+		{"server error", &APIError{StatusCode: http.StatusBadGateway}, config.FailoverServerError},
+		{"overloaded", &APIError{StatusCode: 529}, config.FailoverServerError},
+		{"unauthorized", &APIError{StatusCode: http.StatusUnauthorized}, config.FailoverAuth},
+		{"bad request", &APIError{StatusCode: http.StatusBadRequest}, config.FailoverClientError},
+		{"network", errors.New("failed to send request: connection refused"), config.FailoverNetwork},
+		{"cancelled", fmt.Errorf("operation cancelled: %w", context.Canceled), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FailoverClass(tt.err); got != tt.want {
+				t.Errorf("FailoverClass() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFallbackClient_FailsOverOnRateLimit(t *testing.T) {
+	primary := &stubClient{err: &APIError{StatusCode: http.StatusTooManyRequests, Message: "Rate limited"}}
+	secondary := &stubClient{resp: textResponse("from secondary")}
+	fc := newTestFallbackClient(config.DefaultFailoverClasses, primary, secondary)
+
+	var failedFrom, failedTo, answered string
+	fc.SetFailoverCallback(func(from, to string, err error) { failedFrom, failedTo = from, to })
+	fc.SetAnsweredCallback(func(name string) { answered = name })
+
+	resp, err := fc.QueryWithHistory([]Message{{Role: "user", Content: "hi"}})
+	if err != nil {
+		t.Fatalf("QueryWithHistory() error = %v", err)
+	}
+	if resp.GetContent() != "from secondary" || resp.Backend != "stub:1" {
+		t.Errorf("GetContent() = %q, Backend = %q; want %q from stub:1", resp.GetContent(), resp.Backend, "from secondary")
+	}
+	if failedFrom != "stub:0" || failedTo != "stub:1" {
+		t.Errorf("failover callback = %q -> %q, want stub:0 -> stub:1", failedFrom, failedTo)
+	}
+	if answered != "stub:1" || fc.LastBackend() != "stub:1" {
+		t.Errorf("answered = %q, LastBackend() = %q, want stub:1", answered, fc.LastBackend())
+	}
+}
+
+func TestFallbackClient_NoFailoverForUnconfiguredClass(t *testing.T) {
+	primary := &stubClient{err: &APIError{StatusCode: http.StatusBadRequest, Message: "bad request"}}
+	secondary := &stubClient{resp: textResponse("unused")}
+	fc := newTestFallbackClient(config.DefaultFailoverClasses, primary, secondary)
+
+	_, err := fc.QueryWithHistory([]Message{{Role: "user", Content: "hi"}})
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if secondary.calls != 0 {
+		t.Errorf("secondary called %d times, want 0", secondary.calls)
+	}
+}
+
+func TestFallbackClient_AllBackendsFail(t *testing.T) {
+	primary := &stubClient{err: &APIError{StatusCode: http.StatusServiceUnavailable, Message: "down"}}
+	secondary := &stubClient{err: &APIError{StatusCode: http.StatusServiceUnavailable, Message: "also down"}}
+	fc := newTestFallbackClient(config.DefaultFailoverClasses, primary, secondary)
+
+	_, err := fc.QueryWithHistory([]Message{{Role: "user", Content: "hi"}})
+	if err == nil || err.Error() != "also down" {
+		t.Errorf("error = %v, want last backend's error", err)
+	}
+}
+
+func TestFallbackClient_ToolConversationSurvivesFailover(t *testing.T) {
+	primary := &stubClient{err: errors.New("connection reset")}
+	secondary := &stubClient{resp: textResponse("done")}
+	fc := newTestFallbackClient(config.DefaultFailoverClasses, primary, secondary)
+
+	tc := ToolCall{ID: "call_1", Type: "function"}
+	tc.Function.Name = "read_file"
+	tc.Function.Arguments = `{"path":"go.mod"}`
+	messages := []Message{
+		{Role: "user", Content: "read go.mod"},
+		{Role: "assistant", ToolCalls: []ToolCall{tc}},
+		{Role: "tool", Content: "module x", ToolCallID: "call_1"},
+	}
+
+	if _, err := fc.QueryWithHistoryAndToolsContext(context.Background(), messages, GetDefaultTools()); err != nil {
+		t.Fatalf("QueryWithHistoryAndToolsContext() error = %v", err)
+	}
+	if len(secondary.messages) != 3 || secondary.messages[2].ToolCallID != "call_1" {
+		t.Errorf("secondary received %+v, want the full tool conversation", secondary.messages)
+	}
+}
+
+func TestFallbackClient_StreamNoFailoverAfterOutput(t *testing.T) {
+	primary := &stubClient{chunks: []string{"partial"}, err: errors.New("stream dropped")}
+	secondary := &stubClient{chunks: []string{"full"}, resp: textResponse("full")}
+	fc := newTestFallbackClient(config.DefaultFailoverClasses, primary, secondary)
+
+	var got string
+	err := fc.QueryStreamWithHistory([]Message{{Role: "user", Content: "hi"}},
+		func(content string) { got += content }, nil)
+	if err == nil {
+		t.Fatal("expected error after partial stream, got nil")
+	}
+	if got != "partial" || secondary.calls != 0 {
+		t.Errorf("output = %q, secondary calls = %d; want no failover after output", got, secondary.calls)
+	}
+}
+
+func TestFallbackClient_StreamFailoverBeforeOutput(t *testing.T) {
+	primary := &stubClient{err: &APIError{StatusCode: http.StatusTooManyRequests, Message: "Rate limited"}}
+	secondary := &stubClient{chunks: []string{"hello"}, resp: textResponse("hello")}
+	fc := newTestFallbackClient(config.DefaultFailoverClasses, primary, secondary)
+
+	var got string
+	var final *ChatResponse
+	err := fc.QueryStreamWithHistory([]Message{{Role: "user", Content: "hi"}},
+		func(content string) { got += content },
+		func(resp *ChatResponse) { final = resp })
+	if err != nil {
+		t.Fatalf("QueryStreamWithHistory() error = %v", err)
+	}
+	if got != "hello" || final == nil || final.Backend != "stub:1" {
+		t.Errorf("output = %q, final = %+v; want secondary's stream", got, final)
+	}
+}
+
+func TestFallbackClient_Close(t *testing.T) {
+	a, b := &stubClient{}, &stubClient{}
+	fc := newTestFallbackClient(nil, a, b)
+	fc.Close()
+	if !a.closed || !b.closed {
+		t.Error("Close() should close every backend")
+	}
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/quocvuong92/ai-cli/internal/constants"
+)
+
+// ErrStreamIdle is returned when a stream stops sending data for longer
+// than StreamIdleTimeout
+var ErrStreamIdle = errors.New("stream idle timeout")
+
+// StreamIdleTimeout is how long a stream may go without data before it is
+// abandoned. A long answer is fine as long as it keeps arriving.
+var StreamIdleTimeout = constants.DefaultStreamIdleTimeout
+
+// newAPIHTTPClient creates the HTTP client for AI API requests. There is no
+// overall timeout, which would cut off long streams; the response headers
+// must arrive within DefaultAPITimeout and streams are bounded by
+// StreamIdleTimeout instead.
+func newAPIHTTPClient() *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = constants.DefaultAPITimeout
+	return &http.Client{Transport: transport}
+}
+
+// idleTimeoutReader closes the underlying body when no data has been read
+// for the timeout, unblocking the pending Read
+type idleTimeoutReader struct {
+	body     io.ReadCloser
+	timeout  time.Duration
+	timer    *time.Timer
+	timedOut atomic.Bool
+}
+
+// newIdleTimeoutReader wraps body so that Read fails with ErrStreamIdle once
+// timeout passes without data
+func newIdleTimeoutReader(body io.ReadCloser, timeout time.Duration) *idleTimeoutReader {
+	r := &idleTimeoutReader{body: body, timeout: timeout}
+	r.timer = time.AfterFunc(timeout, func() {
+		r.timedOut.Store(true)
+		_ = body.Close()
+	})
+	return r
+}
+
+// Read reads from the body, restarting the idle timer on each read
+func (r *idleTimeoutReader) Read(p []byte) (int, error) {
+	n, err := r.body.Read(p)
+	if r.timedOut.Load() {
+		return n, fmt.Errorf("%w: no data for %s", ErrStreamIdle, r.timeout)
+	}
+	r.timer.Reset(r.timeout)
+	return n, err
+}
+
+// Close stops the timer and closes the body
+func (r *idleTimeoutReader) Close() error {
+	r.timer.Stop()
+	return r.body.Close()
+}
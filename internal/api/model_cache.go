@@ -0,0 +1,105 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ModelCacheFileName is the name of the model list cache file
+const ModelCacheFileName = "models.json"
+
+// ModelCache stores provider model lists on disk for a TTL, so model
+// completion and validation don't hit the network on every start
+type ModelCache struct {
+	path string
+	ttl  time.Duration
+	now  func() time.Time
+}
+
+// modelCacheEntry is one provider's cached model list
+type modelCacheEntry struct {
+	FetchedAt time.Time   `json:"fetched_at"`
+	Models    []ModelInfo `json:"models"`
+}
+
+// NewModelCache creates a cache in the user cache directory
+// (e.g. ~/.cache/ai-cli/models.json on Linux)
+func NewModelCache(ttl time.Duration) *ModelCache {
+	path := ""
+	if dir, err := os.UserCacheDir(); err == nil {
+		path = filepath.Join(dir, "ai-cli", ModelCacheFileName)
+	}
+	return NewModelCacheAt(path, ttl)
+}
+
+// NewModelCacheAt creates a cache stored at path
+func NewModelCacheAt(path string, ttl time.Duration) *ModelCache {
+	return &ModelCache{path: path, ttl: ttl, now: time.Now}
+}
+
+// Get returns the cached models for key if they are younger than the TTL
+func (c *ModelCache) Get(key string) ([]ModelInfo, bool) {
+	entry, ok := c.load()[key]
+	if !ok || c.now().Sub(entry.FetchedAt) > c.ttl {
+		return nil, false
+	}
+	return entry.Models, true
+}
+
+// Put stores models for key, keeping other providers' entries
+func (c *ModelCache) Put(key string, models []ModelInfo) error {
+	if c.path == "" {
+		return fmt.Errorf("cache path not available")
+	}
+
+	entries := c.load()
+	entries[key] = modelCacheEntry{FetchedAt: c.now(), Models: models}
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return err
+	}
+
+	// Write to a temp file of our own and rename so neither concurrent
+	// readers nor other writers ever see a partial file
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), filepath.Base(c.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Chmod(0644)
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), c.path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+	}
+	return err
+}
+
+// load reads all entries; a missing or corrupt file is an empty cache
+func (c *ModelCache) load() map[string]modelCacheEntry {
+	entries := make(map[string]modelCacheEntry)
+	if c.path == "" {
+		return entries
+	}
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		return entries
+	}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return make(map[string]modelCacheEntry)
+	}
+	return entries
+}
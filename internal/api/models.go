@@ -0,0 +1,227 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+)
+
+// ErrModelListUnsupported is returned by DiscoverModels for clients that
+// can't list their models (e.g. a fallback chain)
+var ErrModelListUnsupported = errors.New("provider does not support listing models")
+
+// ModelInfo is a model reported by a provider's models endpoint
+type ModelInfo struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+	// Model is the underlying model of an Azure deployment
+	Model string `json:"model,omitempty"`
+	// Capabilities is nil when the provider doesn't report them
+	Capabilities *ModelCapabilities `json:"capabilities,omitempty"`
+}
+
+// ModelLister is implemented by clients that can list their models
+type ModelLister interface {
+	ListModels(ctx context.Context) ([]ModelInfo, error)
+	// ModelsCacheKey identifies the model list (provider and account or
+	// endpoint) in the on-disk cache
+	ModelsCacheKey() string
+}
+
+// DiscoverModels returns client's live model list, served from cache while
+// it is fresh. refresh bypasses the cache.
+func DiscoverModels(ctx context.Context, client AIClient, cache *ModelCache, refresh bool) ([]ModelInfo, error) {
+	lister, ok := Unwrap(client).(ModelLister)
+	if !ok {
+		return nil, ErrModelListUnsupported
+	}
+
+	key := lister.ModelsCacheKey()
+	if !refresh && cache != nil {
+		if models, ok := cache.Get(key); ok {
+			return models, nil
+		}
+	}
+
+	models, err := lister.ListModels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if cache != nil {
+		if err := cache.Put(key, models); err != nil {
+			return models, fmt.Errorf("failed to cache models: %w", err)
+		}
+	}
+	return models, nil
+}
+
+// ModelIDs returns the IDs of models
+func ModelIDs(models []ModelInfo) []string {
+	ids := make([]string, len(models))
+	for i, m := range models {
+		ids[i] = m.ID
+	}
+	return ids
+}
+
+// copilotModelsResponse is the Copilot /models response
+type copilotModelsResponse struct {
+	Data []struct {
+		ID           string `json:"id"`
+		Name         string `json:"name"`
+		Capabilities struct {
+			Type   string `json:"type"`
+			Limits struct {
+				MaxContextWindowTokens int `json:"max_context_window_tokens"`
+				MaxOutputTokens        int `json:"max_output_tokens"`
+			} `json:"limits"`
+			Supports struct {
+				ToolCalls bool `json:"tool_calls"`
+				Vision    bool `json:"vision"`
+			} `json:"supports"`
+		} `json:"capabilities"`
+		Policy *struct {
+			State string `json:"state"`
+		} `json:"policy"`
+	} `json:"data"`
+}
+
+// ListModels fetches the chat models available to the Copilot account
+func (c *CopilotClient) ListModels(ctx context.Context) ([]ModelInfo, error) {
+	headers, err := c.buildHeaders(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := c.get(ctx, c.getBaseURL()+"/models", headers)
+	if err != nil {
+		return nil, err
+	}
+	return parseCopilotModels(body)
+}
+
+// parseCopilotModels extracts the enabled chat models from a /models response
+func parseCopilotModels(body []byte) ([]ModelInfo, error) {
+	var resp copilotModelsResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse models: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	var models []ModelInfo
+	for _, m := range resp.Data {
+		if m.Capabilities.Type != "chat" || seen[m.ID] || (m.Policy != nil && m.Policy.State == "disabled") {
+			continue
+		}
+		seen[m.ID] = true
+		models = append(models, ModelInfo{
+			ID:   m.ID,
+			Name: m.Name,
+			Capabilities: &ModelCapabilities{
+				ContextWindow:   m.Capabilities.Limits.MaxContextWindowTokens,
+				MaxOutputTokens: m.Capabilities.Limits.MaxOutputTokens,
+				ToolCalls:       m.Capabilities.Supports.ToolCalls,
+				Vision:          m.Capabilities.Supports.Vision,
+			},
+		})
+	}
+	sort.Slice(models, func(i, j int) bool { return models[i].ID < models[j].ID })
+	return models, nil
+}
+
+// ModelsCacheKey identifies the Copilot account type's model list
+func (c *CopilotClient) ModelsCacheKey() string {
+	return "copilot:" + c.config.AccountType
+}
+
+// get performs an authenticated GET and returns the body of a 200 response
+func (c *CopilotClient) get(ctx context.Context, url string, headers map[string]string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, c.handleError(resp.StatusCode, body)
+	}
+	return body, nil
+}
+
+// azureDeploymentsResponse is the Azure OpenAI deployments list response
+type azureDeploymentsResponse struct {
+	Data []struct {
+		ID     string `json:"id"`
+		Model  string `json:"model"`
+		Status string `json:"status"`
+	} `json:"data"`
+}
+
+// ListModels fetches the deployments of the Azure OpenAI resource.
+// Deployment names are what requests use as the model; Azure doesn't report
+// capabilities for them.
+func (c *AzureClient) ListModels(ctx context.Context) ([]ModelInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.config.GetAzureDeploymentsURL(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("api-key", c.config.AzureAPIKey)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var errResp AzureErrorResponse
+		errMsg := fmt.Sprintf("status code %d", resp.StatusCode)
+		if err := json.Unmarshal(body, &errResp); err == nil && errResp.Error.Message != "" {
+			errMsg = errResp.Error.Message
+		}
+		return nil, &APIError{
+			StatusCode: resp.StatusCode,
+			Message:    fmt.Sprintf("Azure API error: %s", errMsg),
+		}
+	}
+
+	var deployments azureDeploymentsResponse
+	if err := json.Unmarshal(body, &deployments); err != nil {
+		return nil, fmt.Errorf("failed to parse deployments: %w", err)
+	}
+
+	var models []ModelInfo
+	for _, d := range deployments.Data {
+		if d.Status != "" && d.Status != "succeeded" {
+			continue
+		}
+		models = append(models, ModelInfo{ID: d.ID, Model: d.Model})
+	}
+	sort.Slice(models, func(i, j int) bool { return models[i].ID < models[j].ID })
+	return models, nil
+}
+
+// ModelsCacheKey identifies the Azure resource's deployment list
+func (c *AzureClient) ModelsCacheKey() string {
+	return "azure:" + c.config.AzureEndpoint
+}
@@ -0,0 +1,170 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/quocvuong92/ai-cli/internal/config"
+)
+
+func TestParseCopilotModels(t *testing.T) {
+	body := []byte(`{"data":[
+		{"id":"gpt-4.1","name":"GPT-4.1","capabilities":{"type":"chat","limits":{"max_context_window_tokens":128000,"max_output_tokens":16384},"supports":{"tool_calls":true,"vision":true}}},
+		{"id":"text-embedding-3-small","capabilities":{"type":"embeddings"}},
+		{"id":"claude-opus-4.5","capabilities":{"type":"chat","supports":{"tool_calls":true}},"policy":{"state":"disabled"}},
+		{"id":"gpt-4.1","capabilities":{"type":"chat"}},
+		{"id":"gemini-2.5-pro","capabilities":{"type":"chat","limits":{"max_context_window_tokens":1000000}},"policy":{"state":"enabled"}}
+	]}`)
+
+	models, err := parseCopilotModels(body)
+	if err != nil {
+		t.Fatalf("parseCopilotModels() error = %v", err)
+	}
+	if len(models) != 2 || models[0].ID != "gemini-2.5-pro" || models[1].ID != "gpt-4.1" {
+		t.Fatalf("parseCopilotModels() = %+v, want gemini-2.5-pro and gpt-4.1", models)
+	}
+	caps := models[1].Capabilities
+	if caps == nil || caps.ContextWindow != 128000 || caps.MaxOutputTokens != 16384 || !caps.ToolCalls || !caps.Vision {
+		t.Errorf("gpt-4.1 capabilities = %+v", caps)
+	}
+}
+
+func TestAzureClient_ListModels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/openai/deployments" || r.Header.Get("api-key") != "key" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":[
+			{"id":"prod-gpt4o","model":"gpt-4o","status":"succeeded"},
+			{"id":"new-deploy","model":"gpt-4.1","status":"running"},
+			{"id":"mini","model":"gpt-4o-mini","status":"succeeded"}
+		]}`))
+	}))
+	defer server.Close()
+
+	client := NewAzureClient(&config.Config{AzureEndpoint: server.URL, AzureAPIKey: "key"})
+	models, err := client.ListModels(context.Background())
+	if err != nil {
+		t.Fatalf("ListModels() error = %v", err)
+	}
+	if len(models) != 2 || models[0].ID != "mini" || models[1].ID != "prod-gpt4o" || models[1].Model != "gpt-4o" {
+		t.Errorf("ListModels() = %+v", models)
+	}
+	if models[0].Capabilities != nil {
+		t.Error("Azure deployments should have unknown capabilities")
+	}
+}
+
+// countingLister is a ModelLister that counts fetches
+type countingLister struct {
+	ClientAdapter
+	fetches int
+}
+
+func (l *countingLister) ListModels(ctx context.Context) ([]ModelInfo, error) {
+	l.fetches++
+	return []ModelInfo{{ID: "m1"}, {ID: "m2"}}, nil
+}
+
+func (l *countingLister) ModelsCacheKey() string { return "test" }
+
+func TestDiscoverModels(t *testing.T) {
+	cache := NewModelCacheAt(filepath.Join(t.TempDir(), "models.json"), time.Hour)
+	lister := &countingLister{}
+
+	for i := 0; i < 2; i++ {
+		models, err := DiscoverModels(context.Background(), lister, cache, false)
+		if err != nil || len(models) != 2 {
+			t.Fatalf("DiscoverModels() = %+v, %v", models, err)
+		}
+	}
+	if lister.fetches != 1 {
+		t.Errorf("fetches = %d, want 1 (second call served from cache)", lister.fetches)
+	}
+
+	if _, err := DiscoverModels(context.Background(), lister, cache, true); err != nil {
+		t.Fatalf("DiscoverModels(refresh) error = %v", err)
+	}
+	if lister.fetches != 2 {
+		t.Errorf("fetches = %d, want 2 after refresh", lister.fetches)
+	}
+
+	if _, err := DiscoverModels(context.Background(), NewClientAdapter(&closingProvider{}), cache, false); err != ErrModelListUnsupported {
+		t.Errorf("DiscoverModels() on a non-lister error = %v, want ErrModelListUnsupported", err)
+	}
+}
+
+func TestModelCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache", "models.json")
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := NewModelCacheAt(path, time.Hour)
+	cache.now = func() time.Time { return now }
+
+	if _, ok := cache.Get("copilot:individual"); ok {
+		t.Error("Get() on a missing file should miss")
+	}
+
+	if err := cache.Put("copilot:individual", []ModelInfo{{ID: "gpt-4.1", Capabilities: &ModelCapabilities{ContextWindow: 128000}}}); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if err := cache.Put("azure:https://x", []ModelInfo{{ID: "deploy"}}); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	models, ok := cache.Get("copilot:individual")
+	if !ok || len(models) != 1 || models[0].Capabilities.ContextWindow != 128000 {
+		t.Errorf("Get() = %+v, %v", models, ok)
+	}
+	if _, ok := cache.Get("azure:https://x"); !ok {
+		t.Error("Put() should keep other entries")
+	}
+
+	now = now.Add(2 * time.Hour)
+	if _, ok := cache.Get("copilot:individual"); ok {
+		t.Error("Get() should miss after the TTL")
+	}
+
+	if err := os.WriteFile(path, []byte("not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cache.Get("azure:https://x"); ok {
+		t.Error("Get() on a corrupt file should miss")
+	}
+}
+
+func TestModelCache_ConcurrentPut(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "models.json")
+
+	// Separate caches stand in for separate processes
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			models := make([]ModelInfo, 200)
+			for j := range models {
+				models[j] = ModelInfo{ID: "model"}
+			}
+			if err := NewModelCacheAt(path, time.Hour).Put("copilot:individual", models); err != nil {
+				t.Errorf("Put() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if models, ok := NewModelCacheAt(path, time.Hour).Get("copilot:individual"); !ok || len(models) != 200 {
+		t.Errorf("Get() after concurrent writes = %d models, %v", len(models), ok)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("cache dir has %d files, want only the cache", len(entries))
+	}
+}
@@ -0,0 +1,133 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/quocvuong92/ai-cli/internal/config"
+)
+
+// OpenAIClient is a client for any server speaking the OpenAI
+// /v1/chat/completions wire format (OpenAI, vLLM, llama.cpp, Ollama, ...)
+type OpenAIClient struct {
+	ClientAdapter
+	httpClient *http.Client
+	config     *config.Config
+}
+
+// NewOpenAIClient creates a new OpenAI-compatible client
+func NewOpenAIClient(cfg *config.Config) *OpenAIClient {
+	c := &OpenAIClient{
+		httpClient: newAPIHTTPClient(),
+		config:     cfg,
+	}
+	c.ClientAdapter = ClientAdapter{Provider: c}
+	return c
+}
+
+// setHeaders applies authentication and configured extra headers to a request
+func (c *OpenAIClient) setHeaders(req *http.Request) {
+	req.Header.Set("Content-Type", "application/json")
+	if c.config.OpenAIAPIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.config.OpenAIAPIKey)
+	}
+	for name, value := range c.config.OpenAIHeaders {
+		req.Header.Set(name, value)
+	}
+}
+
+// Complete sends a request to the OpenAI-compatible server (non-streaming)
+func (c *OpenAIClient) Complete(ctx context.Context, r Request) (*ChatResponse, error) {
+	reqBody := r.toChatRequest(c.config.Model, false)
+
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	// Use retry logic for transient failures
+	return WithRetry(ctx, func() (*ChatResponse, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.config.GetOpenAIAPIURL(), bytes.NewBuffer(jsonData))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		c.setHeaders(req)
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to send request: %w", err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response: %w", err)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, c.handleError(resp.StatusCode, body)
+		}
+
+		var chatResp ChatResponse
+		if err := json.Unmarshal(body, &chatResp); err != nil {
+			return nil, fmt.Errorf("failed to parse response: %w", err)
+		}
+
+		return &chatResp, nil
+	})
+}
+
+// Stream sends a streaming request to the OpenAI-compatible server
+func (c *OpenAIClient) Stream(ctx context.Context, r Request, handler StreamHandler) error {
+	reqBody := r.toChatRequest(c.config.Model, true)
+
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	// Use retry logic for transient failures (before stream starts)
+	return WithStreamRetry(ctx, func() (*http.Response, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.config.GetOpenAIAPIURL(), bytes.NewBuffer(jsonData))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		c.setHeaders(req)
+		req.Header.Set("Accept", "text/event-stream")
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to send request: %w", err)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			_ = resp.Body.Close()
+			return nil, c.handleError(resp.StatusCode, body)
+		}
+
+		return resp, nil
+	}, handler)
+}
+
+// Close is a no-op for OpenAIClient as it doesn't hold any resources
+func (c *OpenAIClient) Close() {
+	// No resources to clean up
+}
+
+// handleError creates an appropriate error from the API response
+func (c *OpenAIClient) handleError(statusCode int, body []byte) error {
+	var errResp AzureErrorResponse
+	errMsg := fmt.Sprintf("status code %d", statusCode)
+	if err := json.Unmarshal(body, &errResp); err == nil && errResp.Error.Message != "" {
+		errMsg = errResp.Error.Message
+	}
+	return &APIError{
+		StatusCode: statusCode,
+		Message:    fmt.Sprintf("OpenAI API error: %s", errMsg),
+	}
+}
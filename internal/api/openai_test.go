@@ -0,0 +1,149 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/quocvuong92/ai-cli/internal/config"
+)
+
+// newOpenAITestClient creates an OpenAIClient pointed at a test server
+func newOpenAITestClient(t *testing.T, handler http.HandlerFunc) *OpenAIClient {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return NewOpenAIClient(&config.Config{
+		Model:         "local-model",
+		OpenAIBaseURL: server.URL + "/v1",
+		OpenAIAPIKey:  "test-key",
+		OpenAIHeaders: map[string]string{"X-Custom": "custom-value"},
+	})
+}
+
+func TestOpenAIClient_QueryWithHistoryAndToolsContext(t *testing.T) {
+	client := newOpenAITestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("path = %q, want /v1/chat/completions", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer test-key" {
+			t.Errorf("Authorization = %q, want %q", got, "Bearer test-key")
+		}
+		if got := r.Header.Get("X-Custom"); got != "custom-value" {
+			t.Errorf("X-Custom = %q, want %q", got, "custom-value")
+		}
+
+		var req ChatRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+		if req.Model != "local-model" {
+			t.Errorf("Model = %q, want %q", req.Model, "local-model")
+		}
+		if req.Stream {
+			t.Error("Stream = true, want false")
+		}
+		if len(req.Tools) != 1 {
+			t.Errorf("Tools length = %d, want 1", len(req.Tools))
+		}
+
+		fmt.Fprint(w, `{"id":"resp-1","choices":[{"index":0,"message":{"role":"assistant","tool_calls":[{"id":"call_1","type":"function","function":{"name":"read_file","arguments":"{\"path\":\"go.mod\"}"}}]},"finish_reason":"tool_calls"}],"usage":{"prompt_tokens":10,"completion_tokens":5,"total_tokens":15}}`)
+	})
+
+	resp, err := client.QueryWithHistoryAndToolsContext(context.Background(),
+		[]Message{{Role: "user", Content: "read go.mod"}},
+		[]Tool{ReadFileTool},
+	)
+	if err != nil {
+		t.Fatalf("QueryWithHistoryAndToolsContext() error = %v", err)
+	}
+
+	if !resp.Choices[0].HasToolCalls() {
+		t.Fatal("expected tool calls in response")
+	}
+	if resp.Choices[0].GetToolCalls()[0].Function.Name != "read_file" {
+		t.Errorf("tool name = %q, want %q", resp.Choices[0].GetToolCalls()[0].Function.Name, "read_file")
+	}
+	if resp.Usage.TotalTokens != 15 {
+		t.Errorf("TotalTokens = %d, want 15", resp.Usage.TotalTokens)
+	}
+}
+
+func TestOpenAIClient_QueryStream(t *testing.T) {
+	client := newOpenAITestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Accept"); got != "text/event-stream" {
+			t.Errorf("Accept = %q, want %q", got, "text/event-stream")
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, `data: {"id":"s-1","choices":[{"index":0,"delta":{"content":"Hello"}}]}
+
+data: {"id":"s-1","choices":[{"index":0,"delta":{"content":" local"}}]}
+
+data: {"id":"s-1","choices":[],"usage":{"prompt_tokens":3,"completion_tokens":2,"total_tokens":5}}
+
+data: [DONE]
+`)
+	})
+
+	var chunks []string
+	var final *ChatResponse
+	err := client.QueryStream("system", "hi",
+		func(content string) { chunks = append(chunks, content) },
+		func(resp *ChatResponse) { final = resp },
+	)
+	if err != nil {
+		t.Fatalf("QueryStream() error = %v", err)
+	}
+
+	if strings.Join(chunks, "") != "Hello local" {
+		t.Errorf("streamed content = %q, want %q", strings.Join(chunks, ""), "Hello local")
+	}
+	if final == nil || final.Usage.TotalTokens != 5 {
+		t.Errorf("final response usage = %+v, want TotalTokens 5", final)
+	}
+}
+
+func TestOpenAIClient_ErrorResponse(t *testing.T) {
+	client := newOpenAITestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"error":{"message":"model not loaded","code":"invalid_request"}}`)
+	})
+
+	_, err := client.Query("system", "hi")
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+
+	apiErr, ok := err.(*APIError)
+	if !ok {
+		t.Fatalf("error type = %T, want *APIError", err)
+	}
+	if apiErr.StatusCode != http.StatusBadRequest {
+		t.Errorf("StatusCode = %d, want %d", apiErr.StatusCode, http.StatusBadRequest)
+	}
+	if !strings.Contains(apiErr.Message, "model not loaded") {
+		t.Errorf("Message = %q, want it to contain %q", apiErr.Message, "model not loaded")
+	}
+}
+
+func TestOpenAIClient_NoAPIKey(t *testing.T) {
+	client := newOpenAITestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "" {
+			t.Errorf("Authorization = %q, want empty", got)
+		}
+		fmt.Fprint(w, `{"id":"resp-1","choices":[{"index":0,"message":{"role":"assistant","content":"ok"}}]}`)
+	})
+	client.config.OpenAIAPIKey = ""
+
+	resp, err := client.Query("system", "hi")
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if resp.GetContent() != "ok" {
+		t.Errorf("GetContent() = %q, want %q", resp.GetContent(), "ok")
+	}
+}
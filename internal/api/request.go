@@ -0,0 +1,147 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Request carries everything a single chat completion call needs.
+// Zero-valued optional fields leave the provider's defaults in place.
+type Request struct {
+	Messages []Message
+	Tools    []Tool
+
+	// Model overrides the configured model for this request when non-empty
+	Model string
+
+	// Temperature, TopP and Seed are pointers because 0 is a meaningful value
+	Temperature *float64
+	TopP        *float64
+	MaxTokens   int
+	Seed        *int
+	Stop        []string
+
+	// ReasoningEffort is "low", "medium" or "high" for reasoning models
+	ReasoningEffort string
+
+	ToolChoice     *ToolChoice
+	ResponseFormat *ResponseFormat
+}
+
+// StreamHandler receives streaming output.
+// OnChunk is called for each content delta; OnReasoning, if set, for each
+// reasoning delta; OnDone, if set, receives the assembled response.
+type StreamHandler struct {
+	OnChunk     func(content string)
+	OnReasoning func(content string)
+	OnDone      func(resp *ChatResponse)
+}
+
+// Tool choice modes
+const (
+	ToolChoiceAuto     = "auto"
+	ToolChoiceNone     = "none"
+	ToolChoiceRequired = "required"
+)
+
+// ToolChoice controls whether the model may, must or must not call tools.
+// Setting Function forces a call to that specific function.
+type ToolChoice struct {
+	Mode     string
+	Function string
+}
+
+// MarshalJSON encodes the choice in OpenAI format: either a mode string
+// or {"type":"function","function":{"name":...}}
+func (tc ToolChoice) MarshalJSON() ([]byte, error) {
+	if tc.Function != "" {
+		return json.Marshal(map[string]interface{}{
+			"type":     "function",
+			"function": map[string]string{"name": tc.Function},
+		})
+	}
+	return json.Marshal(tc.Mode)
+}
+
+// UnmarshalJSON decodes either OpenAI form produced by MarshalJSON
+func (tc *ToolChoice) UnmarshalJSON(data []byte) error {
+	var mode string
+	if err := json.Unmarshal(data, &mode); err == nil {
+		*tc = ToolChoice{Mode: mode}
+		return nil
+	}
+
+	var named struct {
+		Type     string `json:"type"`
+		Function struct {
+			Name string `json:"name"`
+		} `json:"function"`
+	}
+	if err := json.Unmarshal(data, &named); err != nil {
+		return err
+	}
+	if named.Function.Name == "" {
+		return fmt.Errorf("tool_choice object requires function.name")
+	}
+	*tc = ToolChoice{Function: named.Function.Name}
+	return nil
+}
+
+// Response format types
+const (
+	ResponseFormatText       = "text"
+	ResponseFormatJSONObject = "json_object"
+	ResponseFormatJSONSchema = "json_schema"
+)
+
+// ResponseFormat constrains the shape of the model's output
+type ResponseFormat struct {
+	Type       string      `json:"type"`
+	JSONSchema *JSONSchema `json:"json_schema,omitempty"`
+}
+
+// JSONSchema is a named schema for structured output
+type JSONSchema struct {
+	Name   string          `json:"name"`
+	Schema json.RawMessage `json:"schema"`
+	Strict bool            `json:"strict,omitempty"`
+}
+
+// model returns the request's model override, or fallback if none is set
+func (r *Request) model(fallback string) string {
+	if r.Model != "" {
+		return r.Model
+	}
+	return fallback
+}
+
+// toChatRequest builds the OpenAI-format request body shared by the
+// Copilot, Azure and OpenAI-compatible clients, shaped for the model's
+// capabilities
+func (r *Request) toChatRequest(defaultModel string, stream bool) ChatRequest {
+	model := r.model(defaultModel)
+	caps := Capabilities.Lookup(model)
+
+	req := ChatRequest{
+		Model:           model,
+		Messages:        shapeMessages(r.Messages, caps.SystemRole),
+		Tools:           r.Tools,
+		Stream:          stream,
+		Temperature:     r.Temperature,
+		TopP:            r.TopP,
+		Seed:            r.Seed,
+		Stop:            r.Stop,
+		ReasoningEffort: r.ReasoningEffort,
+		ToolChoice:      r.ToolChoice,
+		ResponseFormat:  r.ResponseFormat,
+	}
+	if stream {
+		req.StreamOptions = &StreamOptions{IncludeUsage: true}
+	}
+	if caps.MaxCompletionTokens {
+		req.MaxCompletionTokens = r.MaxTokens
+	} else {
+		req.MaxTokens = r.MaxTokens
+	}
+	return req
+}
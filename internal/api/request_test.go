@@ -0,0 +1,174 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestToolChoice_MarshalJSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		choice ToolChoice
+		want   string
+	}{
+		{"auto", ToolChoice{Mode: ToolChoiceAuto}, `"auto"`},
+		{"required", ToolChoice{Mode: ToolChoiceRequired}, `"required"`},
+		{"function", ToolChoice{Function: "read_file"}, `{"function":{"name":"read_file"},"type":"function"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(tt.choice)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Marshal() = %s, want %s", got, tt.want)
+			}
+
+			var decoded ToolChoice
+			if err := json.Unmarshal(got, &decoded); err != nil || decoded != tt.choice {
+				t.Errorf("Unmarshal(%s) = %+v, %v, want %+v", got, decoded, err, tt.choice)
+			}
+		})
+	}
+
+	var tc ToolChoice
+	if err := json.Unmarshal([]byte(`{"type":"function"}`), &tc); err == nil {
+		t.Error("Unmarshal() of a function choice without a name should fail")
+	}
+}
+
+func TestRequest_ToChatRequest(t *testing.T) {
+	temp := 0.0
+	r := Request{
+		Messages:        []Message{{Role: "user", Content: "hi"}},
+		Model:           "override",
+		Temperature:     &temp,
+		MaxTokens:       64,
+		Stop:            []string{"END"},
+		ToolChoice:      &ToolChoice{Mode: ToolChoiceNone},
+		ReasoningEffort: "low",
+		ResponseFormat: &ResponseFormat{
+			Type:       ResponseFormatJSONSchema,
+			JSONSchema: &JSONSchema{Name: "answer", Schema: json.RawMessage(`{"type":"object"}`)},
+		},
+	}
+
+	data, err := json.Marshal(r.toChatRequest("default", true))
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	body := string(data)
+	for _, want := range []string{
+		`"model":"override"`,
+		`"stream":true`,
+		`"stream_options":{"include_usage":true}`,
+		`"temperature":0`,
+		`"max_tokens":64`,
+		`"stop":["END"]`,
+		`"reasoning_effort":"low"`,
+		`"tool_choice":"none"`,
+		`"response_format":{"type":"json_schema","json_schema":{"name":"answer","schema":{"type":"object"}}}`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("request body %s missing %s", body, want)
+		}
+	}
+
+	// Unset options must not appear on the wire
+	data, _ = json.Marshal((&Request{}).toChatRequest("default", false))
+	if string(data) != `{"model":"default","messages":null}` {
+		t.Errorf("empty request body = %s", data)
+	}
+}
+
+// closingProvider is a Provider that records the requests and Close calls it receives
+type closingProvider struct {
+	last   Request
+	closed bool
+}
+
+func (p *closingProvider) Complete(ctx context.Context, r Request) (*ChatResponse, error) {
+	p.last = r
+	return textResponse("ok"), nil
+}
+
+func (p *closingProvider) Stream(ctx context.Context, r Request, handler StreamHandler) error {
+	p.last = r
+	handler.OnChunk("ok")
+	return nil
+}
+
+func (p *closingProvider) Close() {
+	p.closed = true
+}
+
+func TestClientAdapter(t *testing.T) {
+	p := &closingProvider{}
+	client := NewClientAdapter(p)
+
+	if _, err := client.Query("sys", "hi"); err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(p.last.Messages) != 2 || p.last.Messages[0].Role != "system" || p.last.Messages[1].Content != "hi" {
+		t.Errorf("Query() sent %+v, want system + user messages", p.last.Messages)
+	}
+
+	var got string
+	err := client.QueryStreamWithHistoryAndToolsContext(context.Background(),
+		[]Message{{Role: "user", Content: "go"}}, []Tool{ReadFileTool},
+		func(content string) { got += content }, nil)
+	if err != nil {
+		t.Fatalf("QueryStreamWithHistoryAndToolsContext() error = %v", err)
+	}
+	if got != "ok" || len(p.last.Tools) != 1 {
+		t.Errorf("stream output = %q, tools = %d; want %q and 1 tool", got, len(p.last.Tools), "ok")
+	}
+
+	client.Close()
+	if !p.closed {
+		t.Error("Close() should close the wrapped provider")
+	}
+}
+
+func TestAnthropicClient_RequestOptions(t *testing.T) {
+	client := newAnthropicTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+		if req["model"] != "claude-other" || req["max_tokens"] != float64(100) || req["temperature"] != 0.5 {
+			t.Errorf("model/max_tokens/temperature = %v/%v/%v", req["model"], req["max_tokens"], req["temperature"])
+		}
+		if stops, _ := req["stop_sequences"].([]interface{}); len(stops) != 1 || stops[0] != "STOP" {
+			t.Errorf("stop_sequences = %v, want [STOP]", req["stop_sequences"])
+		}
+		choice, _ := req["tool_choice"].(map[string]interface{})
+		if choice["type"] != "tool" || choice["name"] != "read_file" {
+			t.Errorf("tool_choice = %v, want tool read_file", req["tool_choice"])
+		}
+		if system, _ := req["system"].(string); !strings.HasPrefix(system, "sys\n\n") || !strings.Contains(system, "JSON") {
+			t.Errorf("system = %q, want JSON instruction appended", system)
+		}
+		_, _ = w.Write([]byte(`{"id":"msg_1","content":[{"type":"text","text":"{}"}],"stop_reason":"end_turn"}`))
+	})
+
+	temp := 0.5
+	_, err := client.Complete(context.Background(), Request{
+		Messages:       []Message{{Role: "system", Content: "sys"}, {Role: "user", Content: "hi"}},
+		Tools:          []Tool{ReadFileTool},
+		Model:          "claude-other",
+		Temperature:    &temp,
+		MaxTokens:      100,
+		Stop:           []string{"STOP"},
+		ToolChoice:     &ToolChoice{Function: "read_file"},
+		ResponseFormat: &ResponseFormat{Type: ResponseFormatJSONObject},
+	})
+	if err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+}
@@ -0,0 +1,382 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/quocvuong92/ai-cli/internal/config"
+)
+
+// ResponseCacheDirName is the directory, under the user cache directory,
+// holding cached responses
+const ResponseCacheDirName = "responses"
+
+// ResponseCache stores chat responses on disk, one file per request hash.
+// Entries older than the TTL are ignored and pruned; when the cache grows
+// beyond its size cap the oldest entries are removed.
+type ResponseCache struct {
+	dir     string
+	ttl     time.Duration
+	maxSize int64
+	now     func() time.Time
+	mu      sync.Mutex // Serializes writes and pruning within the process
+}
+
+// responseCacheEntry is one cached response. Reasoning is stored separately
+// because Message does not send it on the wire.
+type responseCacheEntry struct {
+	CreatedAt time.Time     `json:"created_at"`
+	Response  *ChatResponse `json:"response"`
+	Reasoning string        `json:"reasoning,omitempty"`
+}
+
+// ResponseCacheStats describes the cache contents
+type ResponseCacheStats struct {
+	Dir     string
+	TTL     time.Duration // The limits in effect
+	MaxSize int64
+	Entries int
+	Expired int
+	Size    int64
+	Oldest  time.Time
+	Newest  time.Time
+}
+
+// NewResponseCache creates a cache in the user cache directory
+// (e.g. ~/.cache/ai-cli/responses on Linux)
+func NewResponseCache(ttl time.Duration, maxSize int64) *ResponseCache {
+	dir := ""
+	if cacheDir, err := os.UserCacheDir(); err == nil {
+		dir = filepath.Join(cacheDir, "ai-cli", ResponseCacheDirName)
+	}
+	return NewResponseCacheAt(dir, ttl, maxSize)
+}
+
+// NewResponseCacheAt creates a cache stored in dir
+func NewResponseCacheAt(dir string, ttl time.Duration, maxSize int64) *ResponseCache {
+	return &ResponseCache{dir: dir, ttl: ttl, maxSize: maxSize, now: time.Now}
+}
+
+// ResponseCacheKey hashes everything that determines a response: the
+// provider, the model and the request
+func ResponseCacheKey(provider, model string, r Request) (string, error) {
+	if r.Model != "" {
+		model = r.Model
+	}
+	data, err := json.Marshal(struct {
+		Provider string
+		Model    string
+		Request  Request
+	}{provider, model, r})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Get returns the response cached under key if it is younger than the TTL
+func (c *ResponseCache) Get(key string) (*ChatResponse, bool) {
+	if c.dir == "" {
+		return nil, false
+	}
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+	var entry responseCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Response == nil {
+		return nil, false
+	}
+	if c.now().Sub(entry.CreatedAt) > c.ttl {
+		return nil, false
+	}
+	if entry.Reasoning != "" && len(entry.Response.Choices) > 0 {
+		entry.Response.Choices[0].Message.Reasoning = entry.Reasoning
+	}
+	return entry.Response, true
+}
+
+// Put stores resp under key and prunes the cache to its size cap
+func (c *ResponseCache) Put(key string, resp *ChatResponse) error {
+	if c.dir == "" {
+		return fmt.Errorf("cache directory not available")
+	}
+
+	now := c.now()
+	data, err := json.Marshal(responseCacheEntry{CreatedAt: now, Response: resp, Reasoning: resp.GetReasoning()})
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := os.MkdirAll(c.dir, 0700); err != nil {
+		return err
+	}
+	// Write to a temp file and rename so concurrent readers never see a partial file
+	tmp, err := os.CreateTemp(c.dir, key+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), c.path(key)); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	// Housekeeping goes by file time, so keep it in step with CreatedAt
+	_ = os.Chtimes(c.path(key), now, now)
+	return c.prune()
+}
+
+// Stats reports the number, age and total size of cached responses
+func (c *ResponseCache) Stats() (ResponseCacheStats, error) {
+	stats := ResponseCacheStats{Dir: c.dir, TTL: c.ttl, MaxSize: c.maxSize}
+	files, err := c.files()
+	if err != nil {
+		return stats, err
+	}
+	for _, f := range files {
+		stats.Entries++
+		stats.Size += f.size
+		if c.expired(f) {
+			stats.Expired++
+		}
+		if stats.Oldest.IsZero() || f.modTime.Before(stats.Oldest) {
+			stats.Oldest = f.modTime
+		}
+		if f.modTime.After(stats.Newest) {
+			stats.Newest = f.modTime
+		}
+	}
+	return stats, nil
+}
+
+// Clear removes every cached response and returns how many were removed
+func (c *ResponseCache) Clear() (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	files, err := c.files()
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, f := range files {
+		if err := os.Remove(f.path); err != nil && !os.IsNotExist(err) {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// cacheFile is a cached response file
+type cacheFile struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// files lists the cached response files; a missing directory is an empty cache
+func (c *ResponseCache) files() ([]cacheFile, error) {
+	if c.dir == "" {
+		return nil, nil
+	}
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var files []cacheFile
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, cacheFile{path: filepath.Join(c.dir, e.Name()), size: info.Size(), modTime: info.ModTime()})
+	}
+	return files, nil
+}
+
+// prune removes expired entries, then the oldest ones until the cache fits
+// its size cap. The caller holds c.mu.
+func (c *ResponseCache) prune() error {
+	files, err := c.files()
+	if err != nil {
+		return err
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.Before(files[j].modTime) })
+
+	var total int64
+	for _, f := range files {
+		total += f.size
+	}
+	for _, f := range files {
+		if !c.expired(f) && (c.maxSize <= 0 || total <= c.maxSize) {
+			continue
+		}
+		if err := os.Remove(f.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		total -= f.size
+	}
+	return nil
+}
+
+// expired reports whether f is older than the TTL. File times are only
+// used for housekeeping; Get checks the stored creation time.
+func (c *ResponseCache) expired(f cacheFile) bool {
+	return c.now().Sub(f.modTime) > c.ttl
+}
+
+// path returns the file holding the response for key
+func (c *ResponseCache) path(key string) string {
+	return filepath.Join(c.dir, key+".json")
+}
+
+// CachingClient serves repeated requests from a ResponseCache and stores
+// the responses of new ones. Streaming requests that hit the cache are
+// replayed through the handler in small chunks.
+type CachingClient struct {
+	ClientAdapter
+	client   AIClient
+	cache    *ResponseCache
+	provider string
+	config   *config.Config
+}
+
+// NewCachingClient wraps client with cache. provider and the configured
+// model (used when a request doesn't name one) are part of every cache key.
+func NewCachingClient(client AIClient, cache *ResponseCache, provider string, cfg *config.Config) *CachingClient {
+	c := &CachingClient{client: client, cache: cache, provider: provider, config: cfg}
+	c.ClientAdapter = ClientAdapter{Provider: c}
+	return c
+}
+
+// Unwrap returns the wrapped client
+func (c *CachingClient) Unwrap() AIClient {
+	return c.client
+}
+
+// Complete returns the cached response for r or sends it to the wrapped client
+func (c *CachingClient) Complete(ctx context.Context, r Request) (*ChatResponse, error) {
+	key, ok := c.key(r)
+	if ok {
+		if resp, hit := c.cache.Get(key); hit {
+			log.Printf("Response cache hit (%s)", key[:12])
+			return resp, nil
+		}
+	}
+
+	resp, err := c.client.Complete(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		c.store(key, resp)
+	}
+	return resp, nil
+}
+
+// Stream replays the cached response for r or streams it from the wrapped client
+func (c *CachingClient) Stream(ctx context.Context, r Request, handler StreamHandler) error {
+	key, ok := c.key(r)
+	if ok {
+		if resp, hit := c.cache.Get(key); hit {
+			log.Printf("Response cache hit (%s)", key[:12])
+			replay(resp, handler)
+			return nil
+		}
+	}
+
+	h := handler
+	h.OnDone = func(resp *ChatResponse) {
+		if ok && resp != nil {
+			c.store(key, resp)
+		}
+		if handler.OnDone != nil {
+			handler.OnDone(resp)
+		}
+	}
+	return c.client.Stream(ctx, r, h)
+}
+
+// Close releases the wrapped client's resources
+func (c *CachingClient) Close() {
+	c.client.Close()
+}
+
+// key returns the cache key for r; requests that can't be hashed bypass the cache
+func (c *CachingClient) key(r Request) (string, bool) {
+	key, err := ResponseCacheKey(c.provider, c.config.Model, r)
+	if err != nil {
+		log.Printf("Response cache disabled for request: %v", err)
+		return "", false
+	}
+	return key, true
+}
+
+// store caches resp, logging failures: a cache that can't be written
+// must not fail the request
+func (c *CachingClient) store(key string, resp *ChatResponse) {
+	if len(resp.Choices) == 0 {
+		return
+	}
+	if err := c.cache.Put(key, resp); err != nil {
+		log.Printf("Failed to cache response: %v", err)
+	}
+}
+
+// replay delivers a cached response through handler as if it were streamed
+func replay(resp *ChatResponse, handler StreamHandler) {
+	if reasoning := resp.GetReasoning(); reasoning != "" && handler.OnReasoning != nil {
+		for _, chunk := range strings.SplitAfter(reasoning, " ") {
+			handler.OnReasoning(chunk)
+		}
+	}
+	if content := resp.GetContent(); content != "" && handler.OnChunk != nil {
+		for _, chunk := range strings.SplitAfter(content, " ") {
+			handler.OnChunk(chunk)
+		}
+	}
+	if handler.OnDone != nil {
+		handler.OnDone(resp)
+	}
+}
+
+// Unwrap returns the client underneath any wrappers such as CachingClient
+func Unwrap(client AIClient) AIClient {
+	for {
+		w, ok := client.(interface{ Unwrap() AIClient })
+		if !ok {
+			return client
+		}
+		client = w.Unwrap()
+	}
+}
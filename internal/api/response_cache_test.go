@@ -0,0 +1,162 @@
+package api
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/quocvuong92/ai-cli/internal/config"
+)
+
+func cachedResponse(content string) *ChatResponse {
+	return &ChatResponse{Choices: []Choice{{Message: Message{Role: "assistant", Content: content}}}}
+}
+
+func TestResponseCacheKey(t *testing.T) {
+	req := Request{Messages: []Message{{Role: "user", Content: "hi"}}}
+	base, err := ResponseCacheKey("copilot", "gpt-4.1", req)
+	if err != nil {
+		t.Fatalf("ResponseCacheKey() error = %v", err)
+	}
+	if again, _ := ResponseCacheKey("copilot", "gpt-4.1", req); again != base {
+		t.Error("ResponseCacheKey() should be stable")
+	}
+
+	temp := 0.5
+	variants := map[string]func() (string, error){
+		"provider": func() (string, error) { return ResponseCacheKey("azure", "gpt-4.1", req) },
+		"model":    func() (string, error) { return ResponseCacheKey("copilot", "gpt-4o", req) },
+		"request model": func() (string, error) {
+			r := req
+			r.Model = "gpt-4o"
+			return ResponseCacheKey("copilot", "gpt-4.1", r)
+		},
+		"temperature": func() (string, error) {
+			r := req
+			r.Temperature = &temp
+			return ResponseCacheKey("copilot", "gpt-4.1", r)
+		},
+		"tools": func() (string, error) {
+			r := req
+			r.Tools = GetDefaultTools()
+			return ResponseCacheKey("copilot", "gpt-4.1", r)
+		},
+	}
+	for name, key := range variants {
+		if got, _ := key(); got == base {
+			t.Errorf("changing the %s should change the key", name)
+		}
+	}
+}
+
+func TestResponseCache(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := NewResponseCacheAt(dir, time.Hour, 1<<20)
+	cache.now = func() time.Time { return now }
+
+	if _, ok := cache.Get("missing"); ok {
+		t.Error("Get() on a missing entry should miss")
+	}
+
+	resp := cachedResponse("hello")
+	resp.Choices[0].Message.Reasoning = "thinking"
+	if err := cache.Put("k1", resp); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	got, ok := cache.Get("k1")
+	if !ok || got.GetContent() != "hello" || got.GetReasoning() != "thinking" {
+		t.Errorf("Get() = %+v, %v", got, ok)
+	}
+
+	now = now.Add(2 * time.Hour)
+	if _, ok := cache.Get("k1"); ok {
+		t.Error("Get() should miss after the TTL")
+	}
+	stats, err := cache.Stats()
+	if err != nil || stats.Entries != 1 || stats.Expired != 1 || stats.Size == 0 {
+		t.Errorf("Stats() = %+v, %v", stats, err)
+	}
+
+	if err := cache.Put("k2", cachedResponse("again")); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if stats, _ := cache.Stats(); stats.Entries != 1 {
+		t.Errorf("Put() should prune expired entries, have %d", stats.Entries)
+	}
+
+	removed, err := cache.Clear()
+	if err != nil || removed != 1 {
+		t.Errorf("Clear() = %d, %v, want 1", removed, err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("Clear() left %d files", len(entries))
+	}
+}
+
+func TestResponseCache_SizeCap(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	big := strings.Repeat("x", 400)
+	cache := NewResponseCacheAt(t.TempDir(), time.Hour, 1000)
+	cache.now = func() time.Time { return now }
+
+	for _, key := range []string{"a", "b", "c"} {
+		now = now.Add(time.Minute)
+		if err := cache.Put(key, cachedResponse(big)); err != nil {
+			t.Fatalf("Put(%s) error = %v", key, err)
+		}
+	}
+
+	if _, ok := cache.Get("a"); ok {
+		t.Error("the oldest entry should be pruned to fit the size cap")
+	}
+	if _, ok := cache.Get("c"); !ok {
+		t.Error("the newest entry should be kept")
+	}
+	if stats, _ := cache.Stats(); stats.Size > 1000 {
+		t.Errorf("cache size = %d, want at most 1000", stats.Size)
+	}
+}
+
+func TestCachingClient(t *testing.T) {
+	cfg := &config.Config{Model: "gpt-4.1"}
+	stub := &stubClient{resp: cachedResponse("hello there world"), chunks: []string{"hello ", "there ", "world"}}
+	client := NewCachingClient(NewClientAdapter(stub), NewResponseCacheAt(t.TempDir(), time.Hour, 1<<20), "copilot", cfg)
+	req := Request{Messages: []Message{{Role: "user", Content: "hi"}}}
+
+	var first strings.Builder
+	if err := client.Stream(context.Background(), req, StreamHandler{OnChunk: func(s string) { first.WriteString(s) }}); err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+
+	var replayed strings.Builder
+	var done *ChatResponse
+	err := client.Stream(context.Background(), req, StreamHandler{
+		OnChunk: func(s string) { replayed.WriteString(s) },
+		OnDone:  func(r *ChatResponse) { done = r },
+	})
+	if err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+	if stub.calls != 1 {
+		t.Errorf("calls = %d, want 1 (second request served from cache)", stub.calls)
+	}
+	if replayed.String() != first.String() || done == nil || done.GetContent() != "hello there world" {
+		t.Errorf("replay = %q (done %+v), want %q", replayed.String(), done, first.String())
+	}
+
+	if resp, err := client.Complete(context.Background(), req); err != nil || resp.GetContent() != "hello there world" || stub.calls != 1 {
+		t.Errorf("Complete() = %+v, %v after %d calls; want a cache hit", resp, err, stub.calls)
+	}
+
+	cfg.Model = "gpt-4o"
+	if _, err := client.Complete(context.Background(), req); err != nil || stub.calls != 2 {
+		t.Errorf("switching models should miss the cache, calls = %d, err = %v", stub.calls, err)
+	}
+
+	if Unwrap(client) == AIClient(client) {
+		t.Error("Unwrap() should return the wrapped client")
+	}
+}
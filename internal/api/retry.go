@@ -3,6 +3,7 @@ package api
 import (
 	"context"
 	"fmt"
+	"io"
 	"net/http"
 	"time"
 
@@ -31,6 +32,7 @@ var RetryableStatusCodes = []int{
 	http.StatusGatewayTimeout,      // 504 - Gateway timeout
 	http.StatusBadGateway,          // 502 - Bad gateway
 	http.StatusInternalServerError, // 500 - Internal server error (transient)
+	529,                            // Anthropic overloaded
 }
 
 // ShouldRotateKey checks if the error status code indicates we should try another key
@@ -129,11 +131,43 @@ func WithRetry[T any](ctx context.Context, fn RetryableFunc[T]) (T, error) {
 // The caller is responsible for closing the response body on success.
 type StreamRetryableFunc func() (*http.Response, error)
 
+// StreamProcessor consumes a provider's streaming response body and
+// accumulates it into a ChatResponse.
+type StreamProcessor interface {
+	Process(ctx context.Context, onChunk func(content string)) error
+	SetReasoningHandler(onReasoning func(content string))
+	BuildResponse() *ChatResponse
+}
+
+// StreamInterruptedError is returned when a stream fails after content has
+// been received. Partial holds what arrived, so callers can keep it and ask
+// the model to continue.
+type StreamInterruptedError struct {
+	Partial *ChatResponse
+	Err     error
+}
+
+func (e *StreamInterruptedError) Error() string {
+	return fmt.Sprintf("stream interrupted: %v", e.Err)
+}
+
+func (e *StreamInterruptedError) Unwrap() error {
+	return e.Err
+}
+
 // WithStreamRetry executes a streaming request with retry logic for transient failures.
 // It retries the initial connection on retryable HTTP status codes, then processes
-// the SSE stream using the provided callbacks. Once streaming starts, retries are
-// not attempted (partial responses cannot be safely retried).
-func WithStreamRetry(ctx context.Context, fn StreamRetryableFunc, onChunk func(content string), onDone func(resp *ChatResponse)) error {
+// the SSE stream using the handler's callbacks. A stream that fails before
+// delivering anything is retried too; once output has reached the handler, a
+// failure returns a StreamInterruptedError holding the partial response.
+func WithStreamRetry(ctx context.Context, fn StreamRetryableFunc, handler StreamHandler) error {
+	newProcessor := func(r io.Reader) StreamProcessor { return NewSSEProcessor(r) }
+	return WithStreamRetryProcessor(ctx, fn, newProcessor, handler)
+}
+
+// WithStreamRetryProcessor is WithStreamRetry for providers whose stream format
+// differs from OpenAI's; newProcessor builds the processor for the response body.
+func WithStreamRetryProcessor(ctx context.Context, fn StreamRetryableFunc, newProcessor func(io.Reader) StreamProcessor, handler StreamHandler) error {
 	var lastErr error
 
 	for attempt := 0; attempt < MaxAPIRetryAttempts; attempt++ {
@@ -145,30 +179,31 @@ func WithStreamRetry(ctx context.Context, fn StreamRetryableFunc, onChunk func(c
 		resp, err := fn()
 		if err == nil {
 			// Successfully connected, process the stream
-			defer func() { _ = resp.Body.Close() }()
-
-			processor := NewSSEProcessor(resp.Body)
-			if err := processor.Process(ctx, onChunk); err != nil {
-				return fmt.Errorf("failed to process stream: %w", err)
+			final, err := consumeStream(ctx, resp.Body, newProcessor, handler)
+			if err == nil {
+				if handler.OnDone != nil {
+					handler.OnDone(final)
+				}
+				return nil
 			}
-
-			// Build and return final response
-			if onDone != nil {
-				onDone(processor.BuildResponse())
+			if ctx.Err() != nil || !isEmptyResponse(final) {
+				return streamError(final, err)
 			}
-			return nil
-		}
-		lastErr = err
-
-		// Check if error is retryable
-		if apiErr, ok := err.(*APIError); ok {
-			if !ShouldRetryAPICall(apiErr.StatusCode) {
-				// Non-retryable error, return immediately
+			// Nothing was delivered, so the request can be sent again
+			lastErr = fmt.Errorf("failed to process stream: %w", err)
+		} else {
+			lastErr = err
+
+			// Check if error is retryable
+			if apiErr, ok := err.(*APIError); ok {
+				if !ShouldRetryAPICall(apiErr.StatusCode) {
+					// Non-retryable error, return immediately
+					return err
+				}
+			} else {
+				// Non-API error, don't retry
 				return err
 			}
-		} else {
-			// Non-API error, don't retry
-			return err
 		}
 
 		// Apply backoff before retry (except for last attempt)
@@ -183,3 +218,29 @@ func WithStreamRetry(ctx context.Context, fn StreamRetryableFunc, onChunk func(c
 
 	return fmt.Errorf("max retry attempts (%d) exceeded: %w", MaxAPIRetryAttempts, lastErr)
 }
+
+// consumeStream processes a response body under the idle timeout and returns
+// what was accumulated, even when processing fails
+func consumeStream(ctx context.Context, body io.ReadCloser, newProcessor func(io.Reader) StreamProcessor, handler StreamHandler) (*ChatResponse, error) {
+	reader := newIdleTimeoutReader(body, StreamIdleTimeout)
+	defer func() { _ = reader.Close() }()
+
+	processor := newProcessor(reader)
+	processor.SetReasoningHandler(handler.OnReasoning)
+	err := processor.Process(ctx, handler.OnChunk)
+	return processor.BuildResponse(), err
+}
+
+// streamError wraps a stream processing failure, keeping the partial
+// response when there is one
+func streamError(partial *ChatResponse, err error) error {
+	if isEmptyResponse(partial) {
+		return fmt.Errorf("failed to process stream: %w", err)
+	}
+	return &StreamInterruptedError{Partial: partial, Err: err}
+}
+
+// isEmptyResponse reports whether resp carries no content or reasoning
+func isEmptyResponse(resp *ChatResponse) bool {
+	return resp == nil || (resp.GetContent() == "" && resp.GetReasoning() == "")
+}
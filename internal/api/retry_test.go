@@ -3,7 +3,9 @@ package api
 import (
 	"context"
 	"errors"
+	"io"
 	"net/http"
+	"strings"
 	"testing"
 	"time"
 )
@@ -199,3 +201,77 @@ func TestAPIError_Error(t *testing.T) {
 		t.Errorf("APIError.Error() = %v, want %v", got, want)
 	}
 }
+
+// failingBody yields data and then fails with err
+func failingBody(data string, err error) io.ReadCloser {
+	return io.NopCloser(io.MultiReader(strings.NewReader(data), errReader{err}))
+}
+
+type errReader struct{ err error }
+
+func (r errReader) Read([]byte) (int, error) { return 0, r.err }
+
+func TestWithStreamRetry_InterruptedKeepsPartial(t *testing.T) {
+	dropped := errors.New("connection reset")
+	fn := func() (*http.Response, error) {
+		body := failingBody("data: {\"choices\":[{\"delta\":{\"content\":\"Hello\"}}]}\n\n", dropped)
+		return &http.Response{StatusCode: http.StatusOK, Body: body}, nil
+	}
+
+	var streamed string
+	err := WithStreamRetry(context.Background(), fn, StreamHandler{OnChunk: func(c string) { streamed += c }})
+
+	var interrupted *StreamInterruptedError
+	if !errors.As(err, &interrupted) {
+		t.Fatalf("WithStreamRetry() error = %v, want StreamInterruptedError", err)
+	}
+	if !errors.Is(err, dropped) {
+		t.Errorf("error %v does not wrap the cause", err)
+	}
+	if got := interrupted.Partial.GetContent(); got != "Hello" || streamed != "Hello" {
+		t.Errorf("partial = %q, streamed = %q, want Hello", got, streamed)
+	}
+}
+
+func TestWithStreamRetry_RetriesEmptyStream(t *testing.T) {
+	calls := 0
+	fn := func() (*http.Response, error) {
+		calls++
+		if calls == 1 {
+			return &http.Response{StatusCode: http.StatusOK, Body: failingBody("", errors.New("connection reset"))}, nil
+		}
+		body := io.NopCloser(strings.NewReader("data: {\"choices\":[{\"delta\":{\"content\":\"ok\"}}]}\n\ndata: [DONE]\n"))
+		return &http.Response{StatusCode: http.StatusOK, Body: body}, nil
+	}
+
+	var final *ChatResponse
+	err := WithStreamRetry(context.Background(), fn, StreamHandler{
+		OnChunk: func(string) {},
+		OnDone:  func(r *ChatResponse) { final = r },
+	})
+	if err != nil {
+		t.Fatalf("WithStreamRetry() error = %v", err)
+	}
+	if calls != 2 || final == nil || final.GetContent() != "ok" {
+		t.Errorf("calls = %d, final = %+v", calls, final)
+	}
+}
+
+func TestIdleTimeoutReader(t *testing.T) {
+	pr, pw := io.Pipe()
+	defer func() { _ = pw.Close() }()
+
+	reader := newIdleTimeoutReader(pr, 20*time.Millisecond)
+	defer func() { _ = reader.Close() }()
+
+	go func() { _, _ = pw.Write([]byte("data")) }()
+	buf := make([]byte, 8)
+	if n, err := reader.Read(buf); err != nil || string(buf[:n]) != "data" {
+		t.Fatalf("Read() = %q, %v", buf[:n], err)
+	}
+
+	// Nothing more is written, so the next read must time out
+	if _, err := reader.Read(buf); !errors.Is(err, ErrStreamIdle) {
+		t.Errorf("Read() error = %v, want ErrStreamIdle", err)
+	}
+}
@@ -11,11 +11,13 @@ import (
 
 // SSEProcessor handles Server-Sent Events stream processing
 type SSEProcessor struct {
-	reader         *bufio.Reader
-	contentBuilder strings.Builder
-	toolCallsMap   map[int]*ToolCall
-	finalUsage     Usage
-	responseID     string
+	reader           *bufio.Reader
+	contentBuilder   strings.Builder
+	reasoningBuilder strings.Builder
+	onReasoning      func(content string)
+	toolCallsMap     map[int]*ToolCall
+	finalUsage       Usage
+	responseID       string
 }
 
 // NewSSEProcessor creates a new SSE stream processor
@@ -26,6 +28,11 @@ func NewSSEProcessor(r io.Reader) *SSEProcessor {
 	}
 }
 
+// SetReasoningHandler sets the function called for each reasoning chunk
+func (p *SSEProcessor) SetReasoningHandler(onReasoning func(content string)) {
+	p.onReasoning = onReasoning
+}
+
 // Process reads and processes the SSE stream, calling onChunk for each content chunk
 // and returning the final accumulated response when done
 func (p *SSEProcessor) Process(ctx context.Context, onChunk func(content string)) error {
@@ -68,6 +75,16 @@ func (p *SSEProcessor) Process(ctx context.Context, onChunk func(content string)
 			p.responseID = chunk.ID
 		}
 
+		// Reasoning arrives before the content it leads to
+		if len(chunk.Choices) > 0 {
+			if reasoning := chunk.Choices[0].Delta.ReasoningText(); reasoning != "" {
+				p.reasoningBuilder.WriteString(reasoning)
+				if p.onReasoning != nil {
+					p.onReasoning(reasoning)
+				}
+			}
+		}
+
 		// Send content chunk and accumulate
 		if len(chunk.Choices) > 0 && chunk.Choices[0].Delta.Content != "" {
 			content := chunk.Choices[0].Delta.Content
@@ -126,6 +143,7 @@ func (p *SSEProcessor) BuildResponse() *ChatResponse {
 				Message: Message{
 					Role:      "assistant",
 					Content:   p.contentBuilder.String(),
+					Reasoning: p.reasoningBuilder.String(),
 					ToolCalls: toolCalls,
 				},
 				FinishReason: finishReason,
@@ -194,3 +194,60 @@ func TestNewSSEProcessor(t *testing.T) {
 		t.Error("NewSSEProcessor().toolCallsMap is nil")
 	}
 }
+
+func TestSSEProcessor_Process_Reasoning(t *testing.T) {
+	input := `data: {"id":"r-1","choices":[{"index":0,"delta":{"reasoning_content":"Think"}}]}
+
+data: {"id":"r-1","choices":[{"index":0,"delta":{"reasoning":"ing..."}}]}
+
+data: {"id":"r-1","choices":[{"index":0,"delta":{"content":"42"}}]}
+
+data: {"id":"r-1","choices":[],"usage":{"prompt_tokens":10,"completion_tokens":30,"total_tokens":40,"completion_tokens_details":{"reasoning_tokens":25}}}
+
+data: [DONE]
+`
+	processor := NewSSEProcessor(strings.NewReader(input))
+
+	var reasoning, content []string
+	processor.SetReasoningHandler(func(chunk string) {
+		reasoning = append(reasoning, chunk)
+	})
+	err := processor.Process(context.Background(), func(chunk string) {
+		content = append(content, chunk)
+	})
+	if err != nil {
+		t.Fatalf("Process() unexpected error: %v", err)
+	}
+
+	if strings.Join(reasoning, "") != "Thinking..." {
+		t.Errorf("reasoning chunks = %q, want %q", reasoning, []string{"Think", "ing..."})
+	}
+	if strings.Join(content, "") != "42" {
+		t.Errorf("content chunks = %q, want %q", content, []string{"42"})
+	}
+
+	resp := processor.BuildResponse()
+	if got := resp.GetReasoning(); got != "Thinking..." {
+		t.Errorf("GetReasoning() = %q, want %q", got, "Thinking...")
+	}
+	if got := resp.GetContent(); got != "42" {
+		t.Errorf("GetContent() = %q, want %q", got, "42")
+	}
+	if got := resp.GetUsageMap()["reasoning_tokens"]; got != 25 {
+		t.Errorf("reasoning_tokens = %d, want 25", got)
+	}
+}
+
+func TestSSEProcessor_Process_ReasoningWithoutHandler(t *testing.T) {
+	input := `data: {"id":"r-1","choices":[{"index":0,"delta":{"reasoning_content":"hmm"}}]}
+
+data: [DONE]
+`
+	processor := NewSSEProcessor(strings.NewReader(input))
+	if err := processor.Process(context.Background(), func(string) {}); err != nil {
+		t.Fatalf("Process() unexpected error: %v", err)
+	}
+	if got := processor.BuildResponse().GetReasoning(); got != "hmm" {
+		t.Errorf("GetReasoning() = %q, want %q", got, "hmm")
+	}
+}
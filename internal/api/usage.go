@@ -0,0 +1,62 @@
+package api
+
+import "context"
+
+// UsageClient reports every response the wrapped client completes, streamed
+// or not, so its token usage can be recorded. Responses from providers that
+// don't send usage are reported with zero tokens.
+type UsageClient struct {
+	ClientAdapter
+	client  AIClient
+	onUsage func(r Request, resp *ChatResponse)
+}
+
+// NewUsageClient wraps client so onUsage is called after each completed request
+func NewUsageClient(client AIClient, onUsage func(r Request, resp *ChatResponse)) *UsageClient {
+	c := &UsageClient{client: client, onUsage: onUsage}
+	c.ClientAdapter = ClientAdapter{Provider: c}
+	return c
+}
+
+// Unwrap returns the wrapped client
+func (c *UsageClient) Unwrap() AIClient {
+	return c.client
+}
+
+// Complete sends r to the wrapped client and reports the response's usage
+func (c *UsageClient) Complete(ctx context.Context, r Request) (*ChatResponse, error) {
+	resp, err := c.client.Complete(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	c.report(r, resp)
+	return resp, nil
+}
+
+// Stream streams r from the wrapped client and reports the usage once the
+// stream returns
+func (c *UsageClient) Stream(ctx context.Context, r Request, handler StreamHandler) error {
+	var final *ChatResponse
+	h := handler
+	h.OnDone = func(resp *ChatResponse) {
+		final = resp
+		if handler.OnDone != nil {
+			handler.OnDone(resp)
+		}
+	}
+	err := c.client.Stream(ctx, r, h)
+	c.report(r, final)
+	return err
+}
+
+// Close releases the wrapped client's resources
+func (c *UsageClient) Close() {
+	c.client.Close()
+}
+
+// report calls onUsage for a completed response
+func (c *UsageClient) report(r Request, resp *ChatResponse) {
+	if resp != nil && c.onUsage != nil {
+		c.onUsage(r, resp)
+	}
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestUsageClient(t *testing.T) {
+	resp := cachedResponse("hi")
+	resp.Usage = Usage{PromptTokens: 3, CompletionTokens: 2, TotalTokens: 5}
+	stub := &stubClient{resp: resp, chunks: []string{"hi"}}
+
+	var reported []Request
+	var tokens int
+	var doneBeforeReport bool
+	client := NewUsageClient(NewClientAdapter(stub), func(r Request, got *ChatResponse) {
+		tokens += got.Usage.TotalTokens
+		reported = append(reported, r)
+	})
+
+	req := Request{Model: "gpt-4.1"}
+	if _, err := client.Complete(context.Background(), req); err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	err := client.Stream(context.Background(), req, StreamHandler{
+		OnChunk: func(string) {},
+		OnDone:  func(*ChatResponse) { doneBeforeReport = len(reported) == 1 },
+	})
+	if err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+	if len(reported) != 2 || reported[1].Model != "gpt-4.1" || tokens != 10 {
+		t.Errorf("reported = %+v (%d tokens), want both requests", reported, tokens)
+	}
+	if !doneBeforeReport {
+		t.Error("the caller's OnDone should run before usage is reported")
+	}
+
+	// Failed requests are not reported; responses without usage are
+	stub.err = errors.New("boom")
+	_, _ = client.Complete(context.Background(), req)
+	stub.err, stub.resp = nil, cachedResponse("no usage")
+	_, _ = client.Complete(context.Background(), req)
+	if len(reported) != 3 || tokens != 10 {
+		t.Errorf("reported %d requests (%d tokens), want 3 (10 tokens)", len(reported), tokens)
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/quocvuong92/ai-cli/internal/auth"
 	"github.com/quocvuong92/ai-cli/internal/constants"
@@ -21,14 +23,30 @@ const (
 	EnvCopilotAccountType = "COPILOT_ACCOUNT_TYPE"
 	EnvCopilotModels      = "COPILOT_MODELS"
 
+	// OpenAI-compatible settings
+	EnvOpenAIBaseURL = "OPENAI_BASE_URL"
+	EnvOpenAIAPIKey  = "OPENAI_API_KEY"
+	EnvOpenAIModels  = "OPENAI_MODELS"
+	EnvOpenAIHeaders = "OPENAI_EXTRA_HEADERS" // Comma-separated "Name=Value" pairs
+
+	// Anthropic settings
+	EnvAnthropicBaseURL = "ANTHROPIC_BASE_URL"
+	EnvAnthropicAPIKey  = "ANTHROPIC_API_KEY"
+	EnvAnthropicModels  = "ANTHROPIC_MODELS"
+
 	// Provider selection
-	EnvAIProvider = "AI_PROVIDER"
+	EnvAIProvider      = "AI_PROVIDER"
+	EnvFallbackChain   = "AI_FALLBACK_CHAIN" // Comma-separated "provider:model" entries
+	EnvFallbackClasses = "AI_FALLBACK_ON"    // Comma-separated failover status classes
 
 	// Web search settings
 	EnvTavilyAPIKeys     = "TAVILY_API_KEYS"
 	EnvLinkupAPIKeys     = "LINKUP_API_KEYS"
 	EnvBraveAPIKeys      = "BRAVE_API_KEYS"
 	EnvWebSearchProvider = "WEB_SEARCH_PROVIDER"
+
+	// Local server (ai-cli serve)
+	EnvServeToken = "AI_SERVE_TOKEN" // Bearer token required by clients when set
 )
 
 // Defaults - re-exported from constants for convenience
@@ -38,6 +56,14 @@ const (
 	DefaultSearchProvider = constants.DefaultSearchProvider
 	DefaultAccountType    = constants.DefaultAccountType
 	DefaultProvider       = "" // Auto-detect
+	DefaultOpenAIBaseURL  = "https://api.openai.com/v1"
+	DefaultAnthropicURL   = "https://api.anthropic.com"
+
+	DefaultJSONSchemaRetries = constants.DefaultJSONSchemaRetries
+	DefaultMaxToolIterations = constants.DefaultMaxToolIterations
+
+	DefaultResponseCacheMaxSizeMB  = constants.DefaultResponseCacheMaxSizeMB
+	DefaultHistoryMaxConversations = constants.DefaultHistoryMaxConversations
 )
 
 // Timeout constants - re-exported from constants for convenience
@@ -45,22 +71,186 @@ const (
 	DefaultAPITimeout     = constants.DefaultAPITimeout
 	DefaultCommandTimeout = constants.DefaultCommandTimeout
 	DefaultOAuthTimeout   = constants.DefaultOAuthTimeout
+
+	DefaultModelDiscoveryTimeout = constants.DefaultModelDiscoveryTimeout
+	DefaultModelCacheTTL         = constants.DefaultModelCacheTTL
+	DefaultResponseCacheTTL      = constants.DefaultResponseCacheTTL
 )
 
 // DefaultCopilotModels - re-exported from constants for convenience
 var DefaultCopilotModels = constants.DefaultCopilotModels
 
+// DefaultAnthropicModels - re-exported from constants for convenience
+var DefaultAnthropicModels = constants.DefaultAnthropicModels
+
 // Errors
 var (
 	ErrEndpointNotFound      = errors.New("Azure endpoint not found. Set AZURE_OPENAI_ENDPOINT environment variable")
 	ErrAPIKeyNotFound        = errors.New("Azure API key not found. Set AZURE_OPENAI_API_KEY environment variable")
+	ErrAnthropicKeyNotFound  = errors.New("Anthropic API key not found. Set ANTHROPIC_API_KEY environment variable")
 	ErrModelNotFound         = errors.New("model not found. Set AZURE_OPENAI_MODEL or use --model flag")
 	ErrInvalidModel          = errors.New("invalid model specified")
 	ErrNoAvailableKeys       = errors.New("all API keys exhausted")
 	ErrWebSearchKeyNotFound  = errors.New("web search API key not found. Set TAVILY_API_KEYS, LINKUP_API_KEYS, or BRAVE_API_KEYS to use --web flag")
 	ErrInvalidSearchProvider = errors.New("invalid search provider. Use 'tavily', 'linkup', or 'brave'")
+	ErrInvalidFallbackEntry  = errors.New("invalid fallback chain entry. Use 'provider:model' with provider copilot, azure, openai, or anthropic")
+	ErrInvalidFailoverClass  = errors.New("invalid failover class. Use 'rate_limit', 'server_error', 'auth', 'client_error', or 'network'")
+	ErrInvalidTemperature    = errors.New("invalid temperature. Use a value between 0 and 2")
+	ErrInvalidTopP           = errors.New("invalid top_p. Use a value between 0 and 1")
+	ErrInvalidMaxTokens      = errors.New("invalid max_tokens. Use a positive number")
+	ErrInvalidReasoning      = errors.New("invalid reasoning effort. Use 'low', 'medium', or 'high'")
+	ErrInvalidOutputFormat   = errors.New("invalid output format. Use 'text', 'json', or 'ndjson'")
+	ErrInvalidSystemRole     = errors.New("invalid system_role. Use 'system', 'developer', or 'user'")
+	ErrInvalidHistoryMaxAge  = errors.New("invalid history.max_age. Use a duration such as '90d', '12w' or '720h'")
+	ErrInvalidCacheTTL       = errors.New("invalid cache ttl. Use a positive duration such as '24h' or '30m'")
+	ErrInvalidLimit          = errors.New("invalid limit. Use a positive number, or 0 for the default")
+)
+
+// Failover status classes used by the fallback chain
+const (
+	FailoverRateLimit   = "rate_limit"   // 429
+	FailoverServerError = "server_error" // 5xx (and 529 overloaded)
+	FailoverAuth        = "auth"         // 401, 403
+	FailoverClientError = "client_error" // Other 4xx, e.g. model not available on this backend
+	FailoverNetwork     = "network"      // Connection failures and other non-HTTP errors
+)
+
+// Output formats for one-shot queries
+const (
+	OutputText   = "text"   // Human-readable text (default)
+	OutputJSON   = "json"   // A single JSON object with the full response
+	OutputNDJSON = "ndjson" // One JSON line per streamed delta, then the final response
+)
+
+// Roles a model may require for system messages (see ModelCapabilityOverride)
+const (
+	SystemRoleSystem    = "system"
+	SystemRoleDeveloper = "developer" // o-series reasoning models
+	SystemRoleUser      = "user"      // Folded into the first user message
 )
 
+// Reasoning effort levels for reasoning models
+const (
+	ReasoningLow    = "low"
+	ReasoningMedium = "medium"
+	ReasoningHigh   = "high"
+)
+
+// DefaultFailoverClasses are used when no failover classes are configured
+var DefaultFailoverClasses = []string{FailoverRateLimit, FailoverServerError, FailoverNetwork}
+
+// FallbackTarget is one provider/model pair in the fallback chain
+type FallbackTarget struct {
+	Provider string
+	Model    string // Empty means use the configured model
+}
+
+// String returns the target in "provider:model" form
+func (t FallbackTarget) String() string {
+	if t.Model == "" {
+		return t.Provider
+	}
+	return t.Provider + ":" + t.Model
+}
+
+// ParseFallbackTarget parses a "provider:model" chain entry
+func ParseFallbackTarget(s string) (FallbackTarget, error) {
+	provider, model, _ := strings.Cut(strings.TrimSpace(s), ":")
+	provider = strings.ToLower(strings.TrimSpace(provider))
+	switch provider {
+	case "copilot", "github", "azure", "openai", "anthropic":
+	default:
+		return FallbackTarget{}, fmt.Errorf("%w: %q", ErrInvalidFallbackEntry, s)
+	}
+	return FallbackTarget{Provider: provider, Model: strings.TrimSpace(model)}, nil
+}
+
+// ValidateSampling checks that the sampling parameters are within the ranges providers accept
+func (c *Config) ValidateSampling() error {
+	if c.Temperature != nil && (*c.Temperature < 0 || *c.Temperature > 2) {
+		return ErrInvalidTemperature
+	}
+	if c.TopP != nil && (*c.TopP < 0 || *c.TopP > 1) {
+		return ErrInvalidTopP
+	}
+	if c.MaxTokens < 0 {
+		return ErrInvalidMaxTokens
+	}
+	switch c.ReasoningEffort {
+	case "", ReasoningLow, ReasoningMedium, ReasoningHigh:
+	default:
+		return ErrInvalidReasoning
+	}
+	return nil
+}
+
+// ValidateLimits checks the usage limits and fills in the tool iteration default
+func (c *Config) ValidateLimits() error {
+	if c.MaxTokensPerSession < 0 || c.MaxRequestsPerDay < 0 || c.MaxToolIterations < 0 {
+		return ErrInvalidLimit
+	}
+	if c.MaxToolIterations == 0 {
+		c.MaxToolIterations = DefaultMaxToolIterations
+	}
+	return nil
+}
+
+// resolveCacheSettings parses the cache TTL and fills in cache defaults
+func (c *Config) resolveCacheSettings() error {
+	if c.CacheTTL == 0 && c.cacheTTLFromFile != "" {
+		ttl, err := time.ParseDuration(c.cacheTTLFromFile)
+		if err != nil || ttl <= 0 {
+			return fmt.Errorf("%w: %q", ErrInvalidCacheTTL, c.cacheTTLFromFile)
+		}
+		c.CacheTTL = ttl
+	}
+	if c.CacheTTL == 0 {
+		c.CacheTTL = DefaultResponseCacheTTL
+	}
+	if c.CacheMaxSizeMB <= 0 {
+		c.CacheMaxSizeMB = DefaultResponseCacheMaxSizeMB
+	}
+	return nil
+}
+
+// resolveHistorySettings parses the history max age and fills in the
+// retention default
+func (c *Config) resolveHistorySettings() error {
+	if c.HistoryMaxAge == 0 && c.historyMaxAgeFromFile != "" {
+		age, err := ParseAge(c.historyMaxAgeFromFile)
+		if err != nil {
+			return fmt.Errorf("%w: %q", ErrInvalidHistoryMaxAge, c.historyMaxAgeFromFile)
+		}
+		c.HistoryMaxAge = age
+	}
+	if c.HistoryMaxConversations <= 0 {
+		c.HistoryMaxConversations = DefaultHistoryMaxConversations
+	}
+	return nil
+}
+
+// ParseAge parses a positive duration with an optional d (days) or w
+// (weeks) unit, such as "90d", "2w" or "36h"
+func ParseAge(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, fmt.Errorf("empty duration")
+	}
+	units := map[byte]time.Duration{'d': 24 * time.Hour, 'w': 7 * 24 * time.Hour}
+	if unit, ok := units[value[len(value)-1]]; ok {
+		n, err := strconv.Atoi(value[:len(value)-1])
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+		return time.Duration(n) * unit, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+	return d, nil
+}
+
 // Error codes that should trigger key rotation
 var RotatableErrorCodes = []int{401, 403, 429}
 
@@ -164,7 +354,11 @@ func getKeysFromEnv(envVar string) []string {
 // Config holds the application configuration
 type Config struct {
 	// Provider selection
-	Provider string // "copilot", "azure", or "" (auto-detect)
+	Provider string // "copilot", "azure", "openai", "anthropic", or "" (auto-detect)
+
+	// Fallback chain: providers tried in order when a request fails
+	FallbackChain   []FallbackTarget
+	FailoverClasses []string // Error classes that trigger failover (see Failover* constants)
 
 	// Azure OpenAI settings
 	AzureEndpoint   string
@@ -177,6 +371,17 @@ type Config struct {
 	AccountType   string   // "individual", "business", or "enterprise"
 	CopilotModels []string // Available Copilot models
 
+	// OpenAI-compatible settings (OpenAI, vLLM, llama.cpp, Ollama, ...)
+	OpenAIBaseURL string            // Base URL including the version prefix, e.g. http://localhost:8000/v1
+	OpenAIAPIKey  string            // Optional bearer key
+	OpenAIHeaders map[string]string // Extra headers sent with every request
+	OpenAIModels  []string          // Available models on the server
+
+	// Anthropic settings
+	AnthropicBaseURL string
+	AnthropicAPIKey  string
+	AnthropicModels  []string
+
 	// Key rotators for search providers
 	TavilyKeys *KeyRotator
 	LinkupKeys *KeyRotator
@@ -187,10 +392,61 @@ type Config struct {
 	linkupKeysFromFile []string
 	braveKeysFromFile  []string
 
+	// Fallback chain entries from the config file ("provider:model")
+	fallbackChainFromFile []string
+
 	// Web search provider selection
 	WebSearchProvider string // "tavily", "linkup", or "brave"
 
+	// Sampling parameters (nil or zero means the provider default)
+	Temperature *float64
+	TopP        *float64
+	MaxTokens   int
+	Seed        *int
+	Stop        []string
+
+	// ReasoningEffort is "low", "medium", "high", or "" for the model default
+	ReasoningEffort string
+
+	// Per-model capability overrides from the config file, keyed by model
+	// name or "prefix*"
+	ModelCapabilities map[string]ModelCapabilityOverride
+
+	// Response cache (opt-in from the config file; --no-cache turns it off)
+	Cache          bool
+	NoCache        bool
+	CacheTTL       time.Duration
+	CacheMaxSizeMB int
+
+	// Cache TTL from the config file, parsed in Validate()
+	cacheTTLFromFile string
+
+	// Usage ledger: every response's token usage is recorded unless
+	// NoUsageLedger is set. ModelPrices, keyed by model name or "prefix*",
+	// estimate spend in the usage report.
+	NoUsageLedger bool
+	ModelPrices   map[string]ModelPrice
+
+	// Usage limits (0 means no limit; MaxToolIterations defaults to
+	// DefaultMaxToolIterations)
+	MaxTokensPerSession int // Tokens the responses of one run may use
+	MaxRequestsPerDay   int // Requests per local day, counted from the usage ledger
+	MaxToolIterations   int // Model round-trips of one tool-calling turn
+
+	// Conversation history retention: how many conversations are kept, and
+	// for how long since their last update (0 means no age limit)
+	HistoryMaxConversations int
+	HistoryMaxAge           time.Duration
+
+	// History max age from the config file, parsed in Validate()
+	historyMaxAgeFromFile string
+
+	// Structured output
+	JSONSchemaFile    string // Path to a JSON Schema the response must satisfy
+	JSONSchemaRetries int    // Re-prompts allowed when the response fails validation
+
 	// Flags
+	Output      string // "text", "json", or "ndjson"
 	Stream      bool
 	Render      bool
 	Usage       bool
@@ -264,8 +520,69 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	// Load OpenAI-compatible settings (env vars override config file)
+	if baseURL := os.Getenv(EnvOpenAIBaseURL); baseURL != "" {
+		c.OpenAIBaseURL = baseURL
+	}
+	if c.OpenAIBaseURL == "" && c.Provider == "openai" {
+		c.OpenAIBaseURL = DefaultOpenAIBaseURL
+	}
+	c.OpenAIBaseURL = strings.TrimSuffix(c.OpenAIBaseURL, "/")
+	if apiKey := strings.TrimSpace(os.Getenv(EnvOpenAIAPIKey)); apiKey != "" {
+		c.OpenAIAPIKey = apiKey
+	}
+	if modelsEnv := os.Getenv(EnvOpenAIModels); modelsEnv != "" {
+		c.OpenAIModels = nil // Clear config file models
+		for _, m := range strings.Split(modelsEnv, ",") {
+			m = strings.TrimSpace(m)
+			if m != "" {
+				c.OpenAIModels = append(c.OpenAIModels, m)
+			}
+		}
+	}
+	if headersEnv := os.Getenv(EnvOpenAIHeaders); headersEnv != "" {
+		if c.OpenAIHeaders == nil {
+			c.OpenAIHeaders = make(map[string]string)
+		}
+		for name, value := range parseHeaders(headersEnv) {
+			c.OpenAIHeaders[name] = value
+		}
+	}
+
+	// Load Anthropic settings (env vars override config file)
+	if baseURL := os.Getenv(EnvAnthropicBaseURL); baseURL != "" {
+		c.AnthropicBaseURL = baseURL
+	}
+	if c.AnthropicBaseURL == "" {
+		c.AnthropicBaseURL = DefaultAnthropicURL
+	}
+	c.AnthropicBaseURL = strings.TrimSuffix(c.AnthropicBaseURL, "/")
+	if apiKey := strings.TrimSpace(os.Getenv(EnvAnthropicAPIKey)); apiKey != "" {
+		c.AnthropicAPIKey = apiKey
+	}
+	if modelsEnv := os.Getenv(EnvAnthropicModels); modelsEnv != "" {
+		c.AnthropicModels = nil // Clear config file models
+		for _, m := range strings.Split(modelsEnv, ",") {
+			m = strings.TrimSpace(m)
+			if m != "" {
+				c.AnthropicModels = append(c.AnthropicModels, m)
+			}
+		}
+	}
+	if len(c.AnthropicModels) == 0 {
+		c.AnthropicModels = DefaultAnthropicModels
+	}
+
 	// Set available models based on provider
-	if c.Provider == "azure" {
+	if c.Provider == "anthropic" {
+		if c.AnthropicAPIKey == "" {
+			return ErrAnthropicKeyNotFound
+		}
+		c.AvailableModels = c.AnthropicModels
+	} else if c.Provider == "openai" {
+		// Local servers often accept any model name, so no list means no validation
+		c.AvailableModels = c.OpenAIModels
+	} else if c.Provider == "azure" {
 		// Azure provider requires endpoint and key
 		if c.AzureEndpoint == "" {
 			return ErrEndpointNotFound
@@ -286,11 +603,84 @@ func (c *Config) Validate() error {
 			c.AvailableModels = c.CopilotModels
 		} else if len(c.AzureModels) > 0 {
 			c.AvailableModels = c.AzureModels
+		} else if c.OpenAIBaseURL != "" && (c.AzureEndpoint == "" || c.AzureAPIKey == "") {
+			// Mirrors NewClient: OpenAI-compatible is used only when Azure isn't configured
+			c.AvailableModels = c.OpenAIModels
 		} else {
 			c.AvailableModels = c.CopilotModels
 		}
 	}
 
+	// Load fallback chain (env var overrides config file)
+	chainEntries := c.fallbackChainFromFile
+	if chainEnv := os.Getenv(EnvFallbackChain); chainEnv != "" {
+		chainEntries = strings.Split(chainEnv, ",")
+	}
+	if len(chainEntries) > 0 {
+		c.FallbackChain = nil
+		for _, entry := range chainEntries {
+			if strings.TrimSpace(entry) == "" {
+				continue
+			}
+			target, err := ParseFallbackTarget(entry)
+			if err != nil {
+				return err
+			}
+			c.FallbackChain = append(c.FallbackChain, target)
+		}
+	}
+	if classesEnv := os.Getenv(EnvFallbackClasses); classesEnv != "" {
+		c.FailoverClasses = nil
+		for _, class := range strings.Split(classesEnv, ",") {
+			class = strings.TrimSpace(class)
+			if class != "" {
+				c.FailoverClasses = append(c.FailoverClasses, class)
+			}
+		}
+	}
+	if len(c.FailoverClasses) == 0 {
+		c.FailoverClasses = DefaultFailoverClasses
+	}
+	for _, class := range c.FailoverClasses {
+		switch class {
+		case FailoverRateLimit, FailoverServerError, FailoverAuth, FailoverClientError, FailoverNetwork:
+		default:
+			return fmt.Errorf("%w: %q", ErrInvalidFailoverClass, class)
+		}
+	}
+
+	if err := c.ValidateSampling(); err != nil {
+		return err
+	}
+
+	if err := c.resolveCacheSettings(); err != nil {
+		return err
+	}
+
+	if err := c.ValidateLimits(); err != nil {
+		return err
+	}
+
+	if err := c.resolveHistorySettings(); err != nil {
+		return err
+	}
+
+	for model, o := range c.ModelCapabilities {
+		switch o.SystemRole {
+		case "", SystemRoleSystem, SystemRoleDeveloper, SystemRoleUser:
+		default:
+			return fmt.Errorf("%w (model_capabilities %q)", ErrInvalidSystemRole, model)
+		}
+	}
+
+	switch c.Output {
+	case "":
+		c.Output = OutputText
+	case OutputText, OutputJSON, OutputNDJSON:
+	default:
+		return ErrInvalidOutputFormat
+	}
+
 	// Load default model - pick first available if not set
 	if c.Model == "" {
 		if len(c.AvailableModels) > 0 {
@@ -349,6 +739,43 @@ func (c *Config) GetAzureAPIURL() string {
 		c.AzureEndpoint)
 }
 
+// GetAzureDeploymentsURL builds the URL listing the resource's deployments
+func (c *Config) GetAzureDeploymentsURL() string {
+	return fmt.Sprintf("%s/openai/deployments?api-version=2022-12-01", c.AzureEndpoint)
+}
+
+// GetOpenAIAPIURL builds the full API URL for chat completions on an OpenAI-compatible server
+func (c *Config) GetOpenAIAPIURL() string {
+	return c.OpenAIBaseURL + "/chat/completions"
+}
+
+// GetAnthropicAPIURL builds the full API URL for the Anthropic Messages API
+func (c *Config) GetAnthropicAPIURL() string {
+	return c.AnthropicBaseURL + "/v1/messages"
+}
+
+// parseHeaders parses comma-separated "Name=Value" pairs into a header map
+func parseHeaders(s string) map[string]string {
+	headers := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		name, value, ok := strings.Cut(pair, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			continue
+		}
+		headers[name] = strings.TrimSpace(value)
+	}
+	return headers
+}
+
+// SetLiveModels replaces the configured model list with the provider's
+// live list, so ValidateModel and completion use what is actually available
+func (c *Config) SetLiveModels(models []string) {
+	if len(models) > 0 {
+		c.AvailableModels = models
+	}
+}
+
 // ValidateModel checks if the given model is in available models
 func (c *Config) ValidateModel(model string) bool {
 	if len(c.AvailableModels) == 0 {
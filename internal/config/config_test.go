@@ -1,9 +1,11 @@
 package config
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 // Helper to set environment variable for test and restore after
@@ -40,7 +42,9 @@ func clearAllEnvVars(t *testing.T) {
 	envVars := []string{
 		EnvAzureEndpoint, EnvAzureAPIKey, EnvAzureModels,
 		EnvCopilotAccountType, EnvCopilotModels,
-		EnvAIProvider,
+		EnvOpenAIBaseURL, EnvOpenAIAPIKey, EnvOpenAIModels, EnvOpenAIHeaders,
+		EnvAnthropicBaseURL, EnvAnthropicAPIKey, EnvAnthropicModels,
+		EnvAIProvider, EnvFallbackChain, EnvFallbackClasses,
 		EnvTavilyAPIKeys, EnvLinkupAPIKeys, EnvBraveAPIKeys,
 		EnvWebSearchProvider,
 	}
@@ -490,10 +494,294 @@ func TestConfig_Validate_AzureEndpointTrailingSlash(t *testing.T) {
 	}
 }
 
+func TestConfig_Validate_OpenAIProvider_DefaultBaseURL(t *testing.T) {
+	runInTempDir(t)
+	clearAllEnvVars(t)
+
+	cfg := NewConfig()
+	cfg.Provider = "openai"
+
+	err := cfg.Validate()
+	if err != nil {
+		t.Errorf("Validate() error = %v, want nil", err)
+	}
+	if cfg.OpenAIBaseURL != DefaultOpenAIBaseURL {
+		t.Errorf("OpenAIBaseURL = %q, want %q", cfg.OpenAIBaseURL, DefaultOpenAIBaseURL)
+	}
+	// No models configured means no validation
+	if len(cfg.AvailableModels) != 0 {
+		t.Errorf("AvailableModels length = %d, want 0", len(cfg.AvailableModels))
+	}
+}
+
+func TestConfig_Validate_OpenAIProvider_EnvVarLoading(t *testing.T) {
+	runInTempDir(t)
+	clearAllEnvVars(t)
+	setEnvForTest(t, EnvAIProvider, "openai")
+	setEnvForTest(t, EnvOpenAIBaseURL, "http://localhost:8000/v1/")
+	setEnvForTest(t, EnvOpenAIAPIKey, "local-key")
+	setEnvForTest(t, EnvOpenAIModels, "llama-3,qwen-2.5")
+	setEnvForTest(t, EnvOpenAIHeaders, "X-Team=infra, X-Trace = on,invalid")
+
+	cfg := NewConfig()
+	err := cfg.Validate()
+	if err != nil {
+		t.Errorf("Validate() error = %v, want nil", err)
+	}
+
+	if cfg.OpenAIBaseURL != "http://localhost:8000/v1" {
+		t.Errorf("OpenAIBaseURL = %q, want trailing slash removed", cfg.OpenAIBaseURL)
+	}
+	if cfg.OpenAIAPIKey != "local-key" {
+		t.Errorf("OpenAIAPIKey = %q, want %q", cfg.OpenAIAPIKey, "local-key")
+	}
+	if len(cfg.AvailableModels) != 2 || cfg.Model != "llama-3" {
+		t.Errorf("AvailableModels = %v, Model = %q, want 2 models and llama-3", cfg.AvailableModels, cfg.Model)
+	}
+	if len(cfg.OpenAIHeaders) != 2 {
+		t.Errorf("OpenAIHeaders length = %d, want 2", len(cfg.OpenAIHeaders))
+	}
+	if cfg.OpenAIHeaders["X-Trace"] != "on" {
+		t.Errorf("OpenAIHeaders[X-Trace] = %q, want %q", cfg.OpenAIHeaders["X-Trace"], "on")
+	}
+}
+
+func TestConfig_Validate_AnthropicProvider_MissingAPIKey(t *testing.T) {
+	runInTempDir(t)
+	clearAllEnvVars(t)
+
+	cfg := NewConfig()
+	cfg.Provider = "anthropic"
+
+	err := cfg.Validate()
+	if err != ErrAnthropicKeyNotFound {
+		t.Errorf("Validate() error = %v, want ErrAnthropicKeyNotFound", err)
+	}
+}
+
+func TestConfig_Validate_AnthropicProvider_Valid(t *testing.T) {
+	runInTempDir(t)
+	clearAllEnvVars(t)
+	setEnvForTest(t, EnvAnthropicAPIKey, "sk-ant-test")
+
+	cfg := NewConfig()
+	cfg.Provider = "anthropic"
+
+	err := cfg.Validate()
+	if err != nil {
+		t.Errorf("Validate() error = %v, want nil", err)
+	}
+	if cfg.AnthropicBaseURL != DefaultAnthropicURL {
+		t.Errorf("AnthropicBaseURL = %q, want %q", cfg.AnthropicBaseURL, DefaultAnthropicURL)
+	}
+	if cfg.Model != DefaultAnthropicModels[0] {
+		t.Errorf("Model = %q, want %q", cfg.Model, DefaultAnthropicModels[0])
+	}
+	if cfg.GetAnthropicAPIURL() != DefaultAnthropicURL+"/v1/messages" {
+		t.Errorf("GetAnthropicAPIURL() = %q", cfg.GetAnthropicAPIURL())
+	}
+}
+
+func TestConfig_Validate_FallbackChain(t *testing.T) {
+	runInTempDir(t)
+	clearAllEnvVars(t)
+	setEnvForTest(t, EnvFallbackChain, "copilot:gpt-4.1, Azure:gpt-4o,openai")
+	setEnvForTest(t, EnvFallbackClasses, "rate_limit,auth")
+
+	cfg := NewConfig()
+	err := cfg.Validate()
+	if err != nil {
+		t.Fatalf("Validate() error = %v, want nil", err)
+	}
+
+	want := []FallbackTarget{
+		{Provider: "copilot", Model: "gpt-4.1"},
+		{Provider: "azure", Model: "gpt-4o"},
+		{Provider: "openai"},
+	}
+	if len(cfg.FallbackChain) != len(want) {
+		t.Fatalf("FallbackChain = %v, want %v", cfg.FallbackChain, want)
+	}
+	for i := range want {
+		if cfg.FallbackChain[i] != want[i] {
+			t.Errorf("FallbackChain[%d] = %v, want %v", i, cfg.FallbackChain[i], want[i])
+		}
+	}
+	if len(cfg.FailoverClasses) != 2 || cfg.FailoverClasses[1] != FailoverAuth {
+		t.Errorf("FailoverClasses = %v, want [rate_limit auth]", cfg.FailoverClasses)
+	}
+}
+
+func TestConfig_Validate_FallbackChain_Invalid(t *testing.T) {
+	runInTempDir(t)
+	clearAllEnvVars(t)
+	setEnvForTest(t, EnvFallbackChain, "copilot:gpt-4.1,bedrock:claude")
+
+	cfg := NewConfig()
+	err := cfg.Validate()
+	if !errors.Is(err, ErrInvalidFallbackEntry) {
+		t.Errorf("Validate() error = %v, want ErrInvalidFallbackEntry", err)
+	}
+}
+
+func TestConfig_Validate_FailoverClass_Invalid(t *testing.T) {
+	runInTempDir(t)
+	clearAllEnvVars(t)
+	setEnvForTest(t, EnvFallbackClasses, "timeouts")
+
+	cfg := NewConfig()
+	err := cfg.Validate()
+	if !errors.Is(err, ErrInvalidFailoverClass) {
+		t.Errorf("Validate() error = %v, want ErrInvalidFailoverClass", err)
+	}
+}
+
+func TestConfig_ValidateSampling(t *testing.T) {
+	float := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr error
+	}{
+		{"unset", Config{}, nil},
+		{"valid", Config{Temperature: float(0), TopP: float(1), MaxTokens: 100}, nil},
+		{"temperature too high", Config{Temperature: float(2.5)}, ErrInvalidTemperature},
+		{"negative temperature", Config{Temperature: float(-0.1)}, ErrInvalidTemperature},
+		{"top_p too high", Config{TopP: float(1.5)}, ErrInvalidTopP},
+		{"negative max_tokens", Config{MaxTokens: -1}, ErrInvalidMaxTokens},
+		{"reasoning effort", Config{ReasoningEffort: ReasoningHigh}, nil},
+		{"unknown reasoning effort", Config{ReasoningEffort: "max"}, ErrInvalidReasoning},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cfg.ValidateSampling(); err != tt.wantErr {
+				t.Errorf("ValidateSampling() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfig_Validate_OutputFormat(t *testing.T) {
+	runInTempDir(t)
+	clearAllEnvVars(t)
+
+	cfg := NewConfig()
+	cfg.Output = "yaml"
+	if err := cfg.Validate(); !errors.Is(err, ErrInvalidOutputFormat) {
+		t.Errorf("Validate() error = %v, want ErrInvalidOutputFormat", err)
+	}
+}
+
+func TestConfig_ValidateLimits(t *testing.T) {
+	tests := []struct {
+		name           string
+		cfg            Config
+		wantIterations int
+		wantErr        error
+	}{
+		{"defaults", Config{}, DefaultMaxToolIterations, nil},
+		{"set", Config{MaxTokensPerSession: 50000, MaxRequestsPerDay: 100, MaxToolIterations: 10}, 10, nil},
+		{"negative tokens", Config{MaxTokensPerSession: -1}, 0, ErrInvalidLimit},
+		{"negative requests", Config{MaxRequestsPerDay: -1}, 0, ErrInvalidLimit},
+		{"negative iterations", Config{MaxToolIterations: -5}, 0, ErrInvalidLimit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.ValidateLimits()
+			if err != tt.wantErr {
+				t.Fatalf("ValidateLimits() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && tt.cfg.MaxToolIterations != tt.wantIterations {
+				t.Errorf("MaxToolIterations = %d, want %d", tt.cfg.MaxToolIterations, tt.wantIterations)
+			}
+		})
+	}
+}
+
+func TestConfig_ResolveCacheSettings(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      Config
+		wantTTL  time.Duration
+		wantSize int
+		wantErr  error
+	}{
+		{"defaults", Config{}, DefaultResponseCacheTTL, DefaultResponseCacheMaxSizeMB, nil},
+		{"from file", Config{cacheTTLFromFile: "30m", CacheMaxSizeMB: 10}, 30 * time.Minute, 10, nil},
+		{"invalid ttl", Config{cacheTTLFromFile: "soon"}, 0, 0, ErrInvalidCacheTTL},
+		{"negative ttl", Config{cacheTTLFromFile: "-1h"}, 0, 0, ErrInvalidCacheTTL},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.resolveCacheSettings()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("resolveCacheSettings() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && (tt.cfg.CacheTTL != tt.wantTTL || tt.cfg.CacheMaxSizeMB != tt.wantSize) {
+				t.Errorf("resolveCacheSettings() = %v, %d MB; want %v, %d MB", tt.cfg.CacheTTL, tt.cfg.CacheMaxSizeMB, tt.wantTTL, tt.wantSize)
+			}
+		})
+	}
+}
+
+func TestConfig_ResolveHistorySettings(t *testing.T) {
+	tests := []struct {
+		name      string
+		cfg       Config
+		wantAge   time.Duration
+		wantCount int
+		wantErr   error
+	}{
+		{"defaults", Config{}, 0, DefaultHistoryMaxConversations, nil},
+		{"from file", Config{historyMaxAgeFromFile: "90d", HistoryMaxConversations: 50}, 90 * 24 * time.Hour, 50, nil},
+		{"go duration", Config{historyMaxAgeFromFile: "36h"}, 36 * time.Hour, DefaultHistoryMaxConversations, nil},
+		{"invalid age", Config{historyMaxAgeFromFile: "forever"}, 0, 0, ErrInvalidHistoryMaxAge},
+		{"zero days", Config{historyMaxAgeFromFile: "0d"}, 0, 0, ErrInvalidHistoryMaxAge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.resolveHistorySettings()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("resolveHistorySettings() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && (tt.cfg.HistoryMaxAge != tt.wantAge || tt.cfg.HistoryMaxConversations != tt.wantCount) {
+				t.Errorf("resolveHistorySettings() = %v, %d; want %v, %d", tt.cfg.HistoryMaxAge, tt.cfg.HistoryMaxConversations, tt.wantAge, tt.wantCount)
+			}
+		})
+	}
+}
+
+func TestFallbackTarget_String(t *testing.T) {
+	if got := (FallbackTarget{Provider: "azure", Model: "gpt-4o"}).String(); got != "azure:gpt-4o" {
+		t.Errorf("String() = %q, want %q", got, "azure:gpt-4o")
+	}
+	if got := (FallbackTarget{Provider: "copilot"}).String(); got != "copilot" {
+		t.Errorf("String() = %q, want %q", got, "copilot")
+	}
+}
+
 // =============================================================================
 // Helper Method Tests
 // =============================================================================
 
+func TestConfig_GetOpenAIAPIURL(t *testing.T) {
+	cfg := &Config{
+		OpenAIBaseURL: "http://localhost:11434/v1",
+	}
+
+	url := cfg.GetOpenAIAPIURL()
+	expected := "http://localhost:11434/v1/chat/completions"
+
+	if url != expected {
+		t.Errorf("GetOpenAIAPIURL() = %q, want %q", url, expected)
+	}
+}
+
 func TestConfig_GetAzureAPIURL(t *testing.T) {
 	cfg := &Config{
 		AzureEndpoint: "https://myresource.openai.azure.com",
@@ -544,6 +832,23 @@ func TestConfig_ValidateModel_EmptyAvailable(t *testing.T) {
 	}
 }
 
+func TestConfig_SetLiveModels(t *testing.T) {
+	cfg := &Config{AvailableModels: []string{"gpt-4.1", "retired-model"}}
+
+	cfg.SetLiveModels(nil)
+	if !cfg.ValidateModel("retired-model") {
+		t.Error("SetLiveModels(nil) should keep the configured list")
+	}
+
+	cfg.SetLiveModels([]string{"gpt-4.1", "gpt-5.2"})
+	if cfg.ValidateModel("retired-model") {
+		t.Error("ValidateModel() should reject models missing from the live list")
+	}
+	if !cfg.ValidateModel("gpt-5.2") {
+		t.Error("ValidateModel() should accept models from the live list")
+	}
+}
+
 func TestConfig_GetAvailableModelsString(t *testing.T) {
 	tests := []struct {
 		name     string
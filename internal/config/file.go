@@ -14,7 +14,7 @@ const ConfigFileName = "config.yaml"
 // FileConfig represents the configuration file structure
 type FileConfig struct {
 	// Provider selection
-	Provider string `yaml:"provider,omitempty"` // "copilot", "azure"
+	Provider string `yaml:"provider,omitempty"` // "copilot", "azure", "openai", "anthropic"
 
 	// Model settings
 	Model string `yaml:"model,omitempty"`
@@ -25,11 +25,35 @@ type FileConfig struct {
 	// Azure settings
 	Azure *AzureConfig `yaml:"azure,omitempty"`
 
+	// OpenAI-compatible settings
+	OpenAI *OpenAIConfig `yaml:"openai,omitempty"`
+
+	// Anthropic settings
+	Anthropic *AnthropicConfig `yaml:"anthropic,omitempty"`
+
+	// Fallback chain settings
+	Fallback *FallbackConfig `yaml:"fallback,omitempty"`
+
 	// Web search settings
 	WebSearch *WebSearchConfig `yaml:"web_search,omitempty"`
 
 	// Default flags
 	Defaults *DefaultsConfig `yaml:"defaults,omitempty"`
+
+	// Response cache settings
+	Cache *CacheConfig `yaml:"cache,omitempty"`
+
+	// Usage ledger settings
+	Usage *UsageConfig `yaml:"usage,omitempty"`
+
+	// Usage limits
+	Limits *LimitsConfig `yaml:"limits,omitempty"`
+
+	// Conversation history retention
+	History *HistoryConfig `yaml:"history,omitempty"`
+
+	// Per-model capability overrides, keyed by model name or "prefix*"
+	ModelCapabilities map[string]ModelCapabilityOverride `yaml:"model_capabilities,omitempty"`
 }
 
 // CopilotConfig holds GitHub Copilot-specific configuration
@@ -45,6 +69,27 @@ type AzureConfig struct {
 	Models   []string `yaml:"models,omitempty"`
 }
 
+// OpenAIConfig holds settings for an OpenAI-compatible /v1/chat/completions server
+type OpenAIConfig struct {
+	BaseURL string            `yaml:"base_url,omitempty"` // e.g. http://localhost:8000/v1
+	APIKey  string            `yaml:"api_key,omitempty"`
+	Headers map[string]string `yaml:"headers,omitempty"` // Extra headers sent with every request
+	Models  []string          `yaml:"models,omitempty"`
+}
+
+// AnthropicConfig holds Anthropic Messages API configuration
+type AnthropicConfig struct {
+	BaseURL string   `yaml:"base_url,omitempty"` // default: https://api.anthropic.com
+	APIKey  string   `yaml:"api_key,omitempty"`
+	Models  []string `yaml:"models,omitempty"`
+}
+
+// FallbackConfig holds the multi-provider fallback chain
+type FallbackConfig struct {
+	Chain      []string `yaml:"chain,omitempty"`       // Ordered "provider:model" entries
+	FailoverOn []string `yaml:"failover_on,omitempty"` // rate_limit, server_error, auth, client_error, network
+}
+
 // WebSearchConfig holds web search configuration
 type WebSearchConfig struct {
 	Provider   string   `yaml:"provider,omitempty"` // "tavily", "linkup", "brave"
@@ -53,12 +98,66 @@ type WebSearchConfig struct {
 	BraveKeys  []string `yaml:"brave_keys,omitempty"`
 }
 
+// CacheConfig holds the on-disk response cache settings
+type CacheConfig struct {
+	Enabled   bool   `yaml:"enabled,omitempty"`
+	TTL       string `yaml:"ttl,omitempty"`         // e.g. "24h", "30m"
+	MaxSizeMB int    `yaml:"max_size_mb,omitempty"` // default: 100
+}
+
+// UsageConfig holds the usage ledger settings
+type UsageConfig struct {
+	Ledger *bool `yaml:"ledger,omitempty"` // default: true
+	// Prices estimate spend in "ai-cli usage", keyed by model name or "prefix*"
+	Prices map[string]ModelPrice `yaml:"prices,omitempty"`
+}
+
+// ModelPrice is what a model costs, in dollars per million tokens
+type ModelPrice struct {
+	Input  float64 `yaml:"input"`
+	Output float64 `yaml:"output"`
+}
+
+// LimitsConfig holds the usage limits; 0 means no limit
+type LimitsConfig struct {
+	MaxTokensPerSession int `yaml:"max_tokens_per_session,omitempty"`
+	MaxRequestsPerDay   int `yaml:"max_requests_per_day,omitempty"`
+	MaxToolIterations   int `yaml:"max_tool_iterations,omitempty"` // default: 25
+}
+
+// HistoryConfig holds the conversation history retention
+type HistoryConfig struct {
+	MaxConversations int    `yaml:"max_conversations,omitempty"` // default: 200
+	MaxAge           string `yaml:"max_age,omitempty"`           // e.g. "90d"; default: no limit
+}
+
 // DefaultsConfig holds default flag values
 type DefaultsConfig struct {
 	Stream    bool `yaml:"stream,omitempty"`
 	Render    bool `yaml:"render,omitempty"`
 	WebSearch bool `yaml:"web_search,omitempty"`
 	Citations bool `yaml:"citations,omitempty"`
+
+	// Sampling parameters
+	Temperature *float64 `yaml:"temperature,omitempty"`
+	TopP        *float64 `yaml:"top_p,omitempty"`
+	MaxTokens   int      `yaml:"max_tokens,omitempty"`
+	Seed        *int     `yaml:"seed,omitempty"`
+	Stop        []string `yaml:"stop,omitempty"`
+
+	// Reasoning effort for reasoning models: low, medium, or high
+	ReasoningEffort string `yaml:"reasoning_effort,omitempty"`
+}
+
+// ModelCapabilityOverride corrects the built-in capabilities of a model.
+// Unset fields keep the built-in or provider-reported value.
+type ModelCapabilityOverride struct {
+	ContextWindow       int    `yaml:"context_window,omitempty"`
+	MaxOutputTokens     int    `yaml:"max_output_tokens,omitempty"`
+	ToolCalls           *bool  `yaml:"tool_calls,omitempty"`
+	Vision              *bool  `yaml:"vision,omitempty"`
+	SystemRole          string `yaml:"system_role,omitempty"` // "system", "developer", or "user"
+	MaxCompletionTokens *bool  `yaml:"max_completion_tokens,omitempty"`
 }
 
 // GetConfigPaths returns the paths to check for config files (in order of priority)
@@ -141,6 +240,52 @@ func (c *Config) ApplyFileConfig(fc *FileConfig) {
 		}
 	}
 
+	// OpenAI-compatible config
+	if fc.OpenAI != nil {
+		if c.OpenAIBaseURL == "" && fc.OpenAI.BaseURL != "" {
+			c.OpenAIBaseURL = fc.OpenAI.BaseURL
+		}
+		if c.OpenAIAPIKey == "" && fc.OpenAI.APIKey != "" {
+			c.OpenAIAPIKey = fc.OpenAI.APIKey
+		}
+		if len(fc.OpenAI.Headers) > 0 {
+			if c.OpenAIHeaders == nil {
+				c.OpenAIHeaders = make(map[string]string)
+			}
+			for name, value := range fc.OpenAI.Headers {
+				if _, exists := c.OpenAIHeaders[name]; !exists {
+					c.OpenAIHeaders[name] = value
+				}
+			}
+		}
+		if len(fc.OpenAI.Models) > 0 {
+			c.OpenAIModels = fc.OpenAI.Models
+		}
+	}
+
+	// Anthropic config
+	if fc.Anthropic != nil {
+		if c.AnthropicBaseURL == "" && fc.Anthropic.BaseURL != "" {
+			c.AnthropicBaseURL = fc.Anthropic.BaseURL
+		}
+		if c.AnthropicAPIKey == "" && fc.Anthropic.APIKey != "" {
+			c.AnthropicAPIKey = fc.Anthropic.APIKey
+		}
+		if len(fc.Anthropic.Models) > 0 {
+			c.AnthropicModels = fc.Anthropic.Models
+		}
+	}
+
+	// Fallback config (chain entries are parsed and checked in Validate())
+	if fc.Fallback != nil {
+		if len(fc.Fallback.Chain) > 0 {
+			c.fallbackChainFromFile = fc.Fallback.Chain
+		}
+		if len(c.FailoverClasses) == 0 && len(fc.Fallback.FailoverOn) > 0 {
+			c.FailoverClasses = fc.Fallback.FailoverOn
+		}
+	}
+
 	// Copilot config
 	if fc.Copilot != nil {
 		if c.AccountType == "" && fc.Copilot.AccountType != "" {
@@ -169,6 +314,57 @@ func (c *Config) ApplyFileConfig(fc *FileConfig) {
 		}
 	}
 
+	// Response cache config (the TTL is parsed in Validate())
+	if fc.Cache != nil {
+		if fc.Cache.Enabled {
+			c.Cache = true
+		}
+		if fc.Cache.TTL != "" {
+			c.cacheTTLFromFile = fc.Cache.TTL
+		}
+		if c.CacheMaxSizeMB == 0 && fc.Cache.MaxSizeMB > 0 {
+			c.CacheMaxSizeMB = fc.Cache.MaxSizeMB
+		}
+	}
+
+	// Usage ledger config
+	if fc.Usage != nil {
+		if fc.Usage.Ledger != nil && !*fc.Usage.Ledger {
+			c.NoUsageLedger = true
+		}
+		if len(fc.Usage.Prices) > 0 && c.ModelPrices == nil {
+			c.ModelPrices = fc.Usage.Prices
+		}
+	}
+
+	// Usage limits
+	if fc.Limits != nil {
+		if c.MaxTokensPerSession == 0 {
+			c.MaxTokensPerSession = fc.Limits.MaxTokensPerSession
+		}
+		if c.MaxRequestsPerDay == 0 {
+			c.MaxRequestsPerDay = fc.Limits.MaxRequestsPerDay
+		}
+		if c.MaxToolIterations == 0 {
+			c.MaxToolIterations = fc.Limits.MaxToolIterations
+		}
+	}
+
+	// History retention (the max age is parsed in Validate())
+	if fc.History != nil {
+		if c.HistoryMaxConversations == 0 {
+			c.HistoryMaxConversations = fc.History.MaxConversations
+		}
+		if fc.History.MaxAge != "" {
+			c.historyMaxAgeFromFile = fc.History.MaxAge
+		}
+	}
+
+	// Model capability overrides
+	if len(fc.ModelCapabilities) > 0 && c.ModelCapabilities == nil {
+		c.ModelCapabilities = fc.ModelCapabilities
+	}
+
 	// Apply defaults (these are applied unless explicitly overridden by flags)
 	if fc.Defaults != nil {
 		// Note: These only apply if the flags weren't explicitly set
@@ -186,6 +382,26 @@ func (c *Config) ApplyFileConfig(fc *FileConfig) {
 		if fc.Defaults.Citations && !c.Citations {
 			c.Citations = true
 		}
+
+		// Sampling parameters can be told apart from "unset", so flags simply win
+		if c.Temperature == nil && fc.Defaults.Temperature != nil {
+			c.Temperature = fc.Defaults.Temperature
+		}
+		if c.TopP == nil && fc.Defaults.TopP != nil {
+			c.TopP = fc.Defaults.TopP
+		}
+		if c.MaxTokens == 0 && fc.Defaults.MaxTokens > 0 {
+			c.MaxTokens = fc.Defaults.MaxTokens
+		}
+		if c.Seed == nil && fc.Defaults.Seed != nil {
+			c.Seed = fc.Defaults.Seed
+		}
+		if len(c.Stop) == 0 && len(fc.Defaults.Stop) > 0 {
+			c.Stop = fc.Defaults.Stop
+		}
+		if c.ReasoningEffort == "" && fc.Defaults.ReasoningEffort != "" {
+			c.ReasoningEffort = fc.Defaults.ReasoningEffort
+		}
 	}
 }
 
@@ -213,7 +429,7 @@ func CreateDefaultConfigFile() (string, error) {
 	defaultConfig := `# AI CLI Configuration
 # Location: ~/.config/ai-cli/config.yaml
 
-# AI provider: "copilot" or "azure" (default: auto-detect)
+# AI provider: "copilot", "azure", "openai", or "anthropic" (default: auto-detect)
 # provider: copilot
 
 # Default model to use (must be valid for your provider)
@@ -239,6 +455,33 @@ func CreateDefaultConfigFile() (string, error) {
 #     - gpt-4
 #     - gpt-4o
 
+# OpenAI-compatible server settings (required if provider: openai)
+# Works with OpenAI, vLLM, llama.cpp, Ollama and other /v1/chat/completions servers
+# openai:
+#   base_url: http://localhost:8000/v1
+#   api_key: your-api-key  # optional for local servers
+#   headers:  # extra headers sent with every request
+#     X-Custom-Header: value
+#   models:
+#     - llama-3.1-8b-instruct
+
+# Anthropic settings (required if provider: anthropic)
+# anthropic:
+#   api_key: your-anthropic-key
+#   models:
+#     - claude-sonnet-4-5
+#     - claude-opus-4-1
+
+# Fallback chain: try providers in order when a request fails
+# fallback:
+#   chain:
+#     - copilot:gpt-4.1
+#     - azure:gpt-4o
+#   failover_on:  # rate_limit, server_error, auth, client_error, network
+#     - rate_limit
+#     - server_error
+#     - network
+
 # Web search settings
 # web_search:
 #   provider: tavily  # tavily, linkup, or brave
@@ -255,6 +498,12 @@ func CreateDefaultConfigFile() (string, error) {
 #   render: true
 #   web_search: false
 #   citations: false
+#   temperature: 0.2  # sampling parameters, overridden by --temperature etc.
+#   top_p: 0.9
+#   max_tokens: 2048
+#   seed: 42
+#   stop:
+#     - "###"
 `
 
 	if err := os.WriteFile(path, []byte(defaultConfig), 0600); err != nil {
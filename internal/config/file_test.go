@@ -343,6 +343,38 @@ func TestConfig_ApplyFileConfig_Azure_NoOverwrite(t *testing.T) {
 	}
 }
 
+func TestConfig_ApplyFileConfig_OpenAI(t *testing.T) {
+	cfg := NewConfig()
+	cfg.OpenAIHeaders = map[string]string{"X-Team": "from-env"}
+
+	fc := &FileConfig{
+		OpenAI: &OpenAIConfig{
+			BaseURL: "http://localhost:8000/v1",
+			APIKey:  "file-key",
+			Headers: map[string]string{"X-Team": "from-file", "X-Extra": "1"},
+			Models:  []string{"llama-3"},
+		},
+	}
+	cfg.ApplyFileConfig(fc)
+
+	if cfg.OpenAIBaseURL != "http://localhost:8000/v1" {
+		t.Errorf("OpenAIBaseURL = %q, want %q", cfg.OpenAIBaseURL, "http://localhost:8000/v1")
+	}
+	if cfg.OpenAIAPIKey != "file-key" {
+		t.Errorf("OpenAIAPIKey = %q, want %q", cfg.OpenAIAPIKey, "file-key")
+	}
+	// Existing headers should NOT be overwritten
+	if cfg.OpenAIHeaders["X-Team"] != "from-env" {
+		t.Errorf("OpenAIHeaders[X-Team] = %q, should not be overwritten", cfg.OpenAIHeaders["X-Team"])
+	}
+	if cfg.OpenAIHeaders["X-Extra"] != "1" {
+		t.Errorf("OpenAIHeaders[X-Extra] = %q, want %q", cfg.OpenAIHeaders["X-Extra"], "1")
+	}
+	if len(cfg.OpenAIModels) != 1 {
+		t.Errorf("OpenAIModels length = %d, want 1", len(cfg.OpenAIModels))
+	}
+}
+
 func TestConfig_ApplyFileConfig_Copilot(t *testing.T) {
 	cfg := NewConfig()
 
@@ -442,6 +474,41 @@ func TestConfig_ApplyFileConfig_Defaults_AllFlags(t *testing.T) {
 // CreateDefaultConfigFile Tests
 // =============================================================================
 
+func TestConfig_ApplyFileConfig_Defaults_Sampling(t *testing.T) {
+	flagTemp := 0.0
+	cfg := NewConfig()
+	cfg.Temperature = &flagTemp // Set by --temperature 0
+
+	fileTemp, fileTopP, fileSeed := 0.7, 0.9, 42
+	fc := &FileConfig{
+		Defaults: &DefaultsConfig{
+			Temperature: &fileTemp,
+			TopP:        &fileTopP,
+			MaxTokens:   1024,
+			Seed:        &fileSeed,
+			Stop:        []string{"###"},
+		},
+	}
+	cfg.ApplyFileConfig(fc)
+
+	// An explicit zero from a flag must not be replaced by the file default
+	if cfg.Temperature == nil || *cfg.Temperature != 0 {
+		t.Errorf("Temperature = %v, want flag value 0", cfg.Temperature)
+	}
+	if cfg.TopP == nil || *cfg.TopP != 0.9 {
+		t.Errorf("TopP = %v, want 0.9", cfg.TopP)
+	}
+	if cfg.MaxTokens != 1024 {
+		t.Errorf("MaxTokens = %d, want 1024", cfg.MaxTokens)
+	}
+	if cfg.Seed == nil || *cfg.Seed != 42 {
+		t.Errorf("Seed = %v, want 42", cfg.Seed)
+	}
+	if len(cfg.Stop) != 1 || cfg.Stop[0] != "###" {
+		t.Errorf("Stop = %v, want [###]", cfg.Stop)
+	}
+}
+
 func TestCreateDefaultConfigFile_Success(t *testing.T) {
 	// Use a custom temp directory to avoid affecting real config
 	tmpDir := t.TempDir()
@@ -509,3 +576,107 @@ func TestCreateDefaultConfigFile_AlreadyExists(t *testing.T) {
 		t.Error("CreateDefaultConfigFile() should return error when file exists")
 	}
 }
+
+func TestConfig_ApplyFileConfig_ModelCapabilities(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := createTempConfigFile(t, tmpDir, `
+model_capabilities:
+  "o1*":
+    system_role: developer
+  local-llama:
+    context_window: 8192
+    tool_calls: false
+`)
+
+	fc, err := loadConfigFromPath(configPath)
+	if err != nil {
+		t.Fatalf("loadConfigFromPath() error = %v", err)
+	}
+	cfg := NewConfig()
+	cfg.ApplyFileConfig(fc)
+
+	llama := cfg.ModelCapabilities["local-llama"]
+	if llama.ContextWindow != 8192 || llama.ToolCalls == nil || *llama.ToolCalls || llama.Vision != nil {
+		t.Errorf("local-llama override = %+v", llama)
+	}
+	if cfg.ModelCapabilities["o1*"].SystemRole != SystemRoleDeveloper {
+		t.Errorf("o1* override = %+v", cfg.ModelCapabilities["o1*"])
+	}
+}
+
+func TestConfig_ApplyFileConfig_Usage(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := createTempConfigFile(t, tmpDir, `
+usage:
+  ledger: false
+  prices:
+    gpt-4o:
+      input: 2.5
+      output: 10
+    "o3*":
+      input: 2
+      output: 8
+`)
+
+	fc, err := loadConfigFromPath(configPath)
+	if err != nil {
+		t.Fatalf("loadConfigFromPath() error = %v", err)
+	}
+	cfg := NewConfig()
+	cfg.ApplyFileConfig(fc)
+
+	if !cfg.NoUsageLedger {
+		t.Error("ledger: false should disable the usage ledger")
+	}
+	if got := cfg.ModelPrices["gpt-4o"]; got.Input != 2.5 || got.Output != 10 {
+		t.Errorf("gpt-4o price = %+v", got)
+	}
+	if _, ok := cfg.ModelPrices["o3*"]; !ok {
+		t.Errorf("ModelPrices = %+v, want an o3* entry", cfg.ModelPrices)
+	}
+}
+
+func TestConfig_ApplyFileConfig_Limits(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := createTempConfigFile(t, tmpDir, `
+limits:
+  max_tokens_per_session: 200000
+  max_requests_per_day: 500
+  max_tool_iterations: 10
+`)
+
+	fc, err := loadConfigFromPath(configPath)
+	if err != nil {
+		t.Fatalf("loadConfigFromPath() error = %v", err)
+	}
+	cfg := NewConfig()
+	cfg.MaxToolIterations = 3 // Already set (e.g. by a flag)
+	cfg.ApplyFileConfig(fc)
+
+	if cfg.MaxTokensPerSession != 200000 || cfg.MaxRequestsPerDay != 500 {
+		t.Errorf("limits = %d tokens, %d requests", cfg.MaxTokensPerSession, cfg.MaxRequestsPerDay)
+	}
+	if cfg.MaxToolIterations != 3 {
+		t.Errorf("MaxToolIterations = %d, want the value already set", cfg.MaxToolIterations)
+	}
+}
+
+func TestConfig_ApplyFileConfig_History(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := createTempConfigFile(t, tmpDir, `
+history:
+  max_conversations: 500
+  max_age: 30d
+`)
+
+	fc, err := loadConfigFromPath(configPath)
+	if err != nil {
+		t.Fatalf("loadConfigFromPath() error = %v", err)
+	}
+	cfg := NewConfig()
+	cfg.ApplyFileConfig(fc)
+
+	if cfg.HistoryMaxConversations != 500 || cfg.historyMaxAgeFromFile != "30d" {
+		t.Errorf("history = %d conversations, max age %q", cfg.HistoryMaxConversations, cfg.historyMaxAgeFromFile)
+	}
+}
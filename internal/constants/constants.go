@@ -6,13 +6,23 @@ import "time"
 
 // Timeout constants used across the application
 const (
-	// DefaultAPITimeout is the timeout for AI API requests (streaming can take a while)
+	// DefaultAPITimeout bounds the wait for an AI API response to start
+	// (for non-streaming requests, the whole answer)
 	DefaultAPITimeout = 120 * time.Second
+	// DefaultStreamIdleTimeout is how long a stream may go without data
+	// before it is treated as dropped
+	DefaultStreamIdleTimeout = 60 * time.Second
 	// DefaultCommandTimeout is the timeout for shell command execution
 	// 5 minutes to support long-running scripts (builds, tests, npm install)
 	DefaultCommandTimeout = 5 * time.Minute
 	// DefaultOAuthTimeout is the timeout for OAuth HTTP requests
 	DefaultOAuthTimeout = 30 * time.Second
+	// DefaultModelDiscoveryTimeout bounds fetching a provider's model list
+	DefaultModelDiscoveryTimeout = 10 * time.Second
+	// DefaultModelCacheTTL is how long a fetched model list is reused
+	DefaultModelCacheTTL = 24 * time.Hour
+	// DefaultResponseCacheTTL is how long a cached response is replayed
+	DefaultResponseCacheTTL = 24 * time.Hour
 )
 
 // Application defaults
@@ -21,9 +31,21 @@ const (
 	DefaultSystemMessage  = "Be precise and concise."
 	DefaultSearchProvider = "tavily"
 	DefaultAccountType    = "individual"
+	// DefaultJSONSchemaRetries is how many times a response that fails
+	// --json-schema validation is sent back to the model for correction
+	DefaultJSONSchemaRetries = 2
+	// DefaultMaxToolIterations caps the model round-trips of one tool-calling
+	// turn, interactive or agent (limits.max_tool_iterations overrides it)
+	DefaultMaxToolIterations = 25
+	// DefaultResponseCacheMaxSizeMB caps the on-disk response cache
+	DefaultResponseCacheMaxSizeMB = 100
+	// DefaultHistoryMaxConversations is how many saved conversations are
+	// kept (history.max_conversations overrides it)
+	DefaultHistoryMaxConversations = 200
 )
 
-// DefaultCopilotModels are the models available through GitHub Copilot
+// DefaultCopilotModels are the models available through GitHub Copilot,
+// used until the live list has been fetched from the Copilot /models endpoint
 // Updated: 2025-12-17
 var DefaultCopilotModels = []string{
 	// GPT models
@@ -43,3 +65,10 @@ var DefaultCopilotModels = []string{
 	"gemini-2.5-pro",
 	"gemini-3-pro-preview",
 }
+
+// DefaultAnthropicModels are the models available through the Anthropic API
+var DefaultAnthropicModels = []string{
+	"claude-sonnet-4-5",
+	"claude-opus-4-1",
+	"claude-haiku-4-5",
+}
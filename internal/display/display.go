@@ -129,6 +129,9 @@ func ShowUsage(usage map[string]int) {
 	fmt.Println("|------|-------|")
 	fmt.Printf("| Input | %d |\n", usage["input_tokens"])
 	fmt.Printf("| Output | %d |\n", usage["output_tokens"])
+	if n := usage["reasoning_tokens"]; n > 0 {
+		fmt.Printf("| Reasoning | %d |\n", n)
+	}
 	fmt.Printf("| **Total** | **%d** |\n", usage["total_tokens"])
 	fmt.Println()
 }
@@ -152,6 +155,28 @@ func ShowContentRendered(content string) {
 	fmt.Print(strings.TrimSuffix(rendered, "\n"))
 }
 
+// ShowReasoningChunk displays a streamed piece of the model's reasoning,
+// dimmed on stderr so it stays out of piped output
+func ShowReasoningChunk(chunk string) {
+	fmt.Fprintf(os.Stderr, "\033[2m%s\033[0m", chunk)
+}
+
+// EndReasoning ends streamed reasoning before the answer starts
+func EndReasoning() {
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr)
+}
+
+// ShowReasoning displays the model's complete reasoning, dimmed on stderr
+func ShowReasoning(reasoning string) {
+	reasoning = strings.TrimSpace(reasoning)
+	if reasoning == "" {
+		return
+	}
+	ShowReasoningChunk(reasoning)
+	EndReasoning()
+}
+
 // ShowError displays an error message
 func ShowError(message string) {
 	fmt.Fprintf(os.Stderr, "Error: %s\n", message)
@@ -163,6 +188,16 @@ func ShowKeyRotation(service string, fromIndex, toIndex, totalKeys int) {
 		service, fromIndex, totalKeys, toIndex, totalKeys)
 }
 
+// ShowFailover displays a message when the fallback chain switches backends
+func ShowFailover(from, to string, err error) {
+	fmt.Fprintf(os.Stderr, "Note: %s failed (%v), switching to %s\n", from, err, to)
+}
+
+// ShowBackend displays which backend answered a request
+func ShowBackend(name string) {
+	fmt.Fprintf(os.Stderr, "Answered by: %s\n", name)
+}
+
 // ShowWebSearching displays a message when web search starts
 func ShowWebSearching(query string) {
 	fmt.Fprintf(os.Stderr, "Searching web for: %s\n", query)
@@ -185,10 +220,86 @@ func ShowModels(models []string, currentModel string) {
 	}
 }
 
+// ModelDetails describes a model for ShowModelDetails.
+// Known is false when the provider didn't report capabilities.
+type ModelDetails struct {
+	ID            string
+	Model         string // Underlying model of a deployment, if different from ID
+	ContextWindow int
+	MaxOutput     int
+	ToolCalls     bool
+	Vision        bool
+	Known         bool
+}
+
+// ShowModelDetails displays available models with their capabilities
+func ShowModelDetails(models []ModelDetails, currentModel string) {
+	fmt.Println("Available models:")
+	fmt.Printf("    %-28s %-8s %-8s %-6s %s\n", "MODEL", "CONTEXT", "OUTPUT", "TOOLS", "VISION")
+	for _, m := range models {
+		marker := " "
+		if m.ID == currentModel {
+			marker = "*"
+		}
+		context, output, tools, vision := "?", "?", "?", "?"
+		if m.Known {
+			context, output = FormatTokens(m.ContextWindow), FormatTokens(m.MaxOutput)
+			tools, vision = yesNo(m.ToolCalls), yesNo(m.Vision)
+		}
+		name := m.ID
+		if m.Model != "" && m.Model != m.ID {
+			name = fmt.Sprintf("%s (%s)", m.ID, m.Model)
+		}
+		fmt.Printf("  %s %-28s %-8s %-8s %-6s %s\n", marker, name, context, output, tools, vision)
+	}
+}
+
+// FormatTokens formats a token count compactly (e.g. 128k); 0 is shown as "?"
+func FormatTokens(n int) string {
+	switch {
+	case n <= 0:
+		return "?"
+	case n >= 1000000 && n%1000000 == 0:
+		return fmt.Sprintf("%dM", n/1000000)
+	case n >= 1000:
+		return fmt.Sprintf("%dk", (n+500)/1000)
+	default:
+		return fmt.Sprintf("%d", n)
+	}
+}
+
+// ShowContextUsage displays how much of the model's context window the
+// conversation uses, e.g. "context: 62% used (79k/128k tokens)". Estimated
+// counts are marked with "~".
+func ShowContextUsage(used, window int, estimated bool) {
+	if window <= 0 {
+		return
+	}
+	approx := ""
+	if estimated {
+		approx = "~"
+	}
+	fmt.Fprintf(os.Stderr, "context: %s%d%% used (%s%s/%s tokens)\n", approx, used*100/window, approx, FormatTokens(used), FormatTokens(window))
+}
+
+// ShowContextSummarized displays a note when older messages were replaced
+// by a summary to fit the context window
+func ShowContextSummarized(count int) {
+	fmt.Fprintf(os.Stderr, "Note: summarized %d older messages to fit the context window\n", count)
+}
+
+// yesNo formats a capability flag
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
 // Citation represents a source citation
 type Citation struct {
-	Title string
-	URL   string
+	Title string `json:"title"`
+	URL   string `json:"url"`
 }
 
 // ShowCitations displays the source citations from web search
@@ -428,6 +539,15 @@ func ShowFileResult(success bool, output string) {
 	}
 }
 
+// ShowPolicyDecision displays an --auto-approve decision made in place of a confirmation prompt
+func ShowPolicyDecision(policy, op, target string, approved bool) {
+	if approved {
+		fmt.Fprintf(os.Stderr, "✅ Auto-approved %s (--auto-approve=%s): %s\n", op, policy, target)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "🚫 Denied %s (--auto-approve=%s): %s\n", op, policy, target)
+}
+
 // ShowWarning displays a warning message
 func ShowWarning(msg string) {
 	fmt.Fprintf(os.Stderr, "⚠️  %s\n", msg)
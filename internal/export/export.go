@@ -0,0 +1,409 @@
+// Package export renders saved conversations as Markdown, HTML or JSON for
+// sharing in pull requests and documents.
+package export
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/quocvuong92/ai-cli/internal/api"
+	"github.com/quocvuong92/ai-cli/internal/history"
+)
+
+// Export formats
+const (
+	FormatMarkdown = "md"
+	FormatHTML     = "html"
+	FormatJSON     = "json"
+)
+
+// Formats lists the export formats
+var Formats = []string{FormatMarkdown, FormatHTML, FormatJSON}
+
+// ErrInvalidFormat is returned for an unknown export format
+var ErrInvalidFormat = errors.New("invalid export format. Use 'md', 'html', or 'json'")
+
+// Options controls what an export includes
+type Options struct {
+	// Redact replaces secrets found in tool calls and tool results
+	Redact bool
+}
+
+// ParseFormat returns the export format named by s
+func ParseFormat(s string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "md", "markdown":
+		return FormatMarkdown, nil
+	case "html", "htm":
+		return FormatHTML, nil
+	case "json":
+		return FormatJSON, nil
+	}
+	return "", fmt.Errorf("%w: %q", ErrInvalidFormat, s)
+}
+
+// FormatForPath returns the export format matching the extension of path
+func FormatForPath(path string) (string, bool) {
+	ext := strings.TrimPrefix(filepath.Ext(path), ".")
+	if ext == "" {
+		return "", false
+	}
+	format, err := ParseFormat(ext)
+	return format, err == nil
+}
+
+// Write renders conv to w in format
+func Write(w io.Writer, conv history.ConversationEntry, format string, opts Options) error {
+	var data []byte
+	switch format {
+	case FormatMarkdown:
+		data = []byte(Markdown(conv, opts))
+	case FormatHTML:
+		out, err := HTML(conv, opts)
+		if err != nil {
+			return err
+		}
+		data = []byte(out)
+	case FormatJSON:
+		out, err := JSON(conv, opts)
+		if err != nil {
+			return err
+		}
+		data = out
+	default:
+		return fmt.Errorf("%w: %q", ErrInvalidFormat, format)
+	}
+	_, err := w.Write(data)
+	return err
+}
+
+// JSON returns conv as indented JSON, the format "ai-cli history import"
+// reads back
+func JSON(conv history.ConversationEntry, opts Options) ([]byte, error) {
+	if opts.Redact {
+		conv.Messages = redactMessages(conv.Messages)
+	}
+	data, err := json.MarshalIndent(conv, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal conversation: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// redactMessages returns a copy of messages with secrets removed from tool
+// call arguments and tool results
+func redactMessages(messages []api.Message) []api.Message {
+	out := make([]api.Message, len(messages))
+	for i, m := range messages {
+		if m.Role == "tool" {
+			m.Content = Redact(m.Content)
+		}
+		if len(m.ToolCalls) > 0 {
+			calls := make([]api.ToolCall, len(m.ToolCalls))
+			copy(calls, m.ToolCalls)
+			for j := range calls {
+				calls[j].Function.Arguments = Redact(calls[j].Function.Arguments)
+			}
+			m.ToolCalls = calls
+		}
+		out[i] = m
+	}
+	return out
+}
+
+// planToolName is the tool the model keeps its task checklist with; its
+// calls are rendered as checklists rather than tool sections
+const planToolName = "update_plan"
+
+// turn is one user or assistant message, ready to render
+type turn struct {
+	role    string // "user" or "assistant"
+	content string // Markdown, with citations linked
+	sources []history.Source
+	plans   []plan
+	tools   []toolSection
+}
+
+// plan is an update_plan call
+type plan struct {
+	title string
+	items []planItem
+}
+
+// planItem is one checklist entry of a plan
+type planItem struct {
+	description string
+	status      string // "pending", "in_progress", "completed"
+}
+
+// toolSection is a tool call and its result, shown collapsed
+type toolSection struct {
+	summary   string
+	arguments string
+	result    string
+	hasResult bool
+}
+
+// turns groups the messages of conv into turns. System messages are left
+// out; tool results are attached to the call they answer.
+func turns(conv history.ConversationEntry, opts Options) []turn {
+	messages := conv.Messages
+	if opts.Redact {
+		messages = redactMessages(messages)
+	}
+
+	results := make(map[string]string)
+	for _, m := range messages {
+		if m.Role == "tool" && m.ToolCallID != "" {
+			results[m.ToolCallID] = m.Content
+		}
+	}
+	answered := make(map[string]bool)
+
+	var out []turn
+	for i, m := range messages {
+		switch m.Role {
+		case "user":
+			out = append(out, turn{role: "user", content: m.Content})
+		case "assistant":
+			// Sources are keyed by the original reply
+			sources := conv.SourcesOf(conv.Messages[i])
+			t := turn{role: "assistant", content: linkCitations(m.Content, sources), sources: sources}
+			for _, tc := range m.ToolCalls {
+				if tc.Function.Name == planToolName {
+					if p, ok := parsePlan(tc.Function.Arguments); ok {
+						t.plans = append(t.plans, p)
+						answered[tc.ID] = true
+						continue
+					}
+				}
+				result, ok := results[tc.ID]
+				answered[tc.ID] = ok
+				t.tools = append(t.tools, toolSection{
+					summary:   toolSummary(tc),
+					arguments: prettyJSON(tc.Function.Arguments),
+					result:    result,
+					hasResult: ok,
+				})
+			}
+			if t.content != "" || len(t.plans) > 0 || len(t.tools) > 0 {
+				out = append(out, t)
+			}
+		case "tool":
+			// A result whose call is missing still gets a section of its own
+			if !answered[m.ToolCallID] {
+				out = append(out, turn{role: "assistant", tools: []toolSection{{
+					summary:   "Tool result",
+					result:    m.Content,
+					hasResult: true,
+				}}})
+			}
+		}
+	}
+	return out
+}
+
+// parsePlan reads the arguments of an update_plan call
+func parsePlan(arguments string) (plan, bool) {
+	var args struct {
+		Title string `json:"title"`
+		Items []struct {
+			Description string `json:"description"`
+			Status      string `json:"status"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+		return plan{}, false
+	}
+	p := plan{title: args.Title}
+	for _, item := range args.Items {
+		p.items = append(p.items, planItem{description: item.Description, status: item.Status})
+	}
+	return p, true
+}
+
+// summaryArgs are the tool arguments shown next to the tool name, in order
+// of preference
+var summaryArgs = []string{"command", "path", "pattern", "query"}
+
+// summaryLength limits the argument shown in a tool section summary
+const summaryLength = 80
+
+// toolSummary describes a tool call in one line: its name and main argument
+func toolSummary(tc api.ToolCall) string {
+	var args map[string]any
+	if json.Unmarshal([]byte(tc.Function.Arguments), &args) == nil {
+		for _, key := range summaryArgs {
+			if value, ok := args[key].(string); ok && value != "" {
+				line, _, _ := strings.Cut(value, "\n")
+				if runes := []rune(line); len(runes) > summaryLength {
+					line = string(runes[:summaryLength-3]) + "..."
+				}
+				return tc.Function.Name + ": " + line
+			}
+		}
+	}
+	return tc.Function.Name
+}
+
+// prettyJSON indents JSON arguments, leaving anything else as it is
+func prettyJSON(s string) string {
+	var v any
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		return s
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return s
+	}
+	return string(data)
+}
+
+// citationPattern matches citation markers such as [1]
+var citationPattern = regexp.MustCompile(`\[(\d+)\]`)
+
+// linkCitations turns the [n] markers of a reply into links to its sources.
+// Markers that are already part of a Markdown link are left alone.
+func linkCitations(content string, sources []history.Source) string {
+	if len(sources) == 0 {
+		return content
+	}
+	var b strings.Builder
+	last := 0
+	for _, m := range citationPattern.FindAllStringSubmatchIndex(content, -1) {
+		start, end := m[0], m[1]
+		n, _ := strconv.Atoi(content[m[2]:m[3]])
+		if n < 1 || n > len(sources) || sources[n-1].URL == "" ||
+			(start > 0 && strings.ContainsRune("[]", rune(content[start-1]))) ||
+			(end < len(content) && strings.ContainsRune("([:", rune(content[end]))) {
+			continue
+		}
+		b.WriteString(content[last:start])
+		fmt.Fprintf(&b, "[\\[%d\\]](%s)", n, sources[n-1].URL)
+		last = end
+	}
+	b.WriteString(content[last:])
+	return b.String()
+}
+
+// fence returns a code fence longer than any backtick run in content
+func fence(content string) string {
+	longest, run := 0, 0
+	for _, r := range content {
+		if r == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	return strings.Repeat("`", max(3, longest+1))
+}
+
+// Markdown renders conv as Markdown. Tool calls become collapsible
+// <details> sections, which GitHub and most Markdown viewers support.
+func Markdown(conv history.ConversationEntry, opts Options) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", conv.Title())
+	writeMetadata(&b, conv, func(label, value string) string {
+		return fmt.Sprintf("- **%s:** %s\n", label, value)
+	})
+	b.WriteString("\n")
+
+	role := ""
+	for _, t := range turns(conv, opts) {
+		// Consecutive assistant messages, such as tool calls and the reply
+		// that follows them, share one heading
+		if t.role != role {
+			role = t.role
+			b.WriteString("---\n\n")
+			b.WriteString("### " + roleHeading(role) + "\n\n")
+		}
+		if t.content != "" {
+			b.WriteString(strings.TrimSpace(t.content))
+			b.WriteString("\n\n")
+		}
+		for _, p := range t.plans {
+			b.WriteString(planMarkdown(p))
+			b.WriteString("\n")
+		}
+		for _, s := range t.tools {
+			fmt.Fprintf(&b, "<details>\n<summary>🔧 %s</summary>\n\n", html.EscapeString(s.summary))
+			if s.arguments != "" {
+				f := fence(s.arguments)
+				fmt.Fprintf(&b, "%sjson\n%s\n%s\n\n", f, s.arguments, f)
+			}
+			if s.hasResult {
+				result := strings.TrimRight(s.result, "\n")
+				f := fence(result)
+				fmt.Fprintf(&b, "Result:\n\n%s\n%s\n%s\n\n", f, result, f)
+			}
+			b.WriteString("</details>\n\n")
+		}
+		if len(t.sources) > 0 {
+			b.WriteString(sourcesMarkdown(t.sources))
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// roleHeading names the author of a turn
+func roleHeading(role string) string {
+	if role == "user" {
+		return "👤 User"
+	}
+	return "🤖 Assistant"
+}
+
+// writeMetadata writes the conversation details, formatting each with line
+func writeMetadata(b *strings.Builder, conv history.ConversationEntry, line func(label, value string) string) {
+	b.WriteString(line("Conversation", conv.ID))
+	model := conv.Model
+	if conv.Provider != "" {
+		model += " (" + conv.Provider + ")"
+	}
+	b.WriteString(line("Model", model))
+	b.WriteString(line("Created", conv.CreatedAt.Format("2006-01-02 15:04")))
+	b.WriteString(line("Updated", conv.UpdatedAt.Format("2006-01-02 15:04")))
+}
+
+// planMarkdown renders a plan as a task list
+func planMarkdown(p plan) string {
+	var b strings.Builder
+	if p.title != "" {
+		fmt.Fprintf(&b, "**📋 %s**\n\n", p.title)
+	}
+	for _, item := range p.items {
+		switch item.status {
+		case "completed":
+			fmt.Fprintf(&b, "- [x] %s\n", item.description)
+		case "in_progress":
+			fmt.Fprintf(&b, "- [ ] %s *(in progress)*\n", item.description)
+		default:
+			fmt.Fprintf(&b, "- [ ] %s\n", item.description)
+		}
+	}
+	return b.String()
+}
+
+// sourcesMarkdown renders the sources a reply cites as a numbered list
+func sourcesMarkdown(sources []history.Source) string {
+	var b strings.Builder
+	b.WriteString("**Sources**\n\n")
+	for i, s := range sources {
+		title := s.Title
+		if title == "" {
+			title = s.URL
+		}
+		fmt.Fprintf(&b, "%d. [%s](%s)\n", i+1, strings.ReplaceAll(title, "]", "\\]"), s.URL)
+	}
+	return b.String()
+}
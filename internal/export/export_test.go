@@ -0,0 +1,162 @@
+package export
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/quocvuong92/ai-cli/internal/api"
+	"github.com/quocvuong92/ai-cli/internal/history"
+)
+
+func toolCall(id, name, arguments string) api.ToolCall {
+	tc := api.ToolCall{ID: id, Type: "function"}
+	tc.Function.Name = name
+	tc.Function.Arguments = arguments
+	return tc
+}
+
+func testConversation() history.ConversationEntry {
+	reply := "Go 1.24 added generic type aliases [1]."
+	return history.ConversationEntry{
+		ID:        "3f9a1c2e-0000",
+		Model:     "gpt-4.1",
+		Provider:  "GitHub Copilot",
+		CreatedAt: time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC),
+		UpdatedAt: time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC),
+		Messages: []api.Message{
+			{Role: "system", Content: "Be precise."},
+			{Role: "user", Content: "Check the deploy config"},
+			{Role: "assistant", ToolCalls: []api.ToolCall{
+				toolCall("call_1", "update_plan", `{"title":"Deploy check","items":[{"description":"Read config","status":"completed"},{"description":"Fix it","status":"pending"}]}`),
+				toolCall("call_2", "execute_command", `{"command":"cat deploy.env"}`),
+			}},
+			{Role: "tool", ToolCallID: "call_1", Content: "Plan updated: Deploy check (2 items)"},
+			{Role: "tool", ToolCallID: "call_2", Content: "API_TOKEN=abcd1234efgh\nREGION=eu-west-1"},
+			{Role: "assistant", Content: reply},
+		},
+		Sources: map[string][]history.Source{
+			history.ReplyKey(reply): {{Title: "Go 1.24 Release Notes", URL: "https://go.dev/doc/go1.24"}},
+		},
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	out := Markdown(testConversation(), Options{})
+
+	for _, want := range []string{
+		"# Check the deploy config",
+		"- **Model:** gpt-4.1 (GitHub Copilot)",
+		"### 👤 User",
+		"- [x] Read config",
+		"- [ ] Fix it",
+		"<summary>🔧 execute_command: cat deploy.env</summary>",
+		"API_TOKEN=abcd1234efgh",
+		"aliases [\\[1\\]](https://go.dev/doc/go1.24).",
+		"1. [Go 1.24 Release Notes](https://go.dev/doc/go1.24)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Markdown() is missing %q:\n%s", want, out)
+		}
+	}
+	for _, unwanted := range []string{"Be precise.", "Plan updated"} {
+		if strings.Contains(out, unwanted) {
+			t.Errorf("Markdown() should not contain %q", unwanted)
+		}
+	}
+
+	redacted := Markdown(testConversation(), Options{Redact: true})
+	if strings.Contains(redacted, "abcd1234efgh") || !strings.Contains(redacted, "API_TOKEN="+Redacted) {
+		t.Errorf("Markdown() with Redact kept the secret:\n%s", redacted)
+	}
+	if !strings.Contains(redacted, "[\\[1\\]](https://go.dev/doc/go1.24)") {
+		t.Error("Markdown() with Redact lost the citation links")
+	}
+}
+
+func TestHTML(t *testing.T) {
+	conv := testConversation()
+	conv.Messages = append(conv.Messages, api.Message{Role: "user", Content: "**bold** and <script>alert(1)</script>"})
+
+	out, err := HTML(conv, Options{})
+	if err != nil {
+		t.Fatalf("HTML() error = %v", err)
+	}
+	for _, want := range []string{
+		"<title>Check the deploy config</title>",
+		"<summary>🔧 execute_command: cat deploy.env</summary>",
+		`<input checked="" disabled="" type="checkbox"`,
+		`<a href="https://go.dev/doc/go1.24">`,
+		"<strong>bold</strong>",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("HTML() is missing %q", want)
+		}
+	}
+	if strings.Contains(out, "<script>") {
+		t.Error("HTML() passed raw HTML from a message through")
+	}
+}
+
+func TestJSON(t *testing.T) {
+	data, err := JSON(testConversation(), Options{Redact: true})
+	if err != nil {
+		t.Fatalf("JSON() error = %v", err)
+	}
+	var conv history.ConversationEntry
+	if err := json.Unmarshal(data, &conv); err != nil {
+		t.Fatalf("JSON() output does not parse: %v", err)
+	}
+	if conv.ID != "3f9a1c2e-0000" || len(conv.Messages) != 6 || len(conv.Sources) != 1 {
+		t.Errorf("JSON() round trip = %+v", conv)
+	}
+	if strings.Contains(conv.Messages[4].Content, "abcd1234efgh") {
+		t.Error("JSON() with Redact kept the secret")
+	}
+	if original := testConversation(); original.Messages[4].Content != "API_TOKEN=abcd1234efgh\nREGION=eu-west-1" {
+		t.Error("JSON() changed the original messages")
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	if f, err := ParseFormat("Markdown"); err != nil || f != FormatMarkdown {
+		t.Errorf("ParseFormat(Markdown) = %q, %v", f, err)
+	}
+	if _, err := ParseFormat("pdf"); !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("ParseFormat(pdf) error = %v, want ErrInvalidFormat", err)
+	}
+	if f, ok := FormatForPath("out/session.HTML"); !ok || f != FormatHTML {
+		t.Errorf("FormatForPath() = %q, %v", f, ok)
+	}
+	if _, ok := FormatForPath("session"); ok {
+		t.Error("FormatForPath() without an extension should not match")
+	}
+}
+
+func TestLinkCitations(t *testing.T) {
+	sources := []history.Source{{Title: "A", URL: "https://a.example"}}
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{"See [1].", "See [\\[1\\]](https://a.example)."},
+		{"See [2].", "See [2]."},                                       // No such source
+		{"See [1](https://b.example).", "See [1](https://b.example)."}, // Already a link
+	}
+	for _, tt := range tests {
+		if got := linkCitations(tt.content, sources); got != tt.want {
+			t.Errorf("linkCitations(%q) = %q, want %q", tt.content, got, tt.want)
+		}
+	}
+}
+
+func TestFence(t *testing.T) {
+	if got := fence("plain"); got != "```" {
+		t.Errorf("fence() = %q", got)
+	}
+	if got := fence("has ```go\ncode\n```"); got != "````" {
+		t.Errorf("fence() around a fence = %q", got)
+	}
+}
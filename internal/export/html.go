@@ -0,0 +1,97 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+
+	"github.com/quocvuong92/ai-cli/internal/history"
+)
+
+// markdown converts message content to HTML. Raw HTML in the content is
+// left out rather than passed through.
+var markdown = goldmark.New(goldmark.WithExtensions(extension.GFM))
+
+// htmlStyle keeps exported pages readable without external resources
+const htmlStyle = `body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; max-width: 860px; margin: 2em auto; padding: 0 1em; line-height: 1.5; color: #1f2328; }
+.meta { color: #59636e; font-size: 0.9em; }
+.turn { border-top: 1px solid #d1d9e0; padding: 0.5em 0; }
+.role { font-weight: 600; margin: 0.5em 0; }
+pre { background: #f6f8fa; padding: 0.75em; overflow-x: auto; border-radius: 6px; }
+code { font-family: ui-monospace, SFMono-Regular, Menlo, monospace; font-size: 0.9em; }
+details { border: 1px solid #d1d9e0; border-radius: 6px; padding: 0.25em 0.75em; margin: 0.5em 0; }
+summary { cursor: pointer; }
+li input[type=checkbox] { margin-right: 0.4em; }
+`
+
+// HTML renders conv as a self-contained HTML page. Tool calls become
+// collapsible <details> sections.
+func HTML(conv history.ConversationEntry, opts Options) (string, error) {
+	var b strings.Builder
+	title := html.EscapeString(conv.Title())
+	fmt.Fprintf(&b, "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>%s</title>\n<style>\n%s</style>\n</head>\n<body>\n", title, htmlStyle)
+	fmt.Fprintf(&b, "<h1>%s</h1>\n<ul class=\"meta\">\n", title)
+	writeMetadata(&b, conv, func(label, value string) string {
+		return fmt.Sprintf("<li><strong>%s:</strong> %s</li>\n", label, html.EscapeString(value))
+	})
+	b.WriteString("</ul>\n")
+
+	role := ""
+	for _, t := range turns(conv, opts) {
+		if t.role != role {
+			if role != "" {
+				b.WriteString("</div>\n")
+			}
+			role = t.role
+			fmt.Fprintf(&b, "<div class=\"turn\">\n<div class=\"role\">%s</div>\n", roleHeading(role))
+		}
+
+		// Content, plans and sources are Markdown; tool sections are built
+		// directly so their output is shown verbatim
+		var md strings.Builder
+		md.WriteString(t.content)
+		for _, p := range t.plans {
+			md.WriteString("\n\n")
+			md.WriteString(planMarkdown(p))
+		}
+		if md.Len() > 0 {
+			if err := renderMarkdown(&b, md.String()); err != nil {
+				return "", err
+			}
+		}
+		for _, s := range t.tools {
+			fmt.Fprintf(&b, "<details>\n<summary>🔧 %s</summary>\n", html.EscapeString(s.summary))
+			if s.arguments != "" {
+				fmt.Fprintf(&b, "<pre><code>%s</code></pre>\n", html.EscapeString(s.arguments))
+			}
+			if s.hasResult {
+				fmt.Fprintf(&b, "<p>Result:</p>\n<pre><code>%s</code></pre>\n", html.EscapeString(strings.TrimRight(s.result, "\n")))
+			}
+			b.WriteString("</details>\n")
+		}
+		if len(t.sources) > 0 {
+			if err := renderMarkdown(&b, sourcesMarkdown(t.sources)); err != nil {
+				return "", err
+			}
+		}
+	}
+	if role != "" {
+		b.WriteString("</div>\n")
+	}
+	b.WriteString("</body>\n</html>\n")
+	return b.String(), nil
+}
+
+// renderMarkdown converts source to HTML and appends it to b
+func renderMarkdown(b *strings.Builder, source string) error {
+	var buf bytes.Buffer
+	if err := markdown.Convert([]byte(source), &buf); err != nil {
+		return fmt.Errorf("failed to render markdown: %w", err)
+	}
+	b.Write(buf.Bytes())
+	return nil
+}
@@ -0,0 +1,30 @@
+package export
+
+import "regexp"
+
+// Redacted replaces each secret removed by Redact
+const Redacted = "[REDACTED]"
+
+// secretPatterns match credentials commonly found in command output and
+// files. A pattern's first and second groups, if any, are kept before and
+// after Redacted.
+var secretPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`),
+	regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`),                                 // AWS access key IDs
+	regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})`), // GitHub tokens
+	regexp.MustCompile(`\bsk-[A-Za-z0-9_-]{20,}`),                                       // OpenAI and Anthropic keys
+	regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`),                                // Slack tokens
+	regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}`),                                       // Google API keys
+	regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]+`),     // JWTs
+	regexp.MustCompile(`(?i)(\bbearer\s+)[A-Za-z0-9._~+/-]{16,}=*`),
+	regexp.MustCompile(`(://[^/\s:@]+:)[^/\s@]+(@)`), // Passwords in URLs
+	regexp.MustCompile(`(?i)(\b[A-Za-z0-9_.-]*(?:password|passwd|pwd|secret|token|(?:api|access|auth|private|secret)[_-]?key|credentials?)["']?\s*[:=]\s*["']?)[^\s"',;]{4,}`),
+}
+
+// Redact replaces the secrets found in text with Redacted
+func Redact(text string) string {
+	for _, re := range secretPatterns {
+		text = re.ReplaceAllString(text, "${1}"+Redacted+"${2}")
+	}
+	return text
+}
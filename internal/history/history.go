@@ -2,22 +2,20 @@
 package history
 
 import (
-	"encoding/json"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
+	"unicode"
 
 	"github.com/quocvuong92/ai-cli/internal/api"
 )
 
-const (
-	// HistoryFileName is the name of the history file
-	HistoryFileName = "conversation-history.json"
-	// MaxHistoryEntries limits the number of conversations stored
-	MaxHistoryEntries = 50
-)
-
 // ConversationEntry represents a saved conversation
 type ConversationEntry struct {
 	ID        string        `json:"id"`
@@ -26,80 +24,65 @@ type ConversationEntry struct {
 	Messages  []api.Message `json:"messages"`
 	CreatedAt time.Time     `json:"created_at"`
 	UpdatedAt time.Time     `json:"updated_at"`
+	// Sources are the web search results replies cite as [1], [2], ...,
+	// keyed by the ReplyKey of the reply
+	Sources map[string][]Source `json:"sources,omitempty"`
 }
 
-// History manages conversation history persistence
-type History struct {
-	Conversations []ConversationEntry `json:"conversations"`
-	path          string
+// Source is a web search result cited by a reply
+type Source struct {
+	Title string `json:"title"`
+	URL   string `json:"url"`
 }
 
-// NewHistory creates a new History manager
-func NewHistory() *History {
-	return &History{
-		Conversations: make([]ConversationEntry, 0),
-		path:          getHistoryPath(),
-	}
+// ReplyKey identifies a reply by its content, so its sources stay attached
+// when earlier messages are compacted or removed
+func ReplyKey(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:8])
 }
 
-// getHistoryPath returns the path to the history file
-func getHistoryPath() string {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return ""
+// SourcesOf returns the sources cited by the assistant message msg
+func (e ConversationEntry) SourcesOf(msg api.Message) []Source {
+	if len(e.Sources) == 0 || msg.Role != "assistant" {
+		return nil
 	}
-	return filepath.Join(homeDir, ".local", "share", "ai-cli", HistoryFileName)
+	return e.Sources[ReplyKey(msg.Content)]
 }
 
-// Load reads the history from disk
-func (h *History) Load() error {
-	if h.path == "" {
-		return fmt.Errorf("history path not available")
-	}
-
-	data, err := os.ReadFile(h.path)
-	if err != nil {
-		if os.IsNotExist(err) {
-			// No history file yet, start fresh
-			return nil
-		}
-		return fmt.Errorf("failed to read history: %w", err)
-	}
-
-	if err := json.Unmarshal(data, h); err != nil {
-		return fmt.Errorf("failed to parse history: %w", err)
-	}
+// History manages conversation history persistence. Conversations are
+// ordered oldest first by UpdatedAt; each is stored in its own file (see
+// store.go), so sessions running side by side only write their own.
+type History struct {
+	Conversations []ConversationEntry
 
-	return nil
+	dir       string
+	retention Retention
+	dirty     map[string]bool // Conversations changed since the last Save
+	deleted   map[string]bool // Conversations deleted since the last Save
+	now       func() time.Time
 }
 
-// Save writes the history to disk
-func (h *History) Save() error {
-	if h.path == "" {
-		return fmt.Errorf("history path not available")
-	}
-
-	// Ensure directory exists
-	dir := filepath.Dir(h.path)
-	if err := os.MkdirAll(dir, 0700); err != nil {
-		return fmt.Errorf("failed to create history directory: %w", err)
-	}
-
-	// Trim to max entries
-	if len(h.Conversations) > MaxHistoryEntries {
-		h.Conversations = h.Conversations[len(h.Conversations)-MaxHistoryEntries:]
-	}
-
-	data, err := json.MarshalIndent(h, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal history: %w", err)
+// NewHistory creates a History stored in the user data directory
+// (~/.local/share/ai-cli/conversations)
+func NewHistory() *History {
+	dir := ""
+	if homeDir, err := os.UserHomeDir(); err == nil {
+		dir = filepath.Join(homeDir, ".local", "share", "ai-cli", ConversationsDirName)
 	}
+	return NewHistoryAt(dir)
+}
 
-	if err := os.WriteFile(h.path, data, 0600); err != nil {
-		return fmt.Errorf("failed to write history: %w", err)
+// NewHistoryAt creates a History stored in dir
+func NewHistoryAt(dir string) *History {
+	return &History{
+		Conversations: make([]ConversationEntry, 0),
+		dir:           dir,
+		retention:     Retention{MaxCount: DefaultMaxConversations},
+		dirty:         make(map[string]bool),
+		deleted:       make(map[string]bool),
+		now:           time.Now,
 	}
-
-	return nil
 }
 
 // AddConversation adds a new conversation to history
@@ -109,24 +92,43 @@ func (h *History) AddConversation(id, model, provider string, messages []api.Mes
 		Model:     model,
 		Provider:  provider,
 		Messages:  messages,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		CreatedAt: h.now(),
+		UpdatedAt: h.now(),
 	}
 	h.Conversations = append(h.Conversations, entry)
+	h.dirty[id] = true
+	delete(h.deleted, id)
 }
 
-// UpdateConversation updates an existing conversation
+// UpdateConversation updates an existing conversation, which becomes the
+// most recent
 func (h *History) UpdateConversation(id string, messages []api.Message) bool {
 	for i := range h.Conversations {
 		if h.Conversations[i].ID == id {
-			h.Conversations[i].Messages = messages
-			h.Conversations[i].UpdatedAt = time.Now()
+			entry := h.Conversations[i]
+			entry.Messages = messages
+			entry.UpdatedAt = h.now()
+			h.Conversations = append(append(h.Conversations[:i], h.Conversations[i+1:]...), entry)
+			h.dirty[id] = true
 			return true
 		}
 	}
 	return false
 }
 
+// UpsertConversation saves messages as conversation id, updating its entry
+// (which keeps its CreatedAt) if there is one and adding it otherwise. The
+// messages are copied, so the caller can keep appending to its slice.
+func (h *History) UpsertConversation(id, model, provider string, messages []api.Message) {
+	messages = append([]api.Message(nil), messages...)
+	if conv := h.GetConversation(id); conv != nil {
+		conv.Model, conv.Provider = model, provider
+		h.UpdateConversation(id, messages)
+		return
+	}
+	h.AddConversation(id, model, provider, messages)
+}
+
 // GetConversation retrieves a conversation by ID
 func (h *History) GetConversation(id string) *ConversationEntry {
 	for i := range h.Conversations {
@@ -147,7 +149,11 @@ func (h *History) GetLastConversation() *ConversationEntry {
 
 // Clear removes all conversation history
 func (h *History) Clear() {
+	for _, conv := range h.Conversations {
+		h.deleted[conv.ID] = true
+	}
 	h.Conversations = make([]ConversationEntry, 0)
+	h.dirty = make(map[string]bool)
 }
 
 // GetRecentConversations returns the N most recent conversations
@@ -160,3 +166,180 @@ func (h *History) GetRecentConversations(n int) []ConversationEntry {
 	}
 	return h.Conversations[len(h.Conversations)-n:]
 }
+
+// ErrConversationNotFound is returned when a reference matches no conversation
+var ErrConversationNotFound = errors.New("conversation not found")
+
+// titleLength limits the length of a conversation title
+const titleLength = 60
+
+// Title returns the first line of the first user message, shortened
+func (e ConversationEntry) Title() string {
+	for _, m := range e.Messages {
+		if m.Role != "user" {
+			continue
+		}
+		line, _, _ := strings.Cut(strings.TrimSpace(m.Content), "\n")
+		if runes := []rune(line); len(runes) > titleLength {
+			line = string(runes[:titleLength-3]) + "..."
+		}
+		if line != "" {
+			return line
+		}
+	}
+	return "(no messages)"
+}
+
+// MessageCount returns the number of messages, excluding system messages
+func (e ConversationEntry) MessageCount() int {
+	n := 0
+	for _, m := range e.Messages {
+		if m.Role != "system" {
+			n++
+		}
+	}
+	return n
+}
+
+// Newest returns up to n conversations, most recent first; n <= 0 returns all
+func (h *History) Newest(n int) []ConversationEntry {
+	if n <= 0 || n > len(h.Conversations) {
+		n = len(h.Conversations)
+	}
+	newest := make([]ConversationEntry, n)
+	for i := range newest {
+		newest[i] = h.Conversations[len(h.Conversations)-1-i]
+	}
+	return newest
+}
+
+// Resolve finds a conversation by ID, by a unique ID prefix, or by index
+// (1 is the most recent, as listed by Newest). IDs are tried first, since an
+// all-digit ref can be an ID prefix too; "#n" is always an index.
+func (h *History) Resolve(ref string) (*ConversationEntry, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return nil, ErrConversationNotFound
+	}
+	if rest, ok := strings.CutPrefix(ref, "#"); ok {
+		n, err := strconv.Atoi(rest)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrConversationNotFound, ref)
+		}
+		return h.byIndex(n)
+	}
+	if conv := h.GetConversation(ref); conv != nil {
+		return conv, nil
+	}
+
+	_, numErr := strconv.Atoi(ref)
+	var match *ConversationEntry
+	for i := range h.Conversations {
+		if strings.HasPrefix(h.Conversations[i].ID, ref) {
+			if match != nil {
+				if numErr == nil {
+					return nil, fmt.Errorf("%q matches more than one conversation; use more of the ID, or #%s for conversation #%s", ref, ref, ref)
+				}
+				return nil, fmt.Errorf("%q matches more than one conversation; use more of the ID", ref)
+			}
+			match = &h.Conversations[i]
+		}
+	}
+	if match != nil {
+		return match, nil
+	}
+	if n, err := strconv.Atoi(ref); err == nil {
+		return h.byIndex(n)
+	}
+	return nil, fmt.Errorf("%w: %s", ErrConversationNotFound, ref)
+}
+
+// byIndex returns conversation n, 1 being the most recent
+func (h *History) byIndex(n int) (*ConversationEntry, error) {
+	if n < 1 || n > len(h.Conversations) {
+		return nil, fmt.Errorf("%w: no conversation #%d (%d saved)", ErrConversationNotFound, n, len(h.Conversations))
+	}
+	return &h.Conversations[len(h.Conversations)-n], nil
+}
+
+// DeleteConversation removes the conversation with id, reporting whether it existed
+func (h *History) DeleteConversation(id string) bool {
+	for i := range h.Conversations {
+		if h.Conversations[i].ID == id {
+			h.Conversations = append(h.Conversations[:i], h.Conversations[i+1:]...)
+			delete(h.dirty, id)
+			h.deleted[id] = true
+			return true
+		}
+	}
+	return false
+}
+
+// SearchResult is a conversation matching a search, with excerpts of the
+// matching messages
+type SearchResult struct {
+	Index    int // Position in Newest, starting at 1
+	Entry    ConversationEntry
+	Excerpts []string
+}
+
+// excerptRadius is how much text is kept on each side of a search match
+const excerptRadius = 40
+
+// Search returns the conversations, most recent first, whose messages
+// contain text (case-insensitive)
+func (h *History) Search(text string) []SearchResult {
+	query := strings.Map(unicode.ToLower, strings.Join(strings.Fields(text), " "))
+	if query == "" {
+		return nil
+	}
+
+	var results []SearchResult
+	for i, conv := range h.Newest(0) {
+		var excerpts []string
+		for _, m := range conv.Messages {
+			if m.Role == "system" {
+				continue
+			}
+			if excerpt, ok := excerptOf(m.Content, query); ok {
+				excerpts = append(excerpts, m.Role+": "+excerpt)
+			}
+		}
+		if len(excerpts) > 0 {
+			results = append(results, SearchResult{Index: i + 1, Entry: conv, Excerpts: excerpts})
+		}
+	}
+	return results
+}
+
+// excerptOf returns the text around the first occurrence of query (already
+// lowercased) in content, on one line
+func excerptOf(content, query string) (string, bool) {
+	runes := []rune(strings.Join(strings.Fields(content), " "))
+	// Lowercase rune by rune so positions in lower match runes
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+	q := []rune(query)
+	at := -1
+	for i := 0; i+len(q) <= len(lower); i++ {
+		if string(lower[i:i+len(q)]) == query {
+			at = i
+			break
+		}
+	}
+	if at < 0 {
+		return "", false
+	}
+
+	start, end := max(0, at-excerptRadius), min(len(runes), at+len(q)+excerptRadius)
+	excerpt := string(runes[start:end])
+	if start > 0 {
+		excerpt = "..." + excerpt
+	}
+	if end < len(runes) {
+		excerpt += "..."
+	}
+	return excerpt, true
+}
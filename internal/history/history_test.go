@@ -0,0 +1,180 @@
+package history
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/quocvuong92/ai-cli/internal/api"
+)
+
+func testHistory() *History {
+	h := NewHistoryAt("")
+	h.AddConversation("aaaa1111-0000", "gpt-4.1", "copilot", []api.Message{
+		{Role: "system", Content: "Be precise."},
+		{Role: "user", Content: "How do I tune the connection pool?\nDetails follow."},
+		{Role: "assistant", Content: "Set MaxOpenConns and MaxIdleConns."},
+	})
+	h.AddConversation("aaaa2222-0000", "gpt-4.1", "copilot", []api.Message{
+		{Role: "user", Content: "Write a haiku about autumn"},
+	})
+	h.AddConversation("bbbb3333-0000", "claude", "anthropic", []api.Message{
+		{Role: "system", Content: "Be precise."},
+	})
+	return h
+}
+
+func TestConversationEntry_Title(t *testing.T) {
+	h := testHistory()
+	if got := h.Conversations[0].Title(); got != "How do I tune the connection pool?" {
+		t.Errorf("Title() = %q", got)
+	}
+	if got := h.Conversations[2].Title(); got != "(no messages)" {
+		t.Errorf("Title() without user messages = %q", got)
+	}
+	long := ConversationEntry{Messages: []api.Message{{Role: "user", Content: strings.Repeat("x", 100)}}}
+	if got := long.Title(); len([]rune(got)) != titleLength || !strings.HasSuffix(got, "...") {
+		t.Errorf("Title() of a long message = %q", got)
+	}
+	if got := h.Conversations[0].MessageCount(); got != 2 {
+		t.Errorf("MessageCount() = %d, want 2", got)
+	}
+}
+
+func TestHistory_Resolve(t *testing.T) {
+	h := testHistory()
+
+	tests := []struct {
+		ref     string
+		want    string
+		wantErr bool
+	}{
+		{"1", "bbbb3333-0000", false},
+		{"3", "aaaa1111-0000", false},
+		{"aaaa2222-0000", "aaaa2222-0000", false},
+		{"bbbb", "bbbb3333-0000", false},
+		{"aaaa", "", true}, // Ambiguous prefix
+		{"4", "", true},
+		{"0", "", true},
+		{"zzzz", "", true},
+		{"", "", true},
+	}
+	for _, tt := range tests {
+		conv, err := h.Resolve(tt.ref)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("Resolve(%q) = %s, want an error", tt.ref, conv.ID)
+			}
+			continue
+		}
+		if err != nil || conv.ID != tt.want {
+			t.Errorf("Resolve(%q) = %v, %v; want %s", tt.ref, conv, err, tt.want)
+		}
+	}
+
+	if _, err := h.Resolve("zzzz"); !errors.Is(err, ErrConversationNotFound) {
+		t.Errorf("Resolve() error = %v, want ErrConversationNotFound", err)
+	}
+}
+
+func TestHistory_Resolve_NumericIDPrefix(t *testing.T) {
+	h := NewHistoryAt("")
+	h.AddConversation("12345678-0000", "gpt-4.1", "copilot", userMessage("numeric"))
+	h.AddConversation("12399999-0000", "gpt-4.1", "copilot", userMessage("also numeric"))
+	for i := 0; i < 123; i++ {
+		h.AddConversation(fmt.Sprintf("ffff%04d", i), "gpt-4.1", "copilot", userMessage("filler"))
+	}
+
+	tests := []struct {
+		ref     string
+		want    string
+		wantErr bool
+	}{
+		{"12345", "12345678-0000", false}, // An ID prefix, not conversation #12345
+		{"123", "", true},                 // Ambiguous prefix, never conversation #123
+		{"#124", "12399999-0000", false},  // Explicit index
+		{"2", "ffff0121", false},          // No ID starts with 2, so an index
+		{"#0", "", true},
+		{"#x", "", true},
+	}
+	for _, tt := range tests {
+		conv, err := h.Resolve(tt.ref)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("Resolve(%q) = %s, want an error", tt.ref, conv.ID)
+			}
+			continue
+		}
+		if err != nil || conv.ID != tt.want {
+			t.Errorf("Resolve(%q) = %v, %v; want %s", tt.ref, conv, err, tt.want)
+		}
+	}
+}
+
+func TestHistory_Search(t *testing.T) {
+	h := testHistory()
+
+	results := h.Search("MAXIDLECONNS")
+	if len(results) != 1 || results[0].Entry.ID != "aaaa1111-0000" || results[0].Index != 3 {
+		t.Fatalf("Search() = %+v", results)
+	}
+	if len(results[0].Excerpts) != 1 || !strings.HasPrefix(results[0].Excerpts[0], "assistant: ") {
+		t.Errorf("Search() excerpts = %q", results[0].Excerpts)
+	}
+
+	if results := h.Search("precise"); len(results) != 0 {
+		t.Errorf("Search() should skip system messages, got %+v", results)
+	}
+	if results := h.Search("  "); results != nil {
+		t.Errorf("Search() of blank text = %+v", results)
+	}
+}
+
+func TestHistory_DeleteConversation(t *testing.T) {
+	h := testHistory()
+	if !h.DeleteConversation("aaaa2222-0000") || len(h.Conversations) != 2 {
+		t.Fatalf("DeleteConversation() left %d conversations", len(h.Conversations))
+	}
+	if h.GetConversation("aaaa2222-0000") != nil {
+		t.Error("deleted conversation is still found")
+	}
+	if h.DeleteConversation("missing") {
+		t.Error("DeleteConversation() of a missing ID should report false")
+	}
+}
+
+func TestHistory_UpsertConversation(t *testing.T) {
+	h := testHistory()
+	created := h.GetConversation("aaaa1111-0000").CreatedAt
+	messages := []api.Message{{Role: "user", Content: "How do I tune the connection pool?"}}
+
+	h.UpsertConversation("aaaa1111-0000", "claude", "anthropic", messages)
+	messages[0].Content = "changed by the caller"
+	if len(h.Conversations) != 3 {
+		t.Fatalf("UpsertConversation() of an existing ID left %d conversations", len(h.Conversations))
+	}
+	conv := h.Conversations[2]
+	if conv.ID != "aaaa1111-0000" || conv.Model != "claude" || !conv.CreatedAt.Equal(created) {
+		t.Errorf("updated conversation = %+v, want it newest with CreatedAt kept", conv)
+	}
+	if conv.Messages[0].Content != "How do I tune the connection pool?" {
+		t.Error("UpsertConversation() should copy the messages")
+	}
+
+	h.UpsertConversation("cccc4444-0000", "gpt-4.1", "copilot", messages)
+	if len(h.Conversations) != 4 {
+		t.Errorf("UpsertConversation() of a new ID left %d conversations", len(h.Conversations))
+	}
+}
+
+func TestExcerptOf(t *testing.T) {
+	content := strings.Repeat("a ", 50) + "needle" + strings.Repeat(" b", 50)
+	excerpt, ok := excerptOf(content, "needle")
+	if !ok || !strings.HasPrefix(excerpt, "...") || !strings.HasSuffix(excerpt, "...") || !strings.Contains(excerpt, "needle") {
+		t.Errorf("excerptOf() = %q, %v", excerpt, ok)
+	}
+	if _, ok := excerptOf("nothing here", "needle"); ok {
+		t.Error("excerptOf() should not match")
+	}
+}
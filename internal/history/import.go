@@ -0,0 +1,134 @@
+package history
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/quocvuong92/ai-cli/internal/api"
+)
+
+// ErrInvalidImport is returned for a file that is not a conversation
+var ErrInvalidImport = errors.New("not a conversation. Expected an ai-cli JSON export, an object with a \"messages\" array, or a messages array")
+
+// importedMessage is a message in an imported file. Content may be a string
+// or, as OpenAI allows, an array of content parts.
+type importedMessage struct {
+	Role       string          `json:"role"`
+	Content    json.RawMessage `json:"content"`
+	ToolCalls  []api.ToolCall  `json:"tool_calls,omitempty"`
+	ToolCallID string          `json:"tool_call_id,omitempty"`
+}
+
+// importedConversation is an ai-cli export or an OpenAI-style request body
+type importedConversation struct {
+	ID        string              `json:"id"`
+	Model     string              `json:"model"`
+	Provider  string              `json:"provider"`
+	Messages  []importedMessage   `json:"messages"`
+	CreatedAt time.Time           `json:"created_at"`
+	UpdatedAt time.Time           `json:"updated_at"`
+	Sources   map[string][]Source `json:"sources,omitempty"`
+}
+
+// ParseConversation reads a conversation from JSON: an ai-cli export, an
+// object with a "messages" array such as a Chat Completions request body,
+// or a bare messages array. Fields the file doesn't have are left empty.
+func ParseConversation(data []byte) (ConversationEntry, error) {
+	var in importedConversation
+	data = bytes.TrimSpace(data)
+	if bytes.HasPrefix(data, []byte("[")) {
+		if err := json.Unmarshal(data, &in.Messages); err != nil {
+			return ConversationEntry{}, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+		}
+	} else if err := json.Unmarshal(data, &in); err != nil {
+		return ConversationEntry{}, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+	}
+
+	conv := ConversationEntry{
+		ID:        in.ID,
+		Model:     in.Model,
+		Provider:  in.Provider,
+		CreatedAt: in.CreatedAt,
+		UpdatedAt: in.UpdatedAt,
+		Sources:   in.Sources,
+	}
+	hasUser := false
+	for i, m := range in.Messages {
+		content, err := importedContent(m.Content)
+		if err != nil {
+			return ConversationEntry{}, fmt.Errorf("%w: message %d: %v", ErrInvalidImport, i+1, err)
+		}
+		role := m.Role
+		switch role {
+		case "developer":
+			role = "system"
+		case "system", "assistant", "tool":
+		case "user":
+			hasUser = true
+		default:
+			return ConversationEntry{}, fmt.Errorf("%w: message %d has role %q", ErrInvalidImport, i+1, m.Role)
+		}
+		conv.Messages = append(conv.Messages, api.Message{
+			Role:       role,
+			Content:    content,
+			ToolCalls:  m.ToolCalls,
+			ToolCallID: m.ToolCallID,
+		})
+	}
+	if !hasUser {
+		return ConversationEntry{}, fmt.Errorf("%w: no user messages", ErrInvalidImport)
+	}
+	return conv, nil
+}
+
+// importedContent returns the text of a message's content: a string, or the
+// text parts of a content array. Other parts, such as images, are dropped.
+func importedContent(raw json.RawMessage) (string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return "", nil
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s, nil
+	}
+	var parts []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(raw, &parts); err != nil {
+		return "", fmt.Errorf("content is neither a string nor an array of parts")
+	}
+	var texts []string
+	for _, p := range parts {
+		if p.Type == "text" && p.Text != "" {
+			texts = append(texts, p.Text)
+		}
+	}
+	return strings.Join(texts, "\n\n"), nil
+}
+
+// ImportConversation adds conv as the most recent conversation and returns
+// its ID. It gets a new ID if its ID is missing, not safe as a file name, or
+// already in history, so importing a file twice keeps both copies.
+// CreatedAt is kept if set.
+func (h *History) ImportConversation(conv ConversationEntry) string {
+	if !validID(conv.ID) || h.GetConversation(conv.ID) != nil {
+		conv.ID = uuid.New().String()
+	}
+	now := h.now()
+	if conv.CreatedAt.IsZero() {
+		conv.CreatedAt = now
+	}
+	conv.UpdatedAt = now
+
+	h.Conversations = append(h.Conversations, conv)
+	h.dirty[conv.ID] = true
+	delete(h.deleted, conv.ID)
+	return conv.ID
+}
@@ -0,0 +1,110 @@
+package history
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseConversation(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		wantModel string
+		wantMsgs  int
+		wantFirst string
+	}{
+		{
+			name:      "export",
+			data:      `{"id":"abc","model":"gpt-4.1","provider":"GitHub Copilot","messages":[{"role":"user","content":"hi"},{"role":"assistant","content":"hello"}],"created_at":"2026-01-02T03:04:05Z"}`,
+			wantModel: "gpt-4.1",
+			wantMsgs:  2,
+			wantFirst: "hi",
+		},
+		{
+			name:      "request body",
+			data:      `{"model":"gpt-4o","messages":[{"role":"developer","content":"Be brief."},{"role":"user","content":[{"type":"text","text":"What is this?"},{"type":"image_url","image_url":{"url":"data:"}}]}]}`,
+			wantModel: "gpt-4o",
+			wantMsgs:  2,
+			wantFirst: "Be brief.",
+		},
+		{
+			name:      "messages array",
+			data:      ` [{"role":"user","content":"one"},{"role":"assistant","content":null,"tool_calls":[{"id":"c1","type":"function","function":{"name":"read_file","arguments":"{}"}}]},{"role":"tool","tool_call_id":"c1","content":"data"}]`,
+			wantMsgs:  3,
+			wantFirst: "one",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conv, err := ParseConversation([]byte(tt.data))
+			if err != nil {
+				t.Fatalf("ParseConversation() error = %v", err)
+			}
+			if conv.Model != tt.wantModel || len(conv.Messages) != tt.wantMsgs || conv.Messages[0].Content != tt.wantFirst {
+				t.Errorf("ParseConversation() = %+v", conv)
+			}
+		})
+	}
+
+	conv, _ := ParseConversation([]byte(tests[1].data))
+	if conv.Messages[0].Role != "system" || conv.Messages[1].Content != "What is this?" {
+		t.Errorf("request body messages = %+v", conv.Messages)
+	}
+
+	for _, bad := range []string{`not json`, `{"messages":[]}`, `[{"role":"assistant","content":"x"}]`, `[{"role":"function","content":"x"}]`, `[{"role":"user","content":42}]`} {
+		if _, err := ParseConversation([]byte(bad)); !errors.Is(err, ErrInvalidImport) {
+			t.Errorf("ParseConversation(%s) error = %v, want ErrInvalidImport", bad, err)
+		}
+	}
+}
+
+func TestHistory_ImportConversation(t *testing.T) {
+	h := testHistory()
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	h.now = func() time.Time { return now }
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	id := h.ImportConversation(ConversationEntry{ID: "imported-1", Messages: userMessage("hi"), CreatedAt: created})
+	if id != "imported-1" {
+		t.Errorf("ImportConversation() = %q, want the file's ID", id)
+	}
+	newest := h.Newest(1)[0]
+	if newest.ID != id || !newest.CreatedAt.Equal(created) || !newest.UpdatedAt.Equal(now) {
+		t.Errorf("imported conversation = %+v", newest)
+	}
+
+	again := h.ImportConversation(ConversationEntry{ID: "imported-1", Messages: userMessage("hi")})
+	if again == "imported-1" || again == "" {
+		t.Errorf("importing a taken ID gave %q, want a new ID", again)
+	}
+	if conv := h.GetConversation(again); conv == nil || !conv.CreatedAt.Equal(now) {
+		t.Errorf("import without CreatedAt = %+v", conv)
+	}
+}
+
+func TestHistory_ImportConversation_UnsafeID(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "a", "b", ConversationsDirName)
+	h := NewHistoryAt(dir)
+
+	conv, err := ParseConversation([]byte(`{"id":"../../../victim","messages":[{"role":"user","content":"hi"}]}`))
+	if err != nil {
+		t.Fatalf("ParseConversation() error = %v", err)
+	}
+	id := h.ImportConversation(conv)
+	if id == conv.ID || !validID(id) {
+		t.Fatalf("ImportConversation() = %q, want a new ID", id)
+	}
+	if err := h.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, id+".json")); err != nil {
+		t.Errorf("imported conversation not in the history directory: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, conv.ID+".json")); !os.IsNotExist(err) {
+		t.Errorf("file written outside the history directory: %v", err)
+	}
+}
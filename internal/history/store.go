@@ -0,0 +1,337 @@
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/quocvuong92/ai-cli/internal/config"
+)
+
+const (
+	// ConversationsDirName is the directory, under the data directory,
+	// holding one file per conversation
+	ConversationsDirName = "conversations"
+	// LegacyFileName is the single history file used before conversations
+	// were stored separately; it is migrated on the first Load
+	LegacyFileName = "conversation-history.json"
+	// DefaultMaxConversations is how many conversations are kept unless the
+	// retention is configured
+	DefaultMaxConversations = config.DefaultHistoryMaxConversations
+
+	// lockFileName guards writes to the conversations directory
+	lockFileName = ".lock"
+	// lockTimeout is how long Save waits for another process's lock
+	lockTimeout = 5 * time.Second
+	// staleLockAge is when a lock left by a killed process is broken.
+	// Holders only write a few files, so a live lock is never this old.
+	staleLockAge = 30 * time.Second
+)
+
+// ErrLocked is returned when the history lock can't be acquired in time
+var ErrLocked = errors.New("conversation history is locked by another process")
+
+// ErrInvalidID is returned for a conversation ID that can't name a file
+var ErrInvalidID = errors.New("invalid conversation ID")
+
+// maxIDLength bounds conversation IDs, which become file names
+const maxIDLength = 128
+
+// Retention limits which conversations are kept; zero fields don't limit
+type Retention struct {
+	MaxCount int
+	MaxAge   time.Duration
+}
+
+// SetRetention sets how many conversations are kept, and for how long
+// since their last update. Save removes the ones beyond it.
+func (h *History) SetRetention(r Retention) {
+	h.retention = r
+}
+
+// Dir returns the directory conversations are stored in
+func (h *History) Dir() string {
+	return h.dir
+}
+
+// Load reads all conversations from disk, migrating the legacy history file
+// first if there is one. Unreadable conversation files are skipped.
+func (h *History) Load() error {
+	if h.dir == "" {
+		return fmt.Errorf("history path not available")
+	}
+	if err := h.migrate(); err != nil {
+		return err
+	}
+
+	entries, err := os.ReadDir(h.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			// No history yet, start fresh
+			return nil
+		}
+		return fmt.Errorf("failed to read history: %w", err)
+	}
+
+	conversations := make([]ConversationEntry, 0, len(entries))
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(h.dir, e.Name()))
+		if err != nil {
+			log.Printf("Skipping conversation %s: %v", e.Name(), err)
+			continue
+		}
+		var conv ConversationEntry
+		if err := json.Unmarshal(data, &conv); err != nil || !validID(conv.ID) {
+			log.Printf("Skipping malformed conversation %s: %v", e.Name(), err)
+			continue
+		}
+		conversations = append(conversations, conv)
+	}
+	sort.SliceStable(conversations, func(i, j int) bool {
+		return conversations[i].UpdatedAt.Before(conversations[j].UpdatedAt)
+	})
+
+	h.Conversations = conversations
+	h.dirty = make(map[string]bool)
+	h.deleted = make(map[string]bool)
+	return nil
+}
+
+// Save writes the conversations added or changed since the last Save,
+// removes deleted ones, and applies the retention. Other conversations on
+// disk, including those written by other sessions, are left alone.
+func (h *History) Save() error {
+	if h.dir == "" {
+		return fmt.Errorf("history path not available")
+	}
+	if err := os.MkdirAll(h.dir, 0700); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	unlock, err := h.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	for id := range h.deleted {
+		path, err := h.path(id)
+		if err != nil {
+			return err
+		}
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to delete conversation: %w", err)
+		}
+		delete(h.deleted, id)
+	}
+	// In order, so the newest entry of a conversation added twice wins
+	for _, conv := range h.Conversations {
+		if h.dirty[conv.ID] {
+			if err := h.write(conv); err != nil {
+				return err
+			}
+		}
+	}
+	h.dirty = make(map[string]bool)
+	return h.prune()
+}
+
+// write stores conv atomically: readers see the old file or the new one,
+// never a partial write. The file time is set to UpdatedAt for pruning.
+func (h *History) write(conv ConversationEntry) error {
+	path, err := h.path(conv.ID)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(conv, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal conversation: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(h.dir, conv.ID+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write conversation: %w", err)
+	}
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write conversation: %w", err)
+	}
+	_ = os.Chtimes(path, conv.UpdatedAt, conv.UpdatedAt)
+	return nil
+}
+
+// prune removes conversations beyond the retention, oldest first. It goes
+// by the files on disk so conversations saved by other sessions count too.
+// The caller holds the lock.
+func (h *History) prune() error {
+	if h.retention.MaxCount <= 0 && h.retention.MaxAge <= 0 {
+		return nil
+	}
+	entries, err := os.ReadDir(h.dir)
+	if err != nil {
+		return fmt.Errorf("failed to read history: %w", err)
+	}
+
+	type file struct {
+		id      string
+		modTime time.Time
+	}
+	var files []file
+	for _, e := range entries {
+		id, ok := strings.CutSuffix(e.Name(), ".json")
+		if e.IsDir() || !ok || !validID(id) {
+			continue
+		}
+		if info, err := e.Info(); err == nil {
+			files = append(files, file{id, info.ModTime()})
+		}
+	}
+	// Newest first
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.After(files[j].modTime) })
+
+	now := h.now()
+	for i, f := range files {
+		tooMany := h.retention.MaxCount > 0 && i >= h.retention.MaxCount
+		tooOld := h.retention.MaxAge > 0 && now.Sub(f.modTime) > h.retention.MaxAge
+		if !tooMany && !tooOld {
+			continue
+		}
+		path, err := h.path(f.id)
+		if err != nil {
+			return err
+		}
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to prune conversation: %w", err)
+		}
+		h.forget(f.id)
+	}
+	return nil
+}
+
+// forget drops a pruned conversation from memory
+func (h *History) forget(id string) {
+	for i := range h.Conversations {
+		if h.Conversations[i].ID == id {
+			h.Conversations = append(h.Conversations[:i], h.Conversations[i+1:]...)
+			return
+		}
+	}
+}
+
+// legacyHistory is the layout of the legacy history file
+type legacyHistory struct {
+	Conversations []ConversationEntry `json:"conversations"`
+}
+
+// migrate moves the conversations of the legacy history file, which sits
+// next to the conversations directory, into their own files. The legacy
+// file is renamed with a ".migrated" suffix rather than deleted.
+func (h *History) migrate() error {
+	legacy := filepath.Join(filepath.Dir(h.dir), LegacyFileName)
+	data, err := os.ReadFile(legacy)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read history: %w", err)
+	}
+	var old legacyHistory
+	if err := json.Unmarshal(data, &old); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", legacy, err)
+	}
+
+	if err := os.MkdirAll(h.dir, 0700); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+	unlock, err := h.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	for _, conv := range old.Conversations {
+		// The legacy file could hold the same conversation more than once;
+		// the last entry is the most complete
+		if !validID(conv.ID) {
+			log.Printf("Skipping legacy conversation with invalid ID %q", conv.ID)
+			continue
+		}
+		if err := h.write(conv); err != nil {
+			return err
+		}
+	}
+	if err := os.Rename(legacy, legacy+".migrated"); err != nil {
+		return fmt.Errorf("failed to retire %s: %w", legacy, err)
+	}
+	log.Printf("Migrated %d conversations from %s", len(old.Conversations), legacy)
+	return nil
+}
+
+// lock takes the history lock, a file created exclusively so it works on
+// every platform. A lock older than staleLockAge is broken.
+func (h *History) lock() (func(), error) {
+	path := filepath.Join(h.dir, lockFileName)
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			_, _ = fmt.Fprintf(f, "%d\n", os.Getpid())
+			_ = f.Close()
+			return func() { _ = os.Remove(path) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("failed to lock history: %w", err)
+		}
+		if info, statErr := os.Stat(path); statErr == nil && time.Since(info.ModTime()) > staleLockAge {
+			log.Printf("Breaking stale history lock %s", path)
+			_ = os.Remove(path)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, ErrLocked
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// path returns the file holding the conversation with id. IDs that could
+// name a file outside the history directory are rejected.
+func (h *History) path(id string) (string, error) {
+	if !validID(id) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidID, id)
+	}
+	return filepath.Join(h.dir, id+".json"), nil
+}
+
+// validID reports whether id is safe as a file name: letters, digits and
+// hyphens, as in a UUID
+func validID(id string) bool {
+	if id == "" || len(id) > maxIDLength {
+		return false
+	}
+	for _, r := range id {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,197 @@
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/quocvuong92/ai-cli/internal/api"
+)
+
+func userMessage(content string) []api.Message {
+	return []api.Message{{Role: "user", Content: content}}
+}
+
+func TestHistory_SaveLoad(t *testing.T) {
+	dir := t.TempDir()
+	h := NewHistoryAt(dir)
+	h.AddConversation("conv-1", "gpt-4.1", "copilot", userMessage("first"))
+	h.AddConversation("conv-2", "gpt-4.1", "copilot", userMessage("second"))
+	if err := h.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "conv-1.json")); err != nil {
+		t.Errorf("conversation file missing: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, lockFileName)); !os.IsNotExist(err) {
+		t.Errorf("lock file left behind: %v", err)
+	}
+
+	loaded := NewHistoryAt(dir)
+	if err := loaded.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(loaded.Conversations) != 2 || loaded.GetConversation("conv-2").Messages[0].Content != "second" {
+		t.Fatalf("Load() = %+v", loaded.Conversations)
+	}
+}
+
+func TestHistory_LoadMissing(t *testing.T) {
+	h := NewHistoryAt(filepath.Join(t.TempDir(), ConversationsDirName))
+	if err := h.Load(); err != nil || len(h.Conversations) != 0 {
+		t.Fatalf("Load() = %d conversations, %v", len(h.Conversations), err)
+	}
+}
+
+func TestHistory_LoadSkipsMalformed(t *testing.T) {
+	dir := t.TempDir()
+	h := NewHistoryAt(dir)
+	h.AddConversation("good", "gpt-4.1", "copilot", userMessage("hi"))
+	if err := h.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "bad.json"), []byte("{not json"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := NewHistoryAt(dir)
+	if err := loaded.Load(); err != nil || len(loaded.Conversations) != 1 {
+		t.Fatalf("Load() = %d conversations, %v", len(loaded.Conversations), err)
+	}
+}
+
+func TestHistory_ConcurrentSessions(t *testing.T) {
+	dir := t.TempDir()
+	a, b := NewHistoryAt(dir), NewHistoryAt(dir)
+	if err := a.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Each session saves only its own conversation, so neither loses the other's
+	a.AddConversation("from-a", "gpt-4.1", "copilot", userMessage("a"))
+	b.AddConversation("from-b", "gpt-4.1", "copilot", userMessage("b"))
+	if err := a.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := NewHistoryAt(dir)
+	if err := loaded.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if loaded.GetConversation("from-a") == nil || loaded.GetConversation("from-b") == nil {
+		t.Errorf("Load() after concurrent saves = %+v", loaded.Conversations)
+	}
+}
+
+func TestHistory_DeleteRemovesFile(t *testing.T) {
+	dir := t.TempDir()
+	h := NewHistoryAt(dir)
+	h.AddConversation("conv-1", "gpt-4.1", "copilot", userMessage("hi"))
+	if err := h.Save(); err != nil {
+		t.Fatal(err)
+	}
+	h.DeleteConversation("conv-1")
+	if err := h.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "conv-1.json")); !os.IsNotExist(err) {
+		t.Errorf("deleted conversation file still exists: %v", err)
+	}
+}
+
+func TestHistory_SaveRejectsUnsafeID(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "conversations")
+	h := NewHistoryAt(dir)
+	h.AddConversation("../escaped", "gpt-4.1", "copilot", userMessage("hi"))
+	if err := h.Save(); !errors.Is(err, ErrInvalidID) {
+		t.Errorf("Save() error = %v, want ErrInvalidID", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "..", "escaped.json")); !os.IsNotExist(err) {
+		t.Errorf("file written outside the history directory: %v", err)
+	}
+}
+
+func TestHistory_Retention(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	h := NewHistoryAt(dir)
+	h.SetRetention(Retention{MaxCount: 2, MaxAge: 30 * 24 * time.Hour})
+
+	// Oldest first: one beyond the max age, then three recent ones
+	for i, age := range []time.Duration{40 * 24 * time.Hour, 3 * time.Hour, 2 * time.Hour, time.Hour} {
+		h.now = func() time.Time { return now.Add(-age) }
+		h.AddConversation(string(rune('a'+i)), "gpt-4.1", "copilot", userMessage("hi"))
+	}
+	h.now = func() time.Time { return now }
+	if err := h.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := NewHistoryAt(dir)
+	if err := loaded.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.Conversations) != 2 || loaded.Conversations[0].ID != "c" || loaded.Conversations[1].ID != "d" {
+		t.Errorf("after retention: %+v", loaded.Conversations)
+	}
+	if len(h.Conversations) != 2 {
+		t.Errorf("pruned conversations still in memory: %d", len(h.Conversations))
+	}
+}
+
+func TestHistory_MigrateLegacy(t *testing.T) {
+	dataDir := t.TempDir()
+	legacy := legacyHistory{Conversations: []ConversationEntry{
+		{ID: "old-1", Model: "gpt-4.1", Messages: userMessage("one"), UpdatedAt: time.Now().Add(-time.Hour)},
+		{ID: "old-2", Model: "gpt-4.1", Messages: userMessage("two"), UpdatedAt: time.Now()},
+	}}
+	data, err := json.Marshal(legacy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	legacyPath := filepath.Join(dataDir, LegacyFileName)
+	if err := os.WriteFile(legacyPath, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	h := NewHistoryAt(filepath.Join(dataDir, ConversationsDirName))
+	if err := h.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(h.Conversations) != 2 || h.Conversations[1].ID != "old-2" {
+		t.Fatalf("migrated conversations = %+v", h.Conversations)
+	}
+	if _, err := os.Stat(legacyPath); !os.IsNotExist(err) {
+		t.Errorf("legacy file not retired: %v", err)
+	}
+	if _, err := os.Stat(legacyPath + ".migrated"); err != nil {
+		t.Errorf("legacy file not kept as .migrated: %v", err)
+	}
+}
+
+func TestHistory_StaleLock(t *testing.T) {
+	dir := t.TempDir()
+	lockPath := filepath.Join(dir, lockFileName)
+	if err := os.WriteFile(lockPath, []byte("1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-2 * staleLockAge)
+	if err := os.Chtimes(lockPath, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	h := NewHistoryAt(dir)
+	h.AddConversation("conv-1", "gpt-4.1", "copilot", userMessage("hi"))
+	if err := h.Save(); err != nil {
+		t.Fatalf("Save() with a stale lock error = %v", err)
+	}
+}
@@ -0,0 +1,321 @@
+// Package jsonschema validates JSON values against the subset of JSON Schema
+// that structured-output models are asked to follow: types, properties,
+// required, additionalProperties, items, enum, const, numeric and length
+// bounds, pattern, and the allOf/anyOf/oneOf combinators.
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Schema is a parsed JSON Schema document
+type Schema struct {
+	raw  json.RawMessage
+	root map[string]interface{}
+}
+
+// Parse parses a JSON Schema document. The document must be a JSON object.
+func Parse(data []byte) (*Schema, error) {
+	var root map[string]interface{}
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("invalid JSON schema: %w", err)
+	}
+	compact := new(bytes.Buffer)
+	if err := json.Compact(compact, data); err != nil {
+		return nil, fmt.Errorf("invalid JSON schema: %w", err)
+	}
+	return &Schema{raw: compact.Bytes(), root: root}, nil
+}
+
+// Load reads and parses a JSON Schema file
+func Load(path string) (*Schema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema file: %w", err)
+	}
+	return Parse(data)
+}
+
+// Raw returns the compacted schema document
+func (s *Schema) Raw() json.RawMessage {
+	return s.raw
+}
+
+// Title returns the schema's title, or "" if it has none
+func (s *Schema) Title() string {
+	title, _ := s.root["title"].(string)
+	return title
+}
+
+// Validate parses data as JSON and checks it against the schema.
+// It returns one message per violation, each prefixed with the JSON path
+// of the offending value; an empty result means the value is valid.
+func (s *Schema) Validate(data []byte) []string {
+	var value interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&value); err != nil {
+		return []string{fmt.Sprintf("$: not valid JSON: %v", err)}
+	}
+	if dec.More() {
+		return []string{"$: unexpected data after the JSON value"}
+	}
+
+	var errs []string
+	validate(s.root, value, "$", &errs)
+	return errs
+}
+
+// validate checks value against schema, appending violations to errs
+func validate(schema map[string]interface{}, value interface{}, path string, errs *[]string) {
+	addf := func(format string, args ...interface{}) {
+		*errs = append(*errs, path+": "+fmt.Sprintf(format, args...))
+	}
+
+	if t, ok := schema["type"]; ok && !matchesType(t, value) {
+		addf("expected %s, got %s", describeType(t), typeOf(value))
+		return
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, e := range enum {
+			if equal(e, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			addf("value %s is not one of %s", encode(value), encode(enum))
+		}
+	}
+	if c, ok := schema["const"]; ok && !equal(c, value) {
+		addf("value %s does not equal %s", encode(value), encode(c))
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		validateObject(schema, v, path, errs)
+	case []interface{}:
+		validateArray(schema, v, path, errs)
+	case string:
+		length := len([]rune(v))
+		if n, ok := number(schema["minLength"]); ok && float64(length) < n {
+			addf("string is shorter than %v characters", n)
+		}
+		if n, ok := number(schema["maxLength"]); ok && float64(length) > n {
+			addf("string is longer than %v characters", n)
+		}
+		if pattern, ok := schema["pattern"].(string); ok {
+			if re, err := regexp.Compile(pattern); err == nil && !re.MatchString(v) {
+				addf("string does not match pattern %q", pattern)
+			}
+		}
+	case json.Number:
+		f, _ := v.Float64()
+		if n, ok := number(schema["minimum"]); ok && f < n {
+			addf("%v is less than minimum %v", v, n)
+		}
+		if n, ok := number(schema["maximum"]); ok && f > n {
+			addf("%v is greater than maximum %v", v, n)
+		}
+		if n, ok := number(schema["exclusiveMinimum"]); ok && f <= n {
+			addf("%v must be greater than %v", v, n)
+		}
+		if n, ok := number(schema["exclusiveMaximum"]); ok && f >= n {
+			addf("%v must be less than %v", v, n)
+		}
+	}
+
+	if all, ok := schema["allOf"].([]interface{}); ok {
+		for _, sub := range all {
+			if subSchema, ok := sub.(map[string]interface{}); ok {
+				validate(subSchema, value, path, errs)
+			}
+		}
+	}
+	if anyOf, ok := schema["anyOf"].([]interface{}); ok && countMatches(anyOf, value, path) == 0 {
+		addf("value does not match any of the allowed schemas")
+	}
+	if oneOf, ok := schema["oneOf"].([]interface{}); ok {
+		if n := countMatches(oneOf, value, path); n != 1 {
+			addf("value matches %d schemas in oneOf, expected exactly 1", n)
+		}
+	}
+}
+
+// validateObject checks object keywords
+func validateObject(schema map[string]interface{}, obj map[string]interface{}, path string, errs *[]string) {
+	if required, ok := schema["required"].([]interface{}); ok {
+		for _, r := range required {
+			if name, ok := r.(string); ok {
+				if _, present := obj[name]; !present {
+					*errs = append(*errs, fmt.Sprintf("%s: missing required property %q", path, name))
+				}
+			}
+		}
+	}
+
+	properties, _ := schema["properties"].(map[string]interface{})
+
+	// Sort keys so error messages are deterministic
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		childPath := path + "." + k
+		if prop, ok := properties[k].(map[string]interface{}); ok {
+			validate(prop, obj[k], childPath, errs)
+			continue
+		}
+		switch additional := schema["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				*errs = append(*errs, fmt.Sprintf("%s: property %q is not allowed", path, k))
+			}
+		case map[string]interface{}:
+			validate(additional, obj[k], childPath, errs)
+		}
+	}
+}
+
+// validateArray checks array keywords
+func validateArray(schema map[string]interface{}, arr []interface{}, path string, errs *[]string) {
+	if n, ok := number(schema["minItems"]); ok && float64(len(arr)) < n {
+		*errs = append(*errs, fmt.Sprintf("%s: array has fewer than %v items", path, n))
+	}
+	if n, ok := number(schema["maxItems"]); ok && float64(len(arr)) > n {
+		*errs = append(*errs, fmt.Sprintf("%s: array has more than %v items", path, n))
+	}
+	if items, ok := schema["items"].(map[string]interface{}); ok {
+		for i, item := range arr {
+			validate(items, item, fmt.Sprintf("%s[%d]", path, i), errs)
+		}
+	}
+}
+
+// countMatches returns how many of the schemas value satisfies
+func countMatches(schemas []interface{}, value interface{}, path string) int {
+	n := 0
+	for _, sub := range schemas {
+		subSchema, ok := sub.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		var subErrs []string
+		validate(subSchema, value, path, &subErrs)
+		if len(subErrs) == 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// matchesType reports whether value matches a "type" keyword (a string or list of strings)
+func matchesType(t interface{}, value interface{}) bool {
+	switch t := t.(type) {
+	case string:
+		return matchesSingleType(t, value)
+	case []interface{}:
+		for _, item := range t {
+			if name, ok := item.(string); ok && matchesSingleType(name, value) {
+				return true
+			}
+		}
+		return false
+	default:
+		return true
+	}
+}
+
+func matchesSingleType(name string, value interface{}) bool {
+	switch name {
+	case "integer":
+		n, ok := value.(json.Number)
+		if !ok {
+			return false
+		}
+		f, err := n.Float64()
+		return err == nil && f == math.Trunc(f)
+	case "number":
+		_, ok := value.(json.Number)
+		return ok
+	default:
+		return typeOf(value) == name
+	}
+}
+
+// typeOf returns the JSON Schema type name of a decoded value
+func typeOf(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// describeType formats a "type" keyword for error messages
+func describeType(t interface{}) string {
+	if list, ok := t.([]interface{}); ok {
+		names := make([]string, 0, len(list))
+		for _, item := range list {
+			names = append(names, fmt.Sprint(item))
+		}
+		return strings.Join(names, " or ")
+	}
+	return fmt.Sprint(t)
+}
+
+// number converts a schema keyword value to float64
+func number(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// equal compares a schema value (decoded with float64 numbers) to an
+// instance value (decoded with json.Number) by their JSON encoding
+func equal(schemaValue, value interface{}) bool {
+	if n, ok := value.(json.Number); ok {
+		f, err := n.Float64()
+		sf, sok := number(schemaValue)
+		return err == nil && sok && f == sf
+	}
+	return encode(schemaValue) == encode(value)
+}
+
+// encode returns the compact JSON encoding of v for comparisons and messages
+func encode(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
@@ -0,0 +1,97 @@
+package jsonschema
+
+import (
+	"strings"
+	"testing"
+)
+
+const personSchema = `{
+  "title": "person",
+  "type": "object",
+  "properties": {
+    "name": {"type": "string", "minLength": 1},
+    "age": {"type": "integer", "minimum": 0},
+    "role": {"enum": ["admin", "user"]},
+    "tags": {"type": "array", "items": {"type": "string"}, "maxItems": 2},
+    "email": {"type": ["string", "null"], "pattern": "@"}
+  },
+  "required": ["name", "age"],
+  "additionalProperties": false
+}`
+
+func TestSchema_Validate(t *testing.T) {
+	schema, err := Parse([]byte(personSchema))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		wantErrs []string // substrings expected in the errors, in order
+	}{
+		{"valid", `{"name":"Ada","age":36,"role":"admin","tags":["x"],"email":null}`, nil},
+		{"missing required", `{"name":"Ada"}`, []string{`$: missing required property "age"`}},
+		{"wrong type", `{"name":"Ada","age":"36"}`, []string{"$.age: expected integer, got string"}},
+		{"not an integer", `{"name":"Ada","age":36.5}`, []string{"$.age: expected integer"}},
+		{"below minimum", `{"name":"Ada","age":-1}`, []string{"$.age: -1 is less than minimum 0"}},
+		{"enum", `{"name":"Ada","age":1,"role":"root"}`, []string{`$.role: value "root" is not one of`}},
+		{"extra property", `{"name":"Ada","age":1,"nick":"a"}`, []string{`$: property "nick" is not allowed`}},
+		{"array items", `{"name":"Ada","age":1,"tags":["a",2,"c"]}`, []string{"$.tags: array has more than 2 items", "$.tags[1]: expected string, got number"}},
+		{"pattern", `{"name":"Ada","age":1,"email":"nope"}`, []string{`$.email: string does not match pattern "@"`}},
+		{"empty string", `{"name":"","age":1}`, []string{"$.name: string is shorter than 1 characters"}},
+		{"not json", `{"name":`, []string{"$: not valid JSON"}},
+		{"trailing data", `{"name":"Ada","age":1} {}`, []string{"$: unexpected data after the JSON value"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := schema.Validate([]byte(tt.input))
+			if len(errs) != len(tt.wantErrs) {
+				t.Fatalf("Validate() = %q, want %d errors", errs, len(tt.wantErrs))
+			}
+			for i, want := range tt.wantErrs {
+				if !strings.Contains(errs[i], want) {
+					t.Errorf("error[%d] = %q, want it to contain %q", i, errs[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestSchema_Combinators(t *testing.T) {
+	schema, err := Parse([]byte(`{"oneOf":[{"type":"string"},{"type":"integer"}],"anyOf":[{"const":"a"},{"minimum":10}]}`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if errs := schema.Validate([]byte(`"a"`)); len(errs) != 0 {
+		t.Errorf(`Validate("a") = %q, want no errors`, errs)
+	}
+	if errs := schema.Validate([]byte(`12`)); len(errs) != 0 {
+		t.Errorf("Validate(12) = %q, want no errors", errs)
+	}
+	if errs := schema.Validate([]byte(`3`)); len(errs) != 1 || !strings.Contains(errs[0], "any of") {
+		t.Errorf("Validate(3) = %q, want anyOf error", errs)
+	}
+	if errs := schema.Validate([]byte(`true`)); len(errs) == 0 || !strings.Contains(errs[0], "oneOf") {
+		t.Errorf("Validate(true) = %q, want oneOf error", errs)
+	}
+}
+
+func TestParse(t *testing.T) {
+	schema, err := Parse([]byte("{\n  \"title\": \"person\",\n  \"type\": \"object\"\n}"))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if schema.Title() != "person" {
+		t.Errorf("Title() = %q, want %q", schema.Title(), "person")
+	}
+	if string(schema.Raw()) != `{"title":"person","type":"object"}` {
+		t.Errorf("Raw() = %s, want compacted schema", schema.Raw())
+	}
+
+	if _, err := Parse([]byte(`[1,2]`)); err == nil {
+		t.Error("Parse() of a non-object should fail")
+	}
+}
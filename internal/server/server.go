@@ -0,0 +1,294 @@
+// Package server exposes an api.AIClient through an OpenAI-compatible HTTP API,
+// so other tools can use the configured provider (e.g. a Copilot subscription)
+// as a standard chat completions endpoint.
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/quocvuong92/ai-cli/internal/api"
+)
+
+// maxRequestBody limits the size of a chat completion request
+const maxRequestBody = 32 << 20
+
+// Options configures a Server
+type Options struct {
+	// Token, when set, must be sent by clients as "Authorization: Bearer <token>"
+	Token string
+	// DefaultModel is used for requests that don't name a model
+	DefaultModel string
+	// Models are listed by /v1/models
+	Models []string
+	// OwnedBy is reported as the owner of each listed model
+	OwnedBy string
+}
+
+// Server handles the OpenAI-compatible routes
+type Server struct {
+	client api.AIClient
+	opts   Options
+	mux    *http.ServeMux
+}
+
+// New creates a Server backed by client
+func New(client api.AIClient, opts Options) *Server {
+	s := &Server{
+		client: client,
+		opts:   opts,
+		mux:    http.NewServeMux(),
+	}
+	s.mux.HandleFunc("POST /v1/chat/completions", s.handleChatCompletions)
+	s.mux.HandleFunc("GET /v1/models", s.handleModels)
+	return s
+}
+
+// ServeHTTP checks the bearer token and dispatches to the routes
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.opts.Token != "" && !s.authorized(r) {
+		writeError(w, http.StatusUnauthorized, "invalid_api_key", "Invalid or missing bearer token")
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// authorized compares the request's bearer token in constant time
+func (s *Server) authorized(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(token), []byte(s.opts.Token)) == 1
+}
+
+// handleModels lists the configured models
+func (s *Server) handleModels(w http.ResponseWriter, r *http.Request) {
+	type model struct {
+		ID      string `json:"id"`
+		Object  string `json:"object"`
+		Created int64  `json:"created"`
+		OwnedBy string `json:"owned_by"`
+	}
+
+	data := make([]model, len(s.opts.Models))
+	for i, m := range s.opts.Models {
+		data[i] = model{ID: m, Object: "model", OwnedBy: s.opts.OwnedBy}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"object": "list", "data": data})
+}
+
+// handleChatCompletions serves /v1/chat/completions in both modes
+func (s *Server) handleChatCompletions(w http.ResponseWriter, r *http.Request) {
+	var body completionRequest
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBody))
+	if err := dec.Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", fmt.Sprintf("Invalid request body: %v", err))
+		return
+	}
+
+	req, err := body.toRequest()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", err.Error())
+		return
+	}
+	if req.Model == "" {
+		req.Model = s.opts.DefaultModel
+	}
+	log.Printf("serve: %s model=%s messages=%d stream=%v", r.URL.Path, req.Model, len(req.Messages), body.Stream)
+
+	id := "chatcmpl-" + uuid.New().String()
+	if body.Stream {
+		s.stream(w, r, req, id, body.StreamOptions != nil && body.StreamOptions.IncludeUsage)
+		return
+	}
+
+	resp, err := s.client.Complete(r.Context(), req)
+	if err != nil {
+		writeUpstreamError(w, err)
+		return
+	}
+
+	out := completionResponse{
+		ID:      id,
+		Object:  "chat.completion",
+		Created: time.Now().Unix(),
+		Model:   req.Model,
+		Usage:   &resp.Usage,
+	}
+	for i, choice := range resp.Choices {
+		message := choice.Message
+		if message.Role == "" {
+			message.Role = "assistant"
+		}
+		finish := finishReason(choice.FinishReason, len(message.ToolCalls) > 0)
+		out.Choices = append(out.Choices, completionChoice{Index: i, Message: &message, FinishReason: &finish})
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// stream relays the client's stream as server-sent events. Headers are only
+// sent with the first event, so errors before any output get a normal
+// JSON error response.
+func (s *Server) stream(w http.ResponseWriter, r *http.Request, req api.Request, id string, includeUsage bool) {
+	flusher, _ := w.(http.Flusher)
+	created := time.Now().Unix()
+	started := false
+
+	send := func(v interface{}) {
+		if !started {
+			started = true
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.Header().Set("Cache-Control", "no-cache")
+			w.Header().Set("Connection", "keep-alive")
+			w.WriteHeader(http.StatusOK)
+		}
+		data, _ := json.Marshal(v)
+		_, _ = fmt.Fprintf(w, "data: %s\n\n", data)
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+	chunk := func(delta *completionDelta, finish *string) completionResponse {
+		return completionResponse{
+			ID:      id,
+			Object:  "chat.completion.chunk",
+			Created: created,
+			Model:   req.Model,
+			Choices: []completionChoice{{Delta: delta, FinishReason: finish}},
+		}
+	}
+
+	var final *api.ChatResponse
+	err := s.client.Stream(r.Context(), req, api.StreamHandler{
+		OnReasoning: func(content string) {
+			delta := &completionDelta{ReasoningContent: content}
+			if !started {
+				delta.Role = "assistant"
+			}
+			send(chunk(delta, nil))
+		},
+		OnChunk: func(content string) {
+			delta := &completionDelta{Content: content}
+			if !started {
+				delta.Role = "assistant"
+			}
+			send(chunk(delta, nil))
+		},
+		OnDone: func(resp *api.ChatResponse) {
+			final = resp
+		},
+	})
+	if err != nil {
+		if !started {
+			writeUpstreamError(w, err)
+			return
+		}
+		status, errType := errorStatus(err)
+		log.Printf("serve: stream failed with %d: %v", status, err)
+		send(errorBody(errType, err.Error()))
+		return
+	}
+
+	delta := &completionDelta{}
+	if !started {
+		delta.Role = "assistant"
+	}
+	var toolCalls []api.ToolCall
+	if final != nil && len(final.Choices) > 0 {
+		toolCalls = final.Choices[0].GetToolCalls()
+		for i, tc := range toolCalls {
+			delta.ToolCalls = append(delta.ToolCalls, streamToolCall{
+				Index:    i,
+				ID:       tc.ID,
+				Type:     tc.Type,
+				Function: tc.Function,
+			})
+		}
+	}
+	finish := "stop"
+	if final != nil && len(final.Choices) > 0 {
+		finish = finishReason(final.Choices[0].FinishReason, len(toolCalls) > 0)
+	}
+	send(chunk(delta, &finish))
+
+	if includeUsage && final != nil {
+		usage := final.Usage
+		send(completionResponse{
+			ID:      id,
+			Object:  "chat.completion.chunk",
+			Created: created,
+			Model:   req.Model,
+			Choices: []completionChoice{},
+			Usage:   &usage,
+		})
+	}
+
+	_, _ = fmt.Fprint(w, "data: [DONE]\n\n")
+	if flusher != nil {
+		flusher.Flush()
+	}
+}
+
+// finishReason fills in a missing finish reason
+func finishReason(reason string, hasToolCalls bool) string {
+	switch {
+	case reason != "":
+		return reason
+	case hasToolCalls:
+		return "tool_calls"
+	default:
+		return "stop"
+	}
+}
+
+// errorStatus maps a client error to an HTTP status and OpenAI error type.
+// Upstream API errors keep their status; anything else is a bad gateway.
+func errorStatus(err error) (int, string) {
+	var apiErr *api.APIError
+	if !errors.As(err, &apiErr) {
+		return http.StatusBadGateway, "api_error"
+	}
+	switch {
+	case apiErr.StatusCode == http.StatusUnauthorized || apiErr.StatusCode == http.StatusForbidden:
+		// The upstream credentials failed, not the local client's
+		return http.StatusBadGateway, "upstream_authentication_error"
+	case apiErr.StatusCode == http.StatusTooManyRequests:
+		return apiErr.StatusCode, "rate_limit_error"
+	case apiErr.StatusCode >= 400 && apiErr.StatusCode < 500:
+		return apiErr.StatusCode, "invalid_request_error"
+	default:
+		return http.StatusBadGateway, "api_error"
+	}
+}
+
+// writeUpstreamError writes a client error as an OpenAI error response
+func writeUpstreamError(w http.ResponseWriter, err error) {
+	status, errType := errorStatus(err)
+	log.Printf("serve: request failed with %d: %v", status, err)
+	writeError(w, status, errType, err.Error())
+}
+
+// writeError writes an OpenAI-style error response
+func writeError(w http.ResponseWriter, status int, errType, message string) {
+	writeJSON(w, status, errorBody(errType, message))
+}
+
+// errorBody builds an OpenAI-style error object
+func errorBody(errType, message string) map[string]interface{} {
+	return map[string]interface{}{
+		"error": map[string]string{"message": message, "type": errType},
+	}
+}
+
+// writeJSON writes v as a JSON response
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
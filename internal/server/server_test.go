@@ -0,0 +1,245 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/quocvuong92/ai-cli/internal/api"
+)
+
+// fakeProvider answers with fixed content or an error and records requests
+type fakeProvider struct {
+	chunks    []string
+	toolCalls []api.ToolCall
+	err       error
+	requests  []api.Request
+}
+
+func (p *fakeProvider) response() *api.ChatResponse {
+	return &api.ChatResponse{
+		Choices: []api.Choice{{Message: api.Message{
+			Role:      "assistant",
+			Content:   strings.Join(p.chunks, ""),
+			ToolCalls: p.toolCalls,
+		}}},
+		Usage: api.Usage{PromptTokens: 5, CompletionTokens: 2, TotalTokens: 7},
+	}
+}
+
+func (p *fakeProvider) Complete(ctx context.Context, r api.Request) (*api.ChatResponse, error) {
+	p.requests = append(p.requests, r)
+	if p.err != nil {
+		return nil, p.err
+	}
+	return p.response(), nil
+}
+
+func (p *fakeProvider) Stream(ctx context.Context, r api.Request, handler api.StreamHandler) error {
+	p.requests = append(p.requests, r)
+	if p.err != nil {
+		return p.err
+	}
+	for _, c := range p.chunks {
+		handler.OnChunk(c)
+	}
+	if handler.OnDone != nil {
+		handler.OnDone(p.response())
+	}
+	return nil
+}
+
+func newTestServer(provider *fakeProvider, token string) *httptest.Server {
+	return httptest.NewServer(New(api.NewClientAdapter(provider), Options{
+		Token:        token,
+		DefaultModel: "gpt-4.1",
+		Models:       []string{"gpt-4.1", "gpt-5-mini"},
+		OwnedBy:      "copilot",
+	}))
+}
+
+func post(t *testing.T, url, token, body string) *http.Response {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodPost, url+"/v1/chat/completions", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("POST failed: %v", err)
+	}
+	t.Cleanup(func() { _ = resp.Body.Close() })
+	return resp
+}
+
+func TestServer_Auth(t *testing.T) {
+	srv := newTestServer(&fakeProvider{chunks: []string{"hi"}}, "secret")
+	defer srv.Close()
+
+	body := `{"messages":[{"role":"user","content":"hello"}]}`
+	if resp := post(t, srv.URL, "", body); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("no token: status = %d, want 401", resp.StatusCode)
+	}
+	if resp := post(t, srv.URL, "wrong", body); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("wrong token: status = %d, want 401", resp.StatusCode)
+	}
+	if resp := post(t, srv.URL, "secret", body); resp.StatusCode != http.StatusOK {
+		t.Errorf("valid token: status = %d, want 200", resp.StatusCode)
+	}
+}
+
+func TestServer_Models(t *testing.T) {
+	srv := newTestServer(&fakeProvider{}, "")
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/v1/models")
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var list struct {
+		Object string `json:"object"`
+		Data   []struct {
+			ID      string `json:"id"`
+			OwnedBy string `json:"owned_by"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if list.Object != "list" || len(list.Data) != 2 || list.Data[1].ID != "gpt-5-mini" || list.Data[0].OwnedBy != "copilot" {
+		t.Errorf("models = %+v", list)
+	}
+}
+
+func TestServer_ChatCompletion(t *testing.T) {
+	provider := &fakeProvider{chunks: []string{"Hello", " there"}}
+	srv := newTestServer(provider, "")
+	defer srv.Close()
+
+	resp := post(t, srv.URL, "", `{
+		"model": "gpt-5-mini",
+		"messages": [
+			{"role": "system", "content": "Be brief."},
+			{"role": "user", "content": [{"type": "text", "text": "hi"}, {"type": "text", "text": "there"}]}
+		],
+		"temperature": 0.2,
+		"max_completion_tokens": 50,
+		"stop": "END",
+		"tool_choice": {"type": "function", "function": {"name": "read_file"}}
+	}`)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+
+	var out struct {
+		Object  string `json:"object"`
+		Model   string `json:"model"`
+		Choices []struct {
+			Message      api.Message `json:"message"`
+			FinishReason string      `json:"finish_reason"`
+		} `json:"choices"`
+		Usage api.Usage `json:"usage"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if out.Object != "chat.completion" || out.Model != "gpt-5-mini" || len(out.Choices) != 1 ||
+		out.Choices[0].Message.Content != "Hello there" || out.Choices[0].FinishReason != "stop" || out.Usage.TotalTokens != 7 {
+		t.Errorf("response = %+v", out)
+	}
+
+	req := provider.requests[0]
+	if req.Model != "gpt-5-mini" || req.Messages[1].Content != "hi\nthere" || req.MaxTokens != 50 ||
+		req.Temperature == nil || *req.Temperature != 0.2 || len(req.Stop) != 1 || req.ToolChoice.Function != "read_file" {
+		t.Errorf("request = %+v", req)
+	}
+}
+
+func TestServer_ChatCompletion_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		provider   *fakeProvider
+		body       string
+		wantStatus int
+		wantType   string
+	}{
+		{"invalid json", &fakeProvider{}, `{"messages":`, http.StatusBadRequest, "invalid_request_error"},
+		{"no messages", &fakeProvider{}, `{"messages":[]}`, http.StatusBadRequest, "invalid_request_error"},
+		{"image part", &fakeProvider{}, `{"messages":[{"role":"user","content":[{"type":"image_url"}]}]}`, http.StatusBadRequest, "invalid_request_error"},
+		{"rate limited", &fakeProvider{err: &api.APIError{StatusCode: 429, Message: "slow down"}}, `{"messages":[{"role":"user","content":"hi"}]}`, http.StatusTooManyRequests, "rate_limit_error"},
+		{"upstream auth", &fakeProvider{err: &api.APIError{StatusCode: 401, Message: "bad token"}}, `{"messages":[{"role":"user","content":"hi"}]}`, http.StatusBadGateway, "upstream_authentication_error"},
+		{"stream error before output", &fakeProvider{err: &api.APIError{StatusCode: 500, Message: "boom"}}, `{"stream":true,"messages":[{"role":"user","content":"hi"}]}`, http.StatusBadGateway, "api_error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newTestServer(tt.provider, "")
+			defer srv.Close()
+
+			resp := post(t, srv.URL, "", tt.body)
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			var out struct {
+				Error struct {
+					Type    string `json:"type"`
+					Message string `json:"message"`
+				} `json:"error"`
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if out.Error.Type != tt.wantType || out.Error.Message == "" {
+				t.Errorf("error = %+v, want type %q", out.Error, tt.wantType)
+			}
+		})
+	}
+}
+
+func TestServer_ChatCompletion_Stream(t *testing.T) {
+	call := api.ToolCall{ID: "call_1", Type: "function"}
+	call.Function.Name = "read_file"
+	call.Function.Arguments = `{"path":"go.mod"}`
+	provider := &fakeProvider{chunks: []string{"Let me ", "check."}, toolCalls: []api.ToolCall{call}}
+	srv := newTestServer(provider, "")
+	defer srv.Close()
+
+	resp := post(t, srv.URL, "", `{"stream":true,"stream_options":{"include_usage":true},"messages":[{"role":"user","content":"hi"}]}`)
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q, want text/event-stream", ct)
+	}
+
+	var events []string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		if data, ok := strings.CutPrefix(scanner.Text(), "data: "); ok {
+			events = append(events, data)
+		}
+	}
+
+	want := []string{
+		`"delta":{"role":"assistant","content":"Let me "},"finish_reason":null`,
+		`"delta":{"content":"check."},"finish_reason":null`,
+		`"delta":{"tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"read_file","arguments":"{\"path\":\"go.mod\"}"}}]},"finish_reason":"tool_calls"`,
+		`"choices":[],"usage":{"prompt_tokens":5,"completion_tokens":2,"total_tokens":7}`,
+		`[DONE]`,
+	}
+	if len(events) != len(want) {
+		t.Fatalf("got %d events, want %d:\n%s", len(events), len(want), strings.Join(events, "\n"))
+	}
+	for i, w := range want {
+		if !strings.Contains(events[i], w) {
+			t.Errorf("event[%d] = %s, want it to contain %s", i, events[i], w)
+		}
+	}
+	if provider.requests[0].Model != "gpt-4.1" {
+		t.Errorf("request model = %q, want default gpt-4.1", provider.requests[0].Model)
+	}
+}
@@ -0,0 +1,176 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/quocvuong92/ai-cli/internal/api"
+)
+
+// completionRequest is the subset of the OpenAI chat completions request
+// that maps onto api.Request
+type completionRequest struct {
+	Model               string              `json:"model"`
+	Messages            []incomingMessage   `json:"messages"`
+	Tools               []api.Tool          `json:"tools,omitempty"`
+	ToolChoice          *api.ToolChoice     `json:"tool_choice,omitempty"`
+	ResponseFormat      *api.ResponseFormat `json:"response_format,omitempty"`
+	Stream              bool                `json:"stream,omitempty"`
+	StreamOptions       *streamOptions      `json:"stream_options,omitempty"`
+	Temperature         *float64            `json:"temperature,omitempty"`
+	TopP                *float64            `json:"top_p,omitempty"`
+	MaxTokens           int                 `json:"max_tokens,omitempty"`
+	MaxCompletionTokens int                 `json:"max_completion_tokens,omitempty"`
+	Seed                *int                `json:"seed,omitempty"`
+	Stop                stopSequences       `json:"stop,omitempty"`
+	ReasoningEffort     string              `json:"reasoning_effort,omitempty"`
+}
+
+type streamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+// incomingMessage is a chat message whose content may be a string or an
+// array of text and image parts
+type incomingMessage struct {
+	Role       string          `json:"role"`
+	Content    json.RawMessage `json:"content,omitempty"`
+	ToolCalls  []api.ToolCall  `json:"tool_calls,omitempty"`
+	ToolCallID string          `json:"tool_call_id,omitempty"`
+}
+
+// stopSequences accepts "stop" as a single string or an array
+type stopSequences []string
+
+// UnmarshalJSON decodes a string or an array of strings
+func (s *stopSequences) UnmarshalJSON(data []byte) error {
+	var one string
+	if err := json.Unmarshal(data, &one); err == nil {
+		*s = stopSequences{one}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return fmt.Errorf("stop must be a string or an array of strings")
+	}
+	*s = many
+	return nil
+}
+
+// toRequest converts the request body into an api.Request
+func (r *completionRequest) toRequest() (api.Request, error) {
+	if len(r.Messages) == 0 {
+		return api.Request{}, fmt.Errorf("messages must not be empty")
+	}
+
+	messages := make([]api.Message, len(r.Messages))
+	for i, m := range r.Messages {
+		content, parts, err := messageContent(m.Content)
+		if err != nil {
+			return api.Request{}, fmt.Errorf("messages[%d]: %w", i, err)
+		}
+		messages[i] = api.Message{
+			Role:       m.Role,
+			Content:    content,
+			Parts:      parts,
+			ToolCalls:  m.ToolCalls,
+			ToolCallID: m.ToolCallID,
+		}
+	}
+
+	maxTokens := r.MaxTokens
+	if r.MaxCompletionTokens > 0 {
+		maxTokens = r.MaxCompletionTokens
+	}
+
+	return api.Request{
+		Messages:        messages,
+		Tools:           r.Tools,
+		Model:           r.Model,
+		Temperature:     r.Temperature,
+		TopP:            r.TopP,
+		MaxTokens:       maxTokens,
+		Seed:            r.Seed,
+		Stop:            r.Stop,
+		ReasoningEffort: r.ReasoningEffort,
+		ToolChoice:      r.ToolChoice,
+		ResponseFormat:  r.ResponseFormat,
+	}, nil
+}
+
+// messageContent decodes message content, which may be null, a string, or
+// an array of text and image_url parts. It returns the text and, when the
+// content has images, all parts.
+func messageContent(raw json.RawMessage) (string, []api.ContentPart, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return "", nil, nil
+	}
+
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return text, nil, nil
+	}
+
+	var parts []api.ContentPart
+	if err := json.Unmarshal(raw, &parts); err != nil {
+		return "", nil, fmt.Errorf("content must be a string or an array of content parts")
+	}
+	texts := make([]string, 0, len(parts))
+	hasImages := false
+	for _, p := range parts {
+		switch p.Type {
+		case api.PartText:
+			texts = append(texts, p.Text)
+		case api.PartImage:
+			if p.ImageURL == nil || p.ImageURL.URL == "" {
+				return "", nil, fmt.Errorf("image_url part requires a url")
+			}
+			hasImages = true
+		default:
+			return "", nil, fmt.Errorf("unsupported content part type %q", p.Type)
+		}
+	}
+	if !hasImages {
+		parts = nil
+	}
+	return strings.Join(texts, "\n"), parts, nil
+}
+
+// completionResponse is a chat.completion or chat.completion.chunk object
+type completionResponse struct {
+	ID      string             `json:"id"`
+	Object  string             `json:"object"`
+	Created int64              `json:"created"`
+	Model   string             `json:"model"`
+	Choices []completionChoice `json:"choices"`
+	Usage   *api.Usage         `json:"usage,omitempty"`
+}
+
+// completionChoice carries a full message or, when streaming, a delta.
+// FinishReason is a pointer so chunks can send an explicit null.
+type completionChoice struct {
+	Index        int              `json:"index"`
+	Message      *api.Message     `json:"message,omitempty"`
+	Delta        *completionDelta `json:"delta,omitempty"`
+	FinishReason *string          `json:"finish_reason"`
+}
+
+// completionDelta is the incremental message of a stream chunk
+type completionDelta struct {
+	Role             string           `json:"role,omitempty"`
+	Content          string           `json:"content,omitempty"`
+	ReasoningContent string           `json:"reasoning_content,omitempty"`
+	ToolCalls        []streamToolCall `json:"tool_calls,omitempty"`
+}
+
+// streamToolCall is a tool call in a stream chunk, where the index is required
+type streamToolCall struct {
+	Index    int    `json:"index"`
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
@@ -0,0 +1,137 @@
+package tokens
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+
+	"github.com/dlclark/regexp2"
+)
+
+// Pre-tokenization patterns split text into pieces that are encoded
+// separately. They need lookahead, which the standard regexp package lacks.
+const (
+	cl100kPattern = `(?i:'s|'t|'re|'ve|'m|'ll|'d)|[^\r\n\p{L}\p{N}]?\p{L}+|\p{N}{1,3}| ?[^\s\p{L}\p{N}]+[\r\n]*|\s*[\r\n]+|\s+(?!\S)|\s+`
+	o200kPattern  = `[^\r\n\p{L}\p{N}]?[\p{Lu}\p{Lt}\p{Lm}\p{Lo}\p{M}]*[\p{Ll}\p{Lm}\p{Lo}\p{M}]+(?i:'s|'t|'re|'ve|'m|'ll|'d)?|[^\r\n\p{L}\p{N}]?[\p{Lu}\p{Lt}\p{Lm}\p{Lo}\p{M}]+[\p{Ll}\p{Lm}\p{Lo}\p{M}]*(?i:'s|'t|'re|'ve|'m|'ll|'d)?|\p{N}{1,3}| ?[^\s\p{L}\p{N}]+[\r\n/]*|\s*[\r\n]+|\s+(?!\S)|\s+`
+)
+
+// Encoding is a byte-level BPE tokenizer loaded from a tiktoken rank table
+type Encoding struct {
+	Name    string
+	ranks   map[string]int
+	pattern *regexp2.Regexp
+}
+
+// ParseEncoding reads a tiktoken rank table: one "<base64 token> <rank>"
+// pair per line
+func ParseEncoding(name string, r io.Reader) (*Encoding, error) {
+	var pattern string
+	switch name {
+	case Cl100kBase:
+		pattern = cl100kPattern
+	case O200kBase:
+		pattern = o200kPattern
+	default:
+		return nil, fmt.Errorf("unknown encoding %q", name)
+	}
+
+	ranks := make(map[string]int)
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		fields := bytes.Fields(scanner.Bytes())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%s line %d: expected token and rank", name, line)
+		}
+		token, err := base64.StdEncoding.DecodeString(string(fields[0]))
+		if err != nil {
+			return nil, fmt.Errorf("%s line %d: %w", name, line, err)
+		}
+		rank, err := strconv.Atoi(string(fields[1]))
+		if err != nil {
+			return nil, fmt.Errorf("%s line %d: %w", name, line, err)
+		}
+		ranks[string(token)] = rank
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(ranks) == 0 {
+		return nil, fmt.Errorf("%s: empty table", name)
+	}
+
+	return &Encoding{
+		Name:    name,
+		ranks:   ranks,
+		pattern: regexp2.MustCompile(pattern, regexp2.None),
+	}, nil
+}
+
+// Count returns the number of tokens in text
+func (e *Encoding) Count(text string) int {
+	return len(e.Encode(text))
+}
+
+// Encode returns the token ranks of text. Special tokens are not recognized.
+func (e *Encoding) Encode(text string) []int {
+	var out []int
+	m, _ := e.pattern.FindStringMatch(text)
+	for m != nil {
+		out = append(out, e.encodePiece([]byte(m.String()))...)
+		m, _ = e.pattern.FindNextMatch(m)
+	}
+	return out
+}
+
+// encodePiece applies the BPE merges to one pre-tokenized piece: the
+// adjacent pair with the lowest rank is merged until no pair is in the table
+func (e *Encoding) encodePiece(piece []byte) []int {
+	if rank, ok := e.ranks[string(piece)]; ok {
+		return []int{rank}
+	}
+
+	// bounds[i] is the start of part i; the last entry is len(piece)
+	bounds := make([]int, len(piece)+1)
+	for i := range bounds {
+		bounds[i] = i
+	}
+	pairRank := func(i int) int {
+		if i+2 >= len(bounds) {
+			return math.MaxInt
+		}
+		if rank, ok := e.ranks[string(piece[bounds[i]:bounds[i+2]])]; ok {
+			return rank
+		}
+		return math.MaxInt
+	}
+
+	for len(bounds) > 2 {
+		best, bestRank := -1, math.MaxInt
+		for i := 0; i+2 < len(bounds); i++ {
+			if rank := pairRank(i); rank < bestRank {
+				best, bestRank = i, rank
+			}
+		}
+		if best < 0 {
+			break
+		}
+		bounds = append(bounds[:best+1], bounds[best+2:]...)
+	}
+
+	out := make([]int, 0, len(bounds)-1)
+	for i := 0; i+1 < len(bounds); i++ {
+		rank, ok := e.ranks[string(piece[bounds[i]:bounds[i+1]])]
+		if !ok {
+			// Tables cover every single byte; count an unknown one anyway
+			rank = -1
+		}
+		out = append(out, rank)
+	}
+	return out
+}
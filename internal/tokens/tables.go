@@ -0,0 +1,42 @@
+package tokens
+
+import (
+	"compress/gzip"
+	"embed"
+	"fmt"
+)
+
+// tables holds the tiktoken rank tables OpenAI publishes for cl100k_base
+// and o200k_base, gzipped (about 2.4 MB together)
+//
+//go:embed tables/*.tiktoken.gz
+var tables embed.FS
+
+// Encodings lists the encodings with an embedded table
+var Encodings = []string{Cl100kBase, O200kBase}
+
+// tableHashes are the SHA-256 sums of the uncompressed tables, the same ones
+// tiktoken checks the published files against
+var tableHashes = map[string]string{
+	Cl100kBase: "223921b76ee99bde995b7ff738513eef100fb51d18c93597a113bcffe865b2a7",
+	O200kBase:  "446a9538cb6c348e3516120d7c08b09f57c36495e2acfffe59a5bf8b0cfb1a2d",
+}
+
+// LoadEncoding decompresses and parses the named encoding's embedded table
+func LoadEncoding(name string) (*Encoding, error) {
+	if _, ok := tableHashes[name]; !ok {
+		return nil, fmt.Errorf("unknown encoding %q", name)
+	}
+	f, err := tables.Open("tables/" + name + ".tiktoken.gz")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	defer func() { _ = zr.Close() }()
+	return ParseEncoding(name, zr)
+}
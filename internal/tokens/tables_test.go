@@ -0,0 +1,62 @@
+package tokens
+
+import (
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestEmbeddedTables(t *testing.T) {
+	for _, name := range Encodings {
+		f, err := tables.Open("tables/" + name + ".tiktoken.gz")
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		h := sha256.New()
+		if _, err := io.Copy(h, zr); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		_ = f.Close()
+		if got := hex.EncodeToString(h.Sum(nil)); got != tableHashes[name] {
+			t.Errorf("%s: embedded table sha256 = %s, want %s", name, got, tableHashes[name])
+		}
+	}
+
+	if _, err := LoadEncoding("p50k_base"); err == nil {
+		t.Error("expected error for unknown encoding")
+	}
+}
+
+func TestForModel_Exact(t *testing.T) {
+	tests := []struct {
+		model string
+		text  string
+		want  []int
+	}{
+		{"gpt-4", "hello world", []int{15339, 1917}},
+		{"gpt-4o", "hello world", []int{24912, 2375}},
+	}
+	for _, tt := range tests {
+		c := ForModel(tt.model)
+		if IsEstimate(c) {
+			t.Fatalf("ForModel(%q) is an estimate, want the embedded table", tt.model)
+		}
+		enc, err := LoadEncoding(EncodingForModel(tt.model))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := enc.Encode(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Encode(%q) = %v, want %v", enc.Name, tt.text, got, tt.want)
+		}
+		if got := c.Count(tt.text); got != len(tt.want) {
+			t.Errorf("ForModel(%q).Count(%q) = %d, want %d", tt.model, tt.text, got, len(tt.want))
+		}
+	}
+}
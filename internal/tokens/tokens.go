@@ -0,0 +1,135 @@
+// Package tokens counts the tokens of chat model input with the tiktoken
+// BPE tables (cl100k_base, o200k_base), which are embedded in the binary.
+// A character-based estimate stands in if a table fails to load.
+package tokens
+
+import (
+	"log"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Encoding names
+const (
+	Cl100kBase = "cl100k_base"
+	O200kBase  = "o200k_base"
+)
+
+// Counter counts the tokens in a piece of text
+type Counter interface {
+	Count(text string) int
+}
+
+// o200kPrefixes are the model families tokenized with o200k_base; other
+// models (including non-OpenAI ones, for which this is an estimate) use
+// cl100k_base
+var o200kPrefixes = []string{"gpt-4o", "gpt-4.1", "gpt-5", "o1", "o3", "o4"}
+
+// EncodingForModel returns the name of the encoding used by model
+func EncodingForModel(model string) string {
+	for _, prefix := range o200kPrefixes {
+		if strings.HasPrefix(model, prefix) {
+			return O200kBase
+		}
+	}
+	return Cl100kBase
+}
+
+var (
+	countersMu sync.Mutex
+	counters   = make(map[string]Counter)
+)
+
+// ForModel returns the counter for model. Tables are loaded once, on first
+// use; if one fails to load, the heuristic estimate is used instead. BPE
+// counts are cached by text, since the same history is counted before every
+// request.
+func ForModel(model string) Counter {
+	name := EncodingForModel(model)
+
+	countersMu.Lock()
+	defer countersMu.Unlock()
+	if c, ok := counters[name]; ok {
+		return c
+	}
+
+	var c Counter = Heuristic{}
+	if enc, err := LoadEncoding(name); err == nil {
+		c = newCachedCounter(enc, countCacheBytes)
+	} else {
+		log.Printf("Token table %s unavailable, estimating token counts: %v", name, err)
+	}
+	counters[name] = c
+	return c
+}
+
+// IsEstimate reports whether c estimates counts rather than tokenizing
+func IsEstimate(c Counter) bool {
+	_, ok := c.(Heuristic)
+	return ok
+}
+
+// countCacheBytes bounds the text a cached counter remembers counts for
+const countCacheBytes = 16 << 20
+
+// cachedCounter remembers the counts of the texts it has seen. When the
+// texts it holds exceed maxBytes it starts over, which drops the counts of
+// messages that have since been trimmed from the conversation.
+type cachedCounter struct {
+	counter  Counter
+	maxBytes int
+
+	mu     sync.Mutex
+	counts map[string]int
+	size   int
+}
+
+// newCachedCounter wraps counter with a cache of up to maxBytes of text
+func newCachedCounter(counter Counter, maxBytes int) *cachedCounter {
+	return &cachedCounter{counter: counter, maxBytes: maxBytes, counts: make(map[string]int)}
+}
+
+// Count returns the cached count of text, counting it on first sight
+func (c *cachedCounter) Count(text string) int {
+	c.mu.Lock()
+	n, ok := c.counts[text]
+	c.mu.Unlock()
+	if ok {
+		return n
+	}
+
+	n = c.counter.Count(text)
+	if len(text) > c.maxBytes {
+		return n
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.size+len(text) > c.maxBytes {
+		c.counts = make(map[string]int)
+		c.size = 0
+	}
+	if _, ok := c.counts[text]; !ok {
+		c.counts[text] = n
+		c.size += len(text)
+	}
+	return n
+}
+
+// Heuristic estimates tokens without a BPE table: about four bytes of ASCII
+// text per token, and one token per non-ASCII character (CJK text and
+// symbols rarely merge)
+type Heuristic struct{}
+
+// Count estimates the tokens in text
+func (Heuristic) Count(text string) int {
+	ascii, other := 0, 0
+	for _, r := range text {
+		if r < utf8.RuneSelf {
+			ascii++
+		} else {
+			other++
+		}
+	}
+	return (ascii+3)/4 + other
+}
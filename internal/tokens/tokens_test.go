@@ -0,0 +1,140 @@
+package tokens
+
+import (
+	"encoding/base64"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testTable builds a tiktoken table with every single byte plus merges
+func testTable(merges ...string) string {
+	var b strings.Builder
+	rank := 0
+	for i := 0; i < 256; i++ {
+		fmt.Fprintf(&b, "%s %d\n", base64.StdEncoding.EncodeToString([]byte{byte(i)}), rank)
+		rank++
+	}
+	for _, m := range merges {
+		fmt.Fprintf(&b, "%s %d\n", base64.StdEncoding.EncodeToString([]byte(m)), rank)
+		rank++
+	}
+	return b.String()
+}
+
+func TestEncoding_Encode(t *testing.T) {
+	enc, err := ParseEncoding(Cl100kBase, strings.NewReader(testTable("ab", "cd", "abcd", " w", " wo")))
+	if err != nil {
+		t.Fatalf("ParseEncoding() error = %v", err)
+	}
+
+	tests := []struct {
+		text string
+		want []int
+	}{
+		{"abcd", []int{258}},
+		{"abcde", []int{258, 'e'}},
+		{"ab wo", []int{256, 260}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		if got := enc.Encode(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Encode(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+	if got := enc.Count("hello, there"); got != 12 {
+		t.Errorf("Count() with byte-only merges = %d, want 12", got)
+	}
+}
+
+func TestParseEncoding_Errors(t *testing.T) {
+	if _, err := ParseEncoding("p50k_base", strings.NewReader(testTable())); err == nil {
+		t.Error("expected error for unknown encoding")
+	}
+	if _, err := ParseEncoding(O200kBase, strings.NewReader("not-base64! 1\n")); err == nil {
+		t.Error("expected error for malformed line")
+	}
+	if _, err := ParseEncoding(O200kBase, strings.NewReader("")); err == nil {
+		t.Error("expected error for empty table")
+	}
+}
+
+func TestEncodingForModel(t *testing.T) {
+	tests := map[string]string{
+		"gpt-4o-mini":       O200kBase,
+		"gpt-4.1":           O200kBase,
+		"o3-mini":           O200kBase,
+		"gpt-4":             Cl100kBase,
+		"claude-sonnet-4.5": Cl100kBase,
+	}
+	for model, want := range tests {
+		if got := EncodingForModel(model); got != want {
+			t.Errorf("EncodingForModel(%q) = %s, want %s", model, got, want)
+		}
+	}
+}
+
+func TestHeuristic_Count(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"", 0},
+		{"abcd", 1},
+		{"hello world", 3},
+		{"日本語", 3},
+	}
+	for _, tt := range tests {
+		if got := (Heuristic{}).Count(tt.text); got != tt.want {
+			t.Errorf("Count(%q) = %d, want %d", tt.text, got, tt.want)
+		}
+	}
+}
+
+// countingCounter counts how often each text is tokenized
+type countingCounter struct {
+	calls map[string]int
+}
+
+func (c *countingCounter) Count(text string) int {
+	c.calls[text]++
+	return len(text)
+}
+
+func TestCachedCounter(t *testing.T) {
+	inner := &countingCounter{calls: make(map[string]int)}
+	c := newCachedCounter(inner, 10)
+
+	for i := 0; i < 3; i++ {
+		if got := c.Count("hello"); got != 5 {
+			t.Errorf("Count() = %d, want 5", got)
+		}
+	}
+	if inner.calls["hello"] != 1 {
+		t.Errorf("text counted %d times, want once", inner.calls["hello"])
+	}
+
+	// Over the limit the cache starts over
+	c.Count("world!")
+	c.Count("hello")
+	if inner.calls["hello"] != 2 {
+		t.Errorf("text counted %d times after the cache filled, want twice", inner.calls["hello"])
+	}
+
+	// Texts larger than the whole cache aren't kept
+	c.Count("a very long text")
+	c.Count("a very long text")
+	if inner.calls["a very long text"] != 2 || c.size > 10 {
+		t.Errorf("oversized text counted %d times, cache size %d", inner.calls["a very long text"], c.size)
+	}
+}
+
+func TestIsEstimate(t *testing.T) {
+	if !IsEstimate(Heuristic{}) {
+		t.Error("IsEstimate(Heuristic{}) = false")
+	}
+	if IsEstimate(newCachedCounter(Heuristic{}, 10)) {
+		t.Error("IsEstimate() of a cached counter = true")
+	}
+}
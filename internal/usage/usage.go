@@ -0,0 +1,253 @@
+// Package usage records the token usage of every response in a local ledger
+// and summarizes it.
+package usage
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/quocvuong92/ai-cli/internal/config"
+)
+
+// LedgerFileName is the name of the usage ledger file
+const LedgerFileName = "usage.jsonl"
+
+// Report groupings
+const (
+	ByModel        = "model"
+	ByProvider     = "provider"
+	ByDay          = "day"
+	ByCommand      = "command"
+	ByConversation = "conversation"
+)
+
+// Groupings lists the values accepted by Summarize
+var Groupings = []string{ByModel, ByProvider, ByDay, ByCommand, ByConversation}
+
+// Errors returned when parsing report options
+var (
+	ErrInvalidSince    = errors.New("invalid --since. Use a duration such as '7d', '12h' or '2w', or a date such as '2026-01-31'")
+	ErrInvalidGrouping = errors.New("invalid --by. Use: " + strings.Join(Groupings, ", "))
+)
+
+// Entry is the usage of one completed response
+type Entry struct {
+	Time             time.Time `json:"time"`
+	Provider         string    `json:"provider"`
+	Model            string    `json:"model"`
+	ConversationID   string    `json:"conversation_id,omitempty"`
+	Command          string    `json:"command"`
+	PromptTokens     int       `json:"prompt_tokens"`
+	CompletionTokens int       `json:"completion_tokens"`
+	ReasoningTokens  int       `json:"reasoning_tokens,omitempty"`
+	TotalTokens      int       `json:"total_tokens"`
+}
+
+// Ledger is an append-only file of usage entries, one JSON object per line
+type Ledger struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewLedger creates a ledger in the user data directory
+// (~/.local/share/ai-cli/usage.jsonl)
+func NewLedger() *Ledger {
+	path := ""
+	if homeDir, err := os.UserHomeDir(); err == nil {
+		path = filepath.Join(homeDir, ".local", "share", "ai-cli", LedgerFileName)
+	}
+	return NewLedgerAt(path)
+}
+
+// NewLedgerAt creates a ledger stored at path
+func NewLedgerAt(path string) *Ledger {
+	return &Ledger{path: path}
+}
+
+// Path returns the ledger file path
+func (l *Ledger) Path() string {
+	return l.path
+}
+
+// Append adds e to the ledger. Each entry is written with a single append,
+// so concurrent processes don't interleave lines.
+func (l *Ledger) Append(e Entry) error {
+	if l.path == "" {
+		return fmt.Errorf("usage ledger path not available")
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0700); err != nil {
+		return fmt.Errorf("failed to create usage ledger directory: %w", err)
+	}
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open usage ledger: %w", err)
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to write usage ledger: %w", err)
+	}
+	return f.Close()
+}
+
+// Load returns the entries recorded at or after since. Malformed lines are
+// skipped; a missing ledger has no entries.
+func (l *Ledger) Load(since time.Time) ([]Entry, error) {
+	if l.path == "" {
+		return nil, fmt.Errorf("usage ledger path not available")
+	}
+	f, err := os.Open(l.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read usage ledger: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			log.Printf("Skipping malformed usage ledger line %d: %v", line, err)
+			continue
+		}
+		if e.Time.Before(since) {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read usage ledger: %w", err)
+	}
+	return entries, nil
+}
+
+// ParseSince resolves a --since value relative to now: a duration with an
+// optional d (days) or w (weeks) unit, or a YYYY-MM-DD date in local time.
+// An empty value means the beginning of the ledger.
+func ParseSince(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.ParseInLocation(time.DateOnly, value, now.Location()); err == nil {
+		return t, nil
+	}
+
+	d, err := config.ParseAge(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %q", ErrInvalidSince, value)
+	}
+	return now.Add(-d), nil
+}
+
+// Row is the usage of one group in a report
+type Row struct {
+	Key              string
+	Requests         int
+	PromptTokens     int
+	CompletionTokens int
+	ReasoningTokens  int
+	TotalTokens      int
+	// Cost is the estimated spend of the requests with a known price;
+	// Unpriced counts the others
+	Cost     float64
+	Unpriced int
+}
+
+// add accumulates e into r
+func (r *Row) add(e Entry, prices map[string]config.ModelPrice) {
+	r.Requests++
+	r.PromptTokens += e.PromptTokens
+	r.CompletionTokens += e.CompletionTokens
+	r.ReasoningTokens += e.ReasoningTokens
+	r.TotalTokens += e.TotalTokens
+	if price, ok := LookupPrice(prices, e.Model); ok {
+		r.Cost += (float64(e.PromptTokens)*price.Input + float64(e.CompletionTokens)*price.Output) / 1e6
+	} else {
+		r.Unpriced++
+	}
+}
+
+// Summarize groups entries by model, provider, day, command or conversation.
+// Days are listed in order; other groups by total tokens, largest first.
+// The second result is the total over all entries.
+func Summarize(entries []Entry, by string, prices map[string]config.ModelPrice) ([]Row, Row, error) {
+	key, ok := groupKeys[by]
+	if !ok {
+		return nil, Row{}, fmt.Errorf("%w: %q", ErrInvalidGrouping, by)
+	}
+
+	groups := make(map[string]*Row)
+	total := Row{Key: "Total"}
+	for _, e := range entries {
+		k := key(e)
+		if k == "" {
+			k = "(none)"
+		}
+		row, ok := groups[k]
+		if !ok {
+			row = &Row{Key: k}
+			groups[k] = row
+		}
+		row.add(e, prices)
+		total.add(e, prices)
+	}
+
+	rows := make([]Row, 0, len(groups))
+	for _, row := range groups {
+		rows = append(rows, *row)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if by == ByDay || rows[i].TotalTokens == rows[j].TotalTokens {
+			return rows[i].Key < rows[j].Key
+		}
+		return rows[i].TotalTokens > rows[j].TotalTokens
+	})
+	return rows, total, nil
+}
+
+// groupKeys extracts the report key of an entry for each grouping
+var groupKeys = map[string]func(Entry) string{
+	ByModel:        func(e Entry) string { return e.Model },
+	ByProvider:     func(e Entry) string { return e.Provider },
+	ByDay:          func(e Entry) string { return e.Time.Local().Format(time.DateOnly) },
+	ByCommand:      func(e Entry) string { return e.Command },
+	ByConversation: func(e Entry) string { return e.ConversationID },
+}
+
+// LookupPrice returns the price of model: an exact key, else the longest
+// matching "prefix*" key
+func LookupPrice(prices map[string]config.ModelPrice, model string) (config.ModelPrice, bool) {
+	if p, ok := prices[model]; ok {
+		return p, true
+	}
+	best := -1
+	var found config.ModelPrice
+	for key, p := range prices {
+		prefix, ok := strings.CutSuffix(key, "*")
+		if ok && strings.HasPrefix(model, prefix) && len(prefix) > best {
+			best, found = len(prefix), p
+		}
+	}
+	return found, best >= 0
+}
@@ -0,0 +1,133 @@
+package usage
+
+import (
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/quocvuong92/ai-cli/internal/config"
+)
+
+func TestLedger(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", LedgerFileName)
+	ledger := NewLedgerAt(path)
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	if entries, err := ledger.Load(time.Time{}); err != nil || len(entries) != 0 {
+		t.Errorf("Load() on a missing ledger = %+v, %v", entries, err)
+	}
+
+	for i, model := range []string{"gpt-4.1", "gpt-4o", "gpt-4.1"} {
+		err := ledger.Append(Entry{Time: start.Add(time.Duration(i) * 24 * time.Hour), Provider: "copilot", Model: model, Command: "query", TotalTokens: 10})
+		if err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.WriteString("not json\n")
+	_ = f.Close()
+
+	entries, err := ledger.Load(time.Time{})
+	if err != nil || len(entries) != 3 {
+		t.Fatalf("Load() = %d entries, %v; want 3 (malformed line skipped)", len(entries), err)
+	}
+	entries, _ = ledger.Load(start.Add(24 * time.Hour))
+	if len(entries) != 2 || entries[0].Model != "gpt-4o" {
+		t.Errorf("Load(since) = %+v, want the last two entries", entries)
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{"", time.Time{}, false},
+		{"7d", now.Add(-7 * 24 * time.Hour), false},
+		{"2w", now.Add(-14 * 24 * time.Hour), false},
+		{"12h", now.Add(-12 * time.Hour), false},
+		{"2026-03-01", time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), false},
+		{"0d", time.Time{}, true},
+		{"xd", time.Time{}, true},
+		{"-1h", time.Time{}, true},
+		{"last week", time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseSince(tt.value, now)
+		if tt.wantErr {
+			if !errors.Is(err, ErrInvalidSince) {
+				t.Errorf("ParseSince(%q) error = %v, want ErrInvalidSince", tt.value, err)
+			}
+			continue
+		}
+		if err != nil || !got.Equal(tt.want) {
+			t.Errorf("ParseSince(%q) = %v, %v; want %v", tt.value, got, err, tt.want)
+		}
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	entries := []Entry{
+		{Provider: "azure", Model: "gpt-4o", Command: "query", PromptTokens: 1000000, CompletionTokens: 100000, TotalTokens: 1100000},
+		{Provider: "azure", Model: "gpt-4o", Command: "agent", PromptTokens: 1000000, CompletionTokens: 100000, TotalTokens: 1100000},
+		{Provider: "copilot", Model: "o3-mini", Command: "query", PromptTokens: 500, CompletionTokens: 500, ReasoningTokens: 300, TotalTokens: 1000},
+		{Provider: "copilot", Model: "local", Command: "query", TotalTokens: 50},
+	}
+	prices := map[string]config.ModelPrice{
+		"gpt-4o": {Input: 2.5, Output: 10},
+		"o3*":    {Input: 1000, Output: 1000},
+	}
+
+	rows, total, err := Summarize(entries, ByModel, prices)
+	if err != nil {
+		t.Fatalf("Summarize() error = %v", err)
+	}
+	if len(rows) != 3 || rows[0].Key != "gpt-4o" || rows[0].Requests != 2 || rows[2].Key != "local" {
+		t.Fatalf("Summarize() rows = %+v", rows)
+	}
+	if math.Abs(rows[0].Cost-7) > 1e-9 || rows[0].Unpriced != 0 {
+		t.Errorf("gpt-4o cost = %v (%d unpriced), want $7", rows[0].Cost, rows[0].Unpriced)
+	}
+	if math.Abs(rows[1].Cost-1) > 1e-9 || rows[1].ReasoningTokens != 300 {
+		t.Errorf("o3-mini row = %+v, want $1 via the o3* price", rows[1])
+	}
+	if total.Requests != 4 || total.TotalTokens != 2201050 || total.Unpriced != 1 {
+		t.Errorf("total = %+v", total)
+	}
+
+	rows, _, _ = Summarize(entries, ByCommand, nil)
+	if len(rows) != 2 || rows[0].Key != "query" || rows[0].Requests != 3 || rows[0].Unpriced != 3 {
+		t.Errorf("Summarize(by command) = %+v", rows)
+	}
+
+	if _, _, err := Summarize(entries, "color", nil); !errors.Is(err, ErrInvalidGrouping) {
+		t.Errorf("Summarize(by color) error = %v, want ErrInvalidGrouping", err)
+	}
+}
+
+func TestSummarize_ByDay(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 12, 0, 0, 0, time.Local) }
+	entries := []Entry{
+		{Time: day(3), TotalTokens: 1},
+		{Time: day(1), TotalTokens: 100},
+		{Time: day(2), TotalTokens: 10},
+		{Time: day(1), TotalTokens: 100},
+	}
+
+	rows, _, err := Summarize(entries, ByDay, nil)
+	if err != nil {
+		t.Fatalf("Summarize() error = %v", err)
+	}
+	if len(rows) != 3 || rows[0].Key != "2026-03-01" || rows[0].TotalTokens != 200 || rows[2].Key != "2026-03-03" {
+		t.Errorf("Summarize(by day) = %+v, want days in order", rows)
+	}
+}